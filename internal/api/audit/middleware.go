@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	auditService "tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+const (
+	DefaultMaxBodyBytes = 1 << 20
+
+	DefaultAnonymousLimit  = 100
+	DefaultAnonymousWindow = time.Minute
+)
+
+type middleware struct {
+	recorder  auditService.Recorder
+	maxBody   int64
+	anonymous *sampler
+}
+
+// Middleware пишет в журнал аудита каждый изменяющий запрос, включая отклоненные
+// до контроллера. Стоит первым: тогда код ответа окончательный, а клиент уже
+// положен в контекст аутентификацией. Отказы анонимным клиентам прореживаются,
+// чтобы перебор ключей не раздувал журнал.
+func Middleware(recorder auditService.Recorder, opts ...Option) gin.HandlerFunc {
+	m := &middleware{
+		recorder:  recorder,
+		maxBody:   DefaultMaxBodyBytes,
+		anonymous: newSampler(DefaultAnonymousLimit, DefaultAnonymousWindow),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m.handle
+}
+
+func (m *middleware) handle(c *gin.Context) {
+	if !mutating(c.Request.Method) {
+		c.Next()
+		return
+	}
+
+	var payload []byte
+	if c.Request.Body != nil {
+		// Тело читается целиком ради дайджеста, поэтому его размер ограничен заранее
+		var err error
+		payload, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, m.maxBody))
+		// Недочитанное тело все равно отдаем дальше: обработчик ответит 400
+		c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge,
+				"Payload too large", fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)))
+		}
+	}
+	ctx, scope := auditService.WithScope(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	status := c.Writer.Status()
+	actor := auditService.Actor(c.Request.Context())
+	if actor == auditService.ActorAnonymous && status >= http.StatusBadRequest && !m.anonymous.allow(time.Now()) {
+		metrics.AuditSuppressed.Inc()
+		return
+	}
+
+	entry, described := scope.Entry()
+	if !described {
+		entry.Action = auditService.ActionRequest
+		entry.Outcome = auditService.OutcomeOK
+		if status >= http.StatusBadRequest {
+			entry.Outcome = http.StatusText(status)
+		}
+	}
+	if entry.WalletID == nil {
+		if walletID, err := uuid.Parse(c.Param("walletId")); err == nil {
+			entry.WalletID = &walletID
+		}
+	}
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	code := int32(status)
+
+	entry.Actor = actor
+	entry.Route = c.Request.Method + " " + route
+	entry.PayloadDigest = auditService.Digest(payload)
+	entry.StatusCode = &code
+	entry.ClientIP = c.ClientIP()
+	// Ошибку записи логирует сам Recorder: ответ уже отправлен
+	_ = m.recorder.Record(c.Request.Context(), entry)
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package audit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tryingMicro/OrderAccepter/internal/api/audit"
+	"tryingMicro/OrderAccepter/internal/auth"
+	auditService "tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type recorderFunc func(ctx context.Context, e auditService.Entry) error
+
+func (f recorderFunc) Record(ctx context.Context, e auditService.Entry) error {
+	return f(ctx, e)
+}
+
+func setupRouter(entries *[]auditService.Entry, opts ...audit.Option) *gin.Engine {
+	r := gin.New()
+	r.Use(audit.Middleware(recorderFunc(func(_ context.Context, e auditService.Entry) error {
+		*entries = append(*entries, e)
+		return nil
+	}), opts...))
+	// Аутентификация кладет клиента в контекст уже после аудита
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{ID: "key-1"}))
+	})
+	r.POST("/wallets/:walletId/deposit", func(c *gin.Context) {
+		scope, _ := auditService.ScopeFrom(c.Request.Context())
+		walletID := uuid.MustParse(c.Param("walletId"))
+		scope.Describe("wallet.operation.deposit", &walletID, nil)
+		c.Status(http.StatusOK)
+	})
+	r.GET("/wallets/:walletId", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func do(r http.Handler, method, target, body string, authorized bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if authorized {
+		req.Header.Set("Authorization", "Bearer token")
+	}
+	req.RemoteAddr = "10.0.0.7:51234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_RecordsMutation(t *testing.T) {
+	var entries []auditService.Entry
+	walletID := uuid.New()
+
+	do(setupRouter(&entries), http.MethodPost, "/wallets/"+walletID.String()+"/deposit", `{"amount":"10"}`, true)
+
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, "key-1", e.Actor)
+	assert.Equal(t, "wallet.operation.deposit", e.Action)
+	assert.Equal(t, "POST /wallets/:walletId/deposit", e.Route)
+	assert.Equal(t, walletID, *e.WalletID)
+	assert.Equal(t, auditService.Digest([]byte(`{"amount":"10"}`)), e.PayloadDigest)
+	assert.Equal(t, auditService.OutcomeOK, e.Outcome)
+	assert.Equal(t, int32(http.StatusOK), *e.StatusCode)
+	assert.Equal(t, "10.0.0.7", e.ClientIP)
+}
+
+func TestMiddleware_RecordsRejectedRequest(t *testing.T) {
+	var entries []auditService.Entry
+	walletID := uuid.New()
+
+	do(setupRouter(&entries), http.MethodPost, "/wallets/"+walletID.String()+"/deposit", `{"amount":"10"}`, false)
+
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, auditService.ActorAnonymous, e.Actor)
+	assert.Equal(t, auditService.ActionRequest, e.Action)
+	assert.Equal(t, "Unauthorized", e.Outcome)
+	assert.Equal(t, walletID, *e.WalletID)
+	assert.Equal(t, int32(http.StatusUnauthorized), *e.StatusCode)
+}
+
+func TestMiddleware_SkipsReads(t *testing.T) {
+	var entries []auditService.Entry
+
+	do(setupRouter(&entries), http.MethodGet, "/wallets/"+uuid.NewString(), "", true)
+
+	assert.Empty(t, entries)
+}
+
+func TestMiddleware_RejectsOversizedBody(t *testing.T) {
+	var entries []auditService.Entry
+	walletID := uuid.New()
+	r := setupRouter(&entries, audit.WithMaxBodyBytes(16))
+
+	w := do(r, http.MethodPost, "/wallets/"+walletID.String()+"/deposit", `{"amount":"10","comment":"too long"}`, true)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Request Entity Too Large", entries[0].Outcome)
+	assert.Equal(t, int32(http.StatusRequestEntityTooLarge), *entries[0].StatusCode)
+}
+
+func TestMiddleware_SamplesAnonymousRejections(t *testing.T) {
+	var entries []auditService.Entry
+	target := "/wallets/" + uuid.NewString() + "/deposit"
+	r := setupRouter(&entries, audit.WithAnonymousLimit(2, time.Hour))
+
+	for range 5 {
+		do(r, http.MethodPost, target, `{"amount":"10"}`, false)
+	}
+	do(r, http.MethodPost, target, `{"amount":"10"}`, true)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, auditService.ActorAnonymous, entries[0].Actor)
+	assert.Equal(t, auditService.ActorAnonymous, entries[1].Actor)
+	assert.Equal(t, "key-1", entries[2].Actor)
+}
@@ -0,0 +1,24 @@
+package audit
+
+import "time"
+
+type Option func(*middleware)
+
+// WithMaxBodyBytes ограничивает тело изменяющего запроса. Больше - 413 без вызова обработчика
+func WithMaxBodyBytes(n int64) Option {
+	return func(m *middleware) {
+		if n > 0 {
+			m.maxBody = n
+		}
+	}
+}
+
+// WithAnonymousLimit задает, сколько отказов анонимным клиентам пишется в журнал
+// за окно window. Остальные только считаются в метрике wallet_audit_suppressed_total.
+func WithAnonymousLimit(limit int, window time.Duration) Option {
+	return func(m *middleware) {
+		if limit > 0 && window > 0 {
+			m.anonymous = newSampler(limit, window)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// sampler пропускает не больше limit событий за окно. Журнал аудита пишется под
+// общей блокировкой, поэтому поток анонимных отказов не должен его забивать.
+type sampler struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	start  time.Time
+	count  int
+}
+
+func newSampler(limit int, window time.Duration) *sampler {
+	return &sampler{limit: limit, window: window}
+}
+
+func (s *sampler) allow(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= s.window {
+		s.start = now
+		s.count = 0
+	}
+	if s.count >= s.limit {
+		return false
+	}
+	s.count++
+	return true
+}
@@ -0,0 +1,249 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/admin"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/apikey"
+	walletSvc "tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// setupRouter кладет в контекст сотрудника staff, как это делает аутентификация сервера
+func setupRouter(svc walletSvc.WalletService, staff string) *gin.Engine {
+	if staff == "" {
+		return setupRouterAs(svc, nil)
+	}
+	return setupRouterAs(svc, &auth.Principal{ID: "key-" + staff, Subject: staff, Scopes: []string{auth.RoleOperator}})
+}
+
+func setupRouterAs(svc walletSvc.WalletService, p *auth.Principal) *gin.Engine {
+	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
+	if p != nil {
+		r.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), *p))
+		})
+	}
+	ctrl := admin.New(svc, zap.NewNop())
+	r.GET("/admin/wallets", ctrl.FindWallets)
+	r.GET("/admin/wallets/:walletId", ctrl.GetWallet)
+	r.POST("/admin/wallets/:walletId/adjustments", ctrl.RequestAdjustment)
+	r.GET("/admin/adjustments", ctrl.ListAdjustments)
+	r.POST("/admin/adjustments/:id/approve", ctrl.ApproveAdjustment)
+	r.POST("/admin/adjustments/:id/reject", ctrl.RejectAdjustment)
+	return r
+}
+
+func do(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body
+}
+
+func TestFindWallets(t *testing.T) {
+	owner := "merchant-1"
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(10), Currency: "USD", OwnerID: &owner}
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().FindWallets(gomock.Any(), walletSvc.WalletLookup{OwnerID: owner, ExternalRef: "order-1"}).
+		Return([]repository.Wallet{w}, nil)
+
+	rec := do(setupRouter(svc, "alice"), http.MethodGet, "/admin/wallets?ownerId=merchant-1&externalRef=order-1", "")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	wallets := decodeBody(t, rec)["wallets"].([]any)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, owner, wallets[0].(map[string]any)["owner_id"])
+}
+
+func TestFindWallets_OwnerRequired(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+
+	rec := do(setupRouter(svc, "alice"), http.MethodGet, "/admin/wallets", "")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRequestAdjustment_Applied(t *testing.T) {
+	walletID := uuid.New()
+	w := repository.Wallet{ID: walletID, Balance: decimal.NewFromInt(150), Currency: "USD"}
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().RequestAdjustment(gomock.Any(), walletID, walletSvc.AdjustmentRequest{
+		Direction: walletSvc.AdjustmentCredit,
+		Amount:    decimal.RequireFromString("50.00"),
+		Reason:    "duplicate fee",
+		Actor:     "alice",
+	}).Return(walletSvc.AdjustmentResult{
+		Adjustment: repository.ManualAdjustment{Status: walletSvc.AdjustmentStatusApplied},
+		Wallet:     &w,
+	}, nil)
+
+	rec := do(setupRouter(svc, "alice"), http.MethodPost, "/admin/wallets/"+walletID.String()+"/adjustments",
+		`{"direction":"CREDIT","amount":"50.00","reason":"duplicate fee"}`)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	body := decodeBody(t, rec)
+	assert.Equal(t, "150", body["wallet"].(map[string]any)["balance"])
+}
+
+func TestRequestAdjustment_Pending(t *testing.T) {
+	walletID := uuid.New()
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().RequestAdjustment(gomock.Any(), walletID, gomock.Any()).Return(walletSvc.AdjustmentResult{
+		Adjustment: repository.ManualAdjustment{Status: walletSvc.AdjustmentStatusPending},
+	}, nil)
+
+	rec := do(setupRouter(svc, "alice"), http.MethodPost, "/admin/wallets/"+walletID.String()+"/adjustments",
+		`{"direction":"DEBIT","amount":"5000","reason":"chargeback"}`)
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.NotContains(t, decodeBody(t, rec), "wallet")
+}
+
+func TestRequestAdjustment_ReasonRequired(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+
+	rec := do(setupRouter(svc, "alice"), http.MethodPost, "/admin/wallets/"+uuid.NewString()+"/adjustments",
+		`{"direction":"CREDIT","amount":"10"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRequestAdjustment_NoPrincipal(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+
+	rec := do(setupRouter(svc, ""), http.MethodPost, "/admin/wallets/"+uuid.NewString()+"/adjustments",
+		`{"direction":"CREDIT","amount":"10","reason":"fix"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestApproveAdjustment_SelfApproval(t *testing.T) {
+	id := uuid.New()
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().ApproveAdjustment(gomock.Any(), id, "alice").Return(walletSvc.AdjustmentResult{}, walletSvc.ErrSelfApproval)
+
+	rec := do(setupRouter(svc, "alice"), http.MethodPost, "/admin/adjustments/"+id.String()+"/approve", "")
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "SELF_APPROVAL", decodeBody(t, rec)["code"])
+}
+
+func TestRejectAdjustment(t *testing.T) {
+	id := uuid.New()
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().RejectAdjustment(gomock.Any(), id, "bob", "no evidence").
+		Return(repository.ManualAdjustment{ID: id, Status: walletSvc.AdjustmentStatusRejected}, nil)
+
+	rec := do(setupRouter(svc, "bob"), http.MethodPost, "/admin/adjustments/"+id.String()+"/reject", `{"reason":"no evidence"}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestListAdjustments_Filter(t *testing.T) {
+	walletID := uuid.New()
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	svc.EXPECT().ListAdjustments(gomock.Any(), walletSvc.AdjustmentFilter{Status: walletSvc.AdjustmentStatusPending, WalletID: &walletID}).
+		Return([]repository.ManualAdjustment{}, nil)
+
+	rec := do(setupRouter(svc, "alice"), http.MethodGet, "/admin/adjustments?status=PENDING&walletId="+walletID.String(), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(setupRouter(svc, "alice"), http.MethodGet, "/admin/adjustments?status=DONE", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// Ротация выдает новый ключ с новым ID, но тот же сотрудник не может им подтвердить свою корректировку
+func TestApproveAdjustment_AfterKeyRotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockRepository(ctrl)
+	keyRepo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error { return fn(keyRepo) }).AnyTimes()
+	stored := map[string]repository.ApiKey{}
+	keyRepo.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+			row := repository.ApiKey{ID: arg.ID, Name: arg.Name, Prefix: arg.Prefix, SecretHash: arg.SecretHash, Scopes: arg.Scopes, RotatedFrom: arg.RotatedFrom, StaffID: arg.StaffID}
+			stored[row.Prefix] = row
+			return row, nil
+		}).Times(2)
+	keyRepo.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, prefix string) (repository.ApiKey, error) { return stored[prefix], nil }).AnyTimes()
+	keyRepo.EXPECT().GetApiKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id uuid.UUID) (repository.ApiKey, error) {
+			for _, row := range stored {
+				if row.ID == id {
+					return row, nil
+				}
+			}
+			return repository.ApiKey{}, pgx.ErrNoRows
+		})
+	keyRepo.EXPECT().RevokeApiKey(gomock.Any(), gomock.Any()).Return(repository.ApiKey{}, nil)
+	keyRepo.EXPECT().TouchApiKey(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	keys := apikey.New(keyRepo, zap.NewNop())
+
+	issued, err := keys.Issue(context.Background(), apikey.Issue{Name: "Alice", Scopes: []string{auth.RoleOperator}, StaffID: "alice"})
+	require.NoError(t, err)
+	rotated, err := keys.Rotate(context.Background(), issued.ID)
+	require.NoError(t, err)
+	before, err := keys.Authenticate(context.Background(), issued.Token)
+	require.NoError(t, err)
+	after, err := keys.Authenticate(context.Background(), rotated.Token)
+	require.NoError(t, err)
+	require.NotEqual(t, before.ID, after.ID)
+
+	walletRepo := mocks.NewMockRepository(ctrl)
+	walletRepo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error { return fn(walletRepo) }).AnyTimes()
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.Zero, Currency: "USD", Status: walletSvc.WalletStatusActive}
+	walletRepo.EXPECT().GetWalletForUpdate(gomock.Any(), w.ID).Return(w, nil)
+	var adjustment repository.ManualAdjustment
+	walletRepo.EXPECT().CreateManualAdjustment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateManualAdjustmentParams) (repository.ManualAdjustment, error) {
+			adjustment = repository.ManualAdjustment{ID: arg.ID, WalletID: arg.WalletID, Direction: arg.Direction, Amount: arg.Amount, Status: arg.Status, RequestedBy: arg.RequestedBy}
+			return adjustment, nil
+		})
+	walletRepo.EXPECT().GetManualAdjustment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, uuid.UUID) (repository.ManualAdjustment, error) { return adjustment, nil })
+	walletRepo.EXPECT().GetManualAdjustmentForUpdate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, uuid.UUID) (repository.ManualAdjustment, error) { return adjustment, nil })
+	svc := walletSvc.New(walletRepo, zap.NewNop())
+
+	rec := do(setupRouterAs(svc, &before), http.MethodPost, "/admin/wallets/"+w.ID.String()+"/adjustments",
+		`{"direction":"CREDIT","amount":"5000","reason":"chargeback"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	rec = do(setupRouterAs(svc, &after), http.MethodPost, "/admin/adjustments/"+adjustment.ID.String()+"/approve", "")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "SELF_APPROVAL", decodeBody(t, rec)["code"])
+}
@@ -0,0 +1,217 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/api/controllers/wallet"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+// AdminController - инструменты поддержки. Роли проверяет роутер, сотрудник,
+// от имени которого выполняется действие, берется из аутентифицированного клиента.
+type AdminController interface {
+	GetWallet(c *gin.Context)
+	FindWallets(c *gin.Context)
+	RequestAdjustment(c *gin.Context)
+	ApproveAdjustment(c *gin.Context)
+	RejectAdjustment(c *gin.Context)
+	ListAdjustments(c *gin.Context)
+}
+
+type adminController struct {
+	service walletService.WalletService
+	log     logger.Logger
+}
+
+func New(service walletService.WalletService, log logger.Logger) AdminController {
+	return &adminController{
+		service: service,
+		log:     log,
+	}
+}
+
+func (ac *adminController) GetWallet(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
+		return
+	}
+
+	w, err := ac.service.GetBalance(c.Request.Context(), walletID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, wallet.Response(w))
+}
+
+type findWalletsQuery struct {
+	OwnerID     string `form:"ownerId"     binding:"required,max=128"`
+	ExternalRef string `form:"externalRef" binding:"omitempty,max=128"`
+}
+
+func (ac *adminController) FindWallets(c *gin.Context) {
+	var q findWalletsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	wallets, err := ac.service.FindWallets(c.Request.Context(), walletService.WalletLookup{
+		OwnerID:     q.OwnerID,
+		ExternalRef: q.ExternalRef,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	items := make([]gin.H, 0, len(wallets))
+	for _, w := range wallets {
+		items = append(items, wallet.Response(w))
+	}
+	c.JSON(http.StatusOK, gin.H{"wallets": items})
+}
+
+type adjustmentRequest struct {
+	Direction string          `json:"direction" binding:"required,oneof=CREDIT DEBIT"`
+	Amount    decimal.Decimal `json:"amount"`
+	Reason    string          `json:"reason"    binding:"required,max=1000"`
+}
+
+// RequestAdjustment отвечает 201, если корректировка проведена, и 202, если она
+// ждет подтверждения вторым сотрудником.
+func (ac *adminController) RequestAdjustment(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
+		return
+	}
+	var req adjustmentRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	if err = money.Validate(req.Amount); err != nil {
+		_ = c.Error(problem.InvalidField("amount", err.Error()))
+		return
+	}
+	actor, ok := actorOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := ac.service.RequestAdjustment(c.Request.Context(), walletID, walletService.AdjustmentRequest{
+		Direction: req.Direction,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Actor:     actor,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Adjustment.Status == walletService.AdjustmentStatusPending {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, adjustmentResponse(result))
+}
+
+func (ac *adminController) ApproveAdjustment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+	actor, ok := actorOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := ac.service.ApproveAdjustment(c.Request.Context(), id, actor)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, adjustmentResponse(result))
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+func (ac *adminController) RejectAdjustment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+	var req rejectRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	actor, ok := actorOf(c)
+	if !ok {
+		return
+	}
+
+	a, err := ac.service.RejectAdjustment(c.Request.Context(), id, actor, req.Reason)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"adjustment": a})
+}
+
+type listAdjustmentsQuery struct {
+	Status   string `form:"status"   binding:"omitempty,oneof=PENDING APPLIED REJECTED"`
+	WalletID string `form:"walletId" binding:"omitempty,uuid"`
+}
+
+func (ac *adminController) ListAdjustments(c *gin.Context) {
+	var q listAdjustmentsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	filter := walletService.AdjustmentFilter{Status: q.Status}
+	if q.WalletID != "" {
+		walletID := uuid.MustParse(q.WalletID)
+		filter.WalletID = &walletID
+	}
+
+	adjustments, err := ac.service.ListAdjustments(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"adjustments": adjustments})
+}
+
+// actorOf возвращает сотрудника, выполняющего действие.
+// Без клиента в контексте маршрут не был защищен аутентификацией - это ошибка конфигурации роутера.
+func actorOf(c *gin.Context) (string, bool) {
+	actor, err := auth.Actor(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return "", false
+	}
+	return actor, true
+}
+
+func adjustmentResponse(r walletService.AdjustmentResult) gin.H {
+	resp := gin.H{"adjustment": r.Adjustment}
+	if r.Wallet != nil {
+		resp["wallet"] = wallet.Response(*r.Wallet)
+	}
+	return resp
+}
@@ -0,0 +1,122 @@
+package apikey_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/apikey"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
+	apiKeySvc "tryingMicro/OrderAccepter/internal/service/apikey"
+)
+
+type MockApiKeyService struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyService) Issue(ctx context.Context, r apiKeySvc.Issue) (apiKeySvc.IssuedKey, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(apiKeySvc.IssuedKey), args.Error(1)
+}
+func (m *MockApiKeyService) List(ctx context.Context) ([]apiKeySvc.Key, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apiKeySvc.Key), args.Error(1)
+}
+func (m *MockApiKeyService) Revoke(ctx context.Context, id uuid.UUID) (apiKeySvc.Key, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(apiKeySvc.Key), args.Error(1)
+}
+func (m *MockApiKeyService) Rotate(ctx context.Context, id uuid.UUID) (apiKeySvc.IssuedKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(apiKeySvc.IssuedKey), args.Error(1)
+}
+func (m *MockApiKeyService) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(auth.Principal), args.Error(1)
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(svc apiKeySvc.ApiKeyService) *gin.Engine {
+	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
+	ctrl := apikey.New(svc, zap.NewNop())
+	r.POST("/admin/api-keys", ctrl.Issue)
+	r.GET("/admin/api-keys", ctrl.List)
+	r.DELETE("/admin/api-keys/:id", ctrl.Revoke)
+	r.POST("/admin/api-keys/:id/rotate", ctrl.Rotate)
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIssue_Success(t *testing.T) {
+	svc := new(MockApiKeyService)
+	issued := apiKeySvc.IssuedKey{
+		Key:   apiKeySvc.Key{ID: uuid.New(), Name: "billing", Prefix: "abc", Scopes: []string{auth.ScopeWalletRead}},
+		Token: "wk_abc_secret",
+	}
+	svc.On("Issue", mock.Anything, apiKeySvc.Issue{Name: "billing", Scopes: []string{auth.ScopeWalletRead}, OwnerID: "merchant-1"}).Return(issued, nil)
+
+	rec := serve(setupRouter(svc), http.MethodPost, "/admin/api-keys", `{"name":"billing","scopes":["wallet:read"],"ownerId":"merchant-1"}`)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "wk_abc_secret", body["key"])
+	assert.Equal(t, "abc", body["prefix"])
+	svc.AssertExpectations(t)
+}
+
+func TestIssue_InvalidScope(t *testing.T) {
+	svc := new(MockApiKeyService)
+	svc.On("Issue", mock.Anything, apiKeySvc.Issue{Name: "billing", Scopes: []string{"root"}}).Return(apiKeySvc.IssuedKey{}, apiKeySvc.ErrInvalidKey)
+
+	rec := serve(setupRouter(svc), http.MethodPost, "/admin/api-keys", `{"name":"billing","scopes":["root"]}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), problem.CodeInvalidApiKey)
+}
+
+func TestRevoke_NotFound(t *testing.T) {
+	svc := new(MockApiKeyService)
+	id := uuid.New()
+	svc.On("Revoke", mock.Anything, id).Return(apiKeySvc.Key{}, apiKeySvc.ErrKeyNotFound)
+
+	rec := serve(setupRouter(svc), http.MethodDelete, "/admin/api-keys/"+id.String(), "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), problem.CodeApiKeyNotFound)
+}
+
+func TestRotate(t *testing.T) {
+	svc := new(MockApiKeyService)
+	id := uuid.New()
+	svc.On("Rotate", mock.Anything, id).Return(apiKeySvc.IssuedKey{Key: apiKeySvc.Key{ID: uuid.New(), RotatedFrom: &id}, Token: "wk_new_secret"}, nil)
+
+	rec := serve(setupRouter(svc), http.MethodPost, "/admin/api-keys/"+id.String()+"/rotate", "")
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "wk_new_secret")
+
+	rec = serve(setupRouter(svc), http.MethodPost, "/admin/api-keys/nope/rotate", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,100 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	apiKeyService "tryingMicro/OrderAccepter/internal/service/apikey"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+type ApiKeyController interface {
+	Issue(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+	Rotate(c *gin.Context)
+}
+
+type apiKeyController struct {
+	service apiKeyService.ApiKeyService
+	log     logger.Logger
+}
+
+func New(service apiKeyService.ApiKeyService, log logger.Logger) ApiKeyController {
+	return &apiKeyController{
+		service: service,
+		log:     log,
+	}
+}
+
+type issueRequest struct {
+	Name   string   `json:"name"   binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// OwnerID - владелец кошельков, к которым получит доступ ключ
+	OwnerID string `json:"ownerId"`
+	// StaffID - сотрудник, которому выдан ключ поддержки или admin
+	StaffID string `json:"staffId"`
+}
+
+// Issue выпускает ключ. Секрет есть только в этом ответе.
+func (ac *apiKeyController) Issue(c *gin.Context) {
+	var req issueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	key, err := ac.service.Issue(c.Request.Context(), apiKeyService.Issue{
+		Name:    req.Name,
+		Scopes:  req.Scopes,
+		OwnerID: req.OwnerID,
+		StaffID: req.StaffID,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func (ac *apiKeyController) List(c *gin.Context) {
+	keys, err := ac.service.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func (ac *apiKeyController) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+
+	key, err := ac.service.Revoke(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+// Rotate выпускает замену ключа и сразу отзывает старый
+func (ac *apiKeyController) Rotate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+
+	key, err := ac.service.Rotate(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
@@ -1,17 +1,38 @@
 package controllers
 
 import (
+	"tryingMicro/OrderAccepter/internal/api/controllers/admin"
+	"tryingMicro/OrderAccepter/internal/api/controllers/apikey"
+	"tryingMicro/OrderAccepter/internal/api/controllers/fx"
+	"tryingMicro/OrderAccepter/internal/api/controllers/health"
+	"tryingMicro/OrderAccepter/internal/api/controllers/stream"
 	"tryingMicro/OrderAccepter/internal/api/controllers/wallet"
+	"tryingMicro/OrderAccepter/internal/api/controllers/webhook"
+	"tryingMicro/OrderAccepter/internal/api/controllers/ws"
 	"tryingMicro/OrderAccepter/internal/service"
 	"tryingMicro/OrderAccepter/package/logger"
 )
 
 type Controllers struct {
-	Wallet wallet.WalletController
+	Wallet  wallet.WalletController
+	Fx      fx.FxController
+	Webhook webhook.WebhookController
+	Stream  stream.StreamController
+	WS      ws.WSController
+	ApiKey  apikey.ApiKeyController
+	Admin   admin.AdminController
+	Health  health.HealthController
 }
 
 func NewControllers(service *service.Services, log logger.Logger) *Controllers {
 	return &Controllers{
-		Wallet: wallet.New(service.Wallet, log),
+		Wallet:  wallet.New(service.Wallet, log),
+		Fx:      fx.New(service.Fx, log),
+		Webhook: webhook.New(service.Webhook, log),
+		Stream:  stream.New(service.Stream, log),
+		WS:      ws.New(service.Stream, log),
+		ApiKey:  apikey.New(service.ApiKey, log),
+		Admin:   admin.New(service.Wallet, log),
+		Health:  health.New(service.Health, log),
 	}
 }
@@ -0,0 +1,175 @@
+package fx_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/fx"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/repository"
+	fxSvc "tryingMicro/OrderAccepter/internal/service/fx"
+)
+
+type MockFxService struct {
+	mock.Mock
+}
+
+func (m *MockFxService) UploadRates(ctx context.Context, rates []fxSvc.Rate) (fxSvc.UploadResult, error) {
+	args := m.Called(ctx, rates)
+	return args.Get(0).(fxSvc.UploadResult), args.Error(1)
+}
+func (m *MockFxService) GetRate(ctx context.Context, base, quote string, at time.Time) (repository.FxRate, error) {
+	args := m.Called(ctx, base, quote, at)
+	return args.Get(0).(repository.FxRate), args.Error(1)
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(svc fxSvc.FxService) *gin.Engine {
+	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
+	ctrl := fx.New(svc, zap.NewNop())
+	r.POST("/admin/fx-rates", ctrl.UploadRates)
+	r.GET("/fx-rates/", ctrl.GetRate)
+	return r
+}
+
+func TestUploadRates_JSON(t *testing.T) {
+	mockSvc := new(MockFxService)
+	mockSvc.On("UploadRates", mock.Anything, mock.MatchedBy(func(rates []fxSvc.Rate) bool {
+		return len(rates) == 1 && rates[0].Base == "USD" && rates[0].Rate.Equal(decimal.RequireFromString("0.92"))
+	})).Return(fxSvc.UploadResult{Saved: []repository.FxRate{{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: decimal.RequireFromString("0.92")}}}, nil)
+
+	body := `{"rates":[{"base":"USD","quote":"EUR","rate":"0.92","effective_from":"2026-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/fx-rates", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp map[string][]map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp["rates"], 1)
+	assert.Equal(t, "0.92", resp["rates"][0]["rate"])
+	assert.Empty(t, resp["rejected"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestUploadRates_ReportsRejected(t *testing.T) {
+	mockSvc := new(MockFxService)
+	mockSvc.On("UploadRates", mock.Anything, mock.Anything).Return(fxSvc.UploadResult{
+		Rejected: []fxSvc.RejectedRate{{Row: 1, Rate: fxSvc.Rate{Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("0.95")}}},
+	}, nil)
+
+	body := `{"rates":[{"base":"USD","quote":"EUR","rate":"0.95","effective_from":"2026-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/fx-rates", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp map[string][]map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Empty(t, resp["rates"])
+	require.Len(t, resp["rejected"], 1)
+	assert.Equal(t, float64(1), resp["rejected"][0]["row"])
+	assert.Equal(t, "0.95", resp["rejected"][0]["rate"])
+}
+
+func TestUploadRates_CSV(t *testing.T) {
+	mockSvc := new(MockFxService)
+	mockSvc.On("UploadRates", mock.Anything, mock.MatchedBy(func(rates []fxSvc.Rate) bool {
+		return len(rates) == 2 && rates[1].Quote == "JPY"
+	})).Return(fxSvc.UploadResult{Saved: []repository.FxRate{{}, {}}}, nil)
+
+	body := "base,quote,rate,effective_from\nUSD,EUR,0.92,\nUSD,JPY,151.2,\n"
+	req := httptest.NewRequest(http.MethodPost, "/admin/fx-rates", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestUploadRates_InvalidCSV(t *testing.T) {
+	mockSvc := new(MockFxService)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/fx-rates", strings.NewReader("base,quote\nUSD,EUR\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeInvalidRate, problemCode(t, rec))
+	mockSvc.AssertNotCalled(t, "UploadRates")
+}
+
+func TestUploadRates_ValidationError(t *testing.T) {
+	mockSvc := new(MockFxService)
+	mockSvc.On("UploadRates", mock.Anything, mock.Anything).Return(fxSvc.UploadResult{}, fxSvc.ErrInvalidRate)
+
+	body := `{"rates":[{"base":"USD","quote":"USD","rate":"1"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/fx-rates", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeInvalidRate, problemCode(t, rec))
+}
+
+func TestGetRate_NotFound(t *testing.T) {
+	mockSvc := new(MockFxService)
+	mockSvc.On("GetRate", mock.Anything, "USD", "EUR", time.Time{}).Return(repository.FxRate{}, fxSvc.ErrRateNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/fx-rates/?base=USD&quote=EUR", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problem.CodeRateNotFound, problemCode(t, rec))
+	mockSvc.AssertExpectations(t)
+}
+
+func TestGetRate_MissingQuery(t *testing.T) {
+	mockSvc := new(MockFxService)
+
+	req := httptest.NewRequest(http.MethodGet, "/fx-rates/?quote=EUR", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeValidationFailed, problemCode(t, rec))
+	assert.NotContains(t, rec.Body.String(), "Key:", "текст валидатора наружу не попадает")
+	mockSvc.AssertNotCalled(t, "GetRate")
+}
+
+// problemCode разбирает ответ problem+json и возвращает его код
+func problemCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p.Code
+}
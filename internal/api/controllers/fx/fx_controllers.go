@@ -0,0 +1,114 @@
+package fx
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/repository"
+	fxService "tryingMicro/OrderAccepter/internal/service/fx"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+type FxController interface {
+	UploadRates(c *gin.Context)
+	GetRate(c *gin.Context)
+}
+
+type fxController struct {
+	service fxService.FxService
+	log     logger.Logger
+}
+
+func New(service fxService.FxService, log logger.Logger) FxController {
+	return &fxController{
+		service: service,
+		log:     log,
+	}
+}
+
+type uploadRatesRequest struct {
+	Rates []fxService.Rate `json:"rates" binding:"required"`
+}
+
+// UploadRates принимает курсы в JSON ({"rates": [...]}) или в CSV (Content-Type: text/csv)
+func (fc *fxController) UploadRates(c *gin.Context) {
+	var (
+		rates []fxService.Rate
+		err   error
+	)
+	if c.ContentType() == "text/csv" {
+		rates, err = fxService.ParseCSV(c.Request.Body)
+	} else {
+		var req uploadRatesRequest
+		if err = c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(problem.Binding(err))
+			return
+		}
+		rates = req.Rates
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	result, err := fc.service.UploadRates(c.Request.Context(), rates)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	saved := make([]gin.H, 0, len(result.Saved))
+	for _, r := range result.Saved {
+		saved = append(saved, rateResponse(r))
+	}
+	// Курсы на уже занятые даты не перезаписываются и возвращаются отдельно
+	rejected := make([]gin.H, 0, len(result.Rejected))
+	for _, r := range result.Rejected {
+		rejected = append(rejected, gin.H{
+			"row":            r.Row,
+			"base":           r.Rate.Base,
+			"quote":          r.Rate.Quote,
+			"rate":           r.Rate.Rate,
+			"effective_from": r.Rate.EffectiveFrom,
+			"reason":         "rate already published for this effective_from",
+		})
+	}
+	c.JSON(http.StatusCreated, gin.H{"rates": saved, "rejected": rejected})
+}
+
+type getRateQuery struct {
+	Base  string     `form:"base"  binding:"required,len=3"`
+	Quote string     `form:"quote" binding:"required,len=3"`
+	At    *time.Time `form:"at"    time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (fc *fxController) GetRate(c *gin.Context) {
+	var query getRateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	var at time.Time
+	if query.At != nil {
+		at = *query.At
+	}
+
+	rate, err := fc.service.GetRate(c.Request.Context(), query.Base, query.Quote, at)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rateResponse(rate))
+}
+
+func rateResponse(r repository.FxRate) gin.H {
+	return gin.H{
+		"base":           r.BaseCurrency,
+		"quote":          r.QuoteCurrency,
+		"rate":           r.Rate,
+		"effective_from": r.EffectiveFrom,
+	}
+}
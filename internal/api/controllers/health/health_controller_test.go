@@ -0,0 +1,90 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/health"
+	healthSvc "tryingMicro/OrderAccepter/internal/service/health"
+)
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Live() healthSvc.Report {
+	return m.Called().Get(0).(healthSvc.Report)
+}
+func (m *MockHealthService) Ready(ctx context.Context) healthSvc.Report {
+	return m.Called(ctx).Get(0).(healthSvc.Report)
+}
+func (m *MockHealthService) Drain() {
+	m.Called()
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(svc healthSvc.HealthService) *gin.Engine {
+	r := gin.New()
+	ctrl := health.New(svc, zap.NewNop())
+	r.GET("/healthz", ctrl.Live)
+	r.GET("/readyz", ctrl.Ready)
+	return r
+}
+
+func TestLive(t *testing.T) {
+	mockSvc := new(MockHealthService)
+	mockSvc.On("Live").Return(healthSvc.Report{Status: healthSvc.StatusUp})
+
+	rec := httptest.NewRecorder()
+	setupRouter(mockSvc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"up"}`, rec.Body.String())
+}
+
+func TestReady(t *testing.T) {
+	version := int32(17)
+	up := healthSvc.Report{Status: healthSvc.StatusUp, Checks: map[string]healthSvc.Check{
+		healthSvc.CheckDatabase:   {Status: healthSvc.StatusUp},
+		healthSvc.CheckMigrations: {Status: healthSvc.StatusUp, Version: &version, Expected: &version},
+		healthSvc.CheckDraining:   {Status: healthSvc.StatusUp},
+	}}
+	draining := healthSvc.Report{Status: healthSvc.StatusDown, Checks: map[string]healthSvc.Check{
+		healthSvc.CheckDatabase: {Status: healthSvc.StatusUp},
+		healthSvc.CheckDraining: {Status: healthSvc.StatusDown, Error: "shutting down"},
+	}}
+
+	tests := []struct {
+		name   string
+		report healthSvc.Report
+		code   int
+	}{
+		{"ready", up, http.StatusOK},
+		{"not ready", draining, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockHealthService)
+			mockSvc.On("Ready", mock.Anything).Return(tt.report)
+
+			rec := httptest.NewRecorder()
+			setupRouter(mockSvc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.code, rec.Code)
+			var body healthSvc.Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.report, body)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	healthService "tryingMicro/OrderAccepter/internal/service/health"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+type HealthController interface {
+	Live(c *gin.Context)
+	Ready(c *gin.Context)
+}
+
+type healthController struct {
+	service healthService.HealthService
+	log     logger.Logger
+}
+
+func New(service healthService.HealthService, log logger.Logger) HealthController {
+	return &healthController{
+		service: service,
+		log:     log,
+	}
+}
+
+// Live отвечает 200, пока процесс обслуживает HTTP
+func (hc *healthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, hc.service.Live())
+}
+
+// Ready отвечает 503 с разбивкой по зависимостям, если хотя бы одна проверка не прошла
+func (hc *healthController) Ready(c *gin.Context) {
+	report := hc.service.Ready(c.Request.Context())
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
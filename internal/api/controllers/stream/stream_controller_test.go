@@ -0,0 +1,102 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/stream"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	streamSvc "tryingMicro/OrderAccepter/internal/service/stream"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+type MockStreamService struct {
+	mock.Mock
+}
+
+func (m *MockStreamService) Subscribe(ctx context.Context, walletID uuid.UUID, lastEventID string) (<-chan wallet.Transaction, error) {
+	args := m.Called(ctx, walletID, lastEventID)
+	if v := args.Get(0); v != nil {
+		return v.(<-chan wallet.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockStreamService) Run(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(svc streamSvc.StreamService) *gin.Engine {
+	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
+	ctrl := stream.New(svc, zap.NewNop())
+	r.GET("/wallets/:walletId/stream", ctrl.WalletStream)
+	return r
+}
+
+func TestWalletStream_WritesEvents(t *testing.T) {
+	walletID := uuid.New()
+	last := uuid.New()
+	tx := wallet.Transaction{ID: uuid.New(), WalletID: walletID, Type: wallet.OperationDeposit, BalanceAfter: decimal.NewFromInt(30)}
+
+	events := make(chan wallet.Transaction, 1)
+	events <- tx
+	close(events)
+	mockSvc := new(MockStreamService)
+	mockSvc.On("Subscribe", mock.Anything, walletID, last.String()).Return((<-chan wallet.Transaction)(events), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/stream", nil)
+	req.Header.Set("Last-Event-ID", last.String())
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "id: "+tx.ID.String()+"\nevent: transaction\ndata: {")
+	assert.Contains(t, body, `"balance_after":"30"`)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestWalletStream_Errors(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockStreamService)
+	mockSvc.On("Subscribe", mock.Anything, walletID, "").Return(nil, streamSvc.ErrWalletNotFound)
+	mockSvc.On("Subscribe", mock.Anything, walletID, "bad").Return(nil, streamSvc.ErrInvalidLastEventID)
+
+	rec := httptest.NewRecorder()
+	setupRouter(mockSvc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/stream", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problem.CodeWalletNotFound, problemCode(t, rec))
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/stream", nil)
+	req.Header.Set("Last-Event-ID", "bad")
+	rec = httptest.NewRecorder()
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeInvalidLastEventID, problemCode(t, rec))
+}
+
+// problemCode разбирает ответ problem+json и возвращает его код
+func problemCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p.Code
+}
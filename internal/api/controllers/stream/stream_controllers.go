@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	eventName = "transaction"
+	// Комментарий раз в keepAliveInterval не дает прокси закрыть простаивающее соединение
+	keepAliveInterval = 15 * time.Second
+)
+
+type StreamController interface {
+	WalletStream(c *gin.Context)
+}
+
+type streamController struct {
+	service streamService.StreamService
+	log     logger.Logger
+}
+
+func New(service streamService.StreamService, log logger.Logger) StreamController {
+	return &streamController{
+		service: service,
+		log:     log,
+	}
+}
+
+// WalletStream отдает записи журнала кошелька как Server-Sent Events. id события - id записи,
+// по нему браузер сам переподключается с заголовком Last-Event-ID.
+func (sc *streamController) WalletStream(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
+		return
+	}
+
+	events, err := sc.service.Subscribe(c.Request.Context(), walletID, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	// Канал событий закрывается сам при отключении клиента или остановке сервера
+	for {
+		select {
+		case t, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(t)
+			if err != nil {
+				sc.log.Error("WalletStream", zap.Error(err))
+				return
+			}
+			if _, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", t.ID, eventName, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
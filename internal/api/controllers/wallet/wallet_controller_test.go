@@ -14,11 +14,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"tryingMicro/OrderAccepter/internal/api/controllers/wallet"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
 	"tryingMicro/OrderAccepter/internal/repository"
 	walletSvc "tryingMicro/OrderAccepter/internal/service/wallet"
 )
@@ -32,37 +35,121 @@ type MockWalletService struct {
 	mock.Mock
 }
 
-func (m *MockWalletService) ProcessOperation(ctx context.Context, walletID uuid.UUID, opType string, amount float64) (repository.Wallet, error) {
-	args := m.Called(ctx, walletID, opType, amount)
-	return args.Get(0).(repository.Wallet), args.Error(1)
+func (m *MockWalletService) ProcessOperation(ctx context.Context, op walletSvc.Operation) (walletSvc.OperationResult, error) {
+	args := m.Called(ctx, op)
+	return args.Get(0).(walletSvc.OperationResult), args.Error(1)
 }
 
 func (m *MockWalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (repository.Wallet, error) {
 	args := m.Called(ctx, walletID)
 	return args.Get(0).(repository.Wallet), args.Error(1)
 }
-func (m *MockWalletService) CreateWallet(ctx context.Context) (repository.Wallet, error) {
+func (m *MockWalletService) CreateWallet(ctx context.Context, params walletSvc.NewWallet) (repository.Wallet, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repository.Wallet), args.Error(1)
+}
+func (m *MockWalletService) GetTransaction(ctx context.Context, transactionID uuid.UUID) (walletSvc.Transaction, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).(walletSvc.Transaction), args.Error(1)
+}
+func (m *MockWalletService) ListTransactions(ctx context.Context, walletID uuid.UUID, filter walletSvc.TransactionFilter) (walletSvc.TransactionPage, error) {
+	args := m.Called(ctx, walletID, filter)
+	return args.Get(0).(walletSvc.TransactionPage), args.Error(1)
+}
+func (m *MockWalletService) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (walletSvc.TransferResult, error) {
+	args := m.Called(ctx, fromWalletID, toWalletID, amount)
+	return args.Get(0).(walletSvc.TransferResult), args.Error(1)
+}
+func (m *MockWalletService) Convert(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (walletSvc.ConversionResult, error) {
+	args := m.Called(ctx, fromWalletID, toWalletID, amount)
+	return args.Get(0).(walletSvc.ConversionResult), args.Error(1)
+}
+func (m *MockWalletService) ExpireHolds(ctx context.Context) (int, error) {
 	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockWalletService) ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) (walletSvc.ReversalResult, error) {
+	args := m.Called(ctx, transactionID, amount)
+	return args.Get(0).(walletSvc.ReversalResult), args.Error(1)
+}
+func (m *MockWalletService) ChangeStatus(ctx context.Context, walletID uuid.UUID, change walletSvc.StatusChange) (repository.Wallet, error) {
+	args := m.Called(ctx, walletID, change)
 	return args.Get(0).(repository.Wallet), args.Error(1)
 }
+func (m *MockWalletService) ProcessOperationIdempotent(ctx context.Context, key string, op walletSvc.Operation) (walletSvc.OperationResult, bool, error) {
+	args := m.Called(ctx, key, op)
+	return args.Get(0).(walletSvc.OperationResult), args.Bool(1), args.Error(2)
+}
+func (m *MockWalletService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockWalletService) FindWallets(ctx context.Context, lookup walletSvc.WalletLookup) ([]repository.Wallet, error) {
+	args := m.Called(ctx, lookup)
+	return args.Get(0).([]repository.Wallet), args.Error(1)
+}
+func (m *MockWalletService) RequestAdjustment(ctx context.Context, walletID uuid.UUID, r walletSvc.AdjustmentRequest) (walletSvc.AdjustmentResult, error) {
+	args := m.Called(ctx, walletID, r)
+	return args.Get(0).(walletSvc.AdjustmentResult), args.Error(1)
+}
+func (m *MockWalletService) ApproveAdjustment(ctx context.Context, id uuid.UUID, actor string) (walletSvc.AdjustmentResult, error) {
+	args := m.Called(ctx, id, actor)
+	return args.Get(0).(walletSvc.AdjustmentResult), args.Error(1)
+}
+func (m *MockWalletService) RejectAdjustment(ctx context.Context, id uuid.UUID, actor, reason string) (repository.ManualAdjustment, error) {
+	args := m.Called(ctx, id, actor, reason)
+	return args.Get(0).(repository.ManualAdjustment), args.Error(1)
+}
+func (m *MockWalletService) ListAdjustments(ctx context.Context, filter walletSvc.AdjustmentFilter) ([]repository.ManualAdjustment, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]repository.ManualAdjustment), args.Error(1)
+}
 func setupRouter(svc walletSvc.WalletService) *gin.Engine {
 	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
 	ctrl := wallet.New(svc, zap.NewNop())
 	r.POST("/wallet/", ctrl.ProcessOperation)
 	r.GET("/wallets/:walletId", ctrl.GetBalance)
 	r.POST("/wallets", ctrl.CreateWallet)
+	r.GET("/wallets/:walletId/transactions", ctrl.ListTransactions)
+	r.POST("/transfers/", ctrl.Transfer)
+	r.POST("/transactions/:id/reverse", ctrl.ReverseTransaction)
+	r.POST("/admin/wallets/:walletId/freeze", ctrl.FreezeWallet)
+	r.POST("/admin/wallets/:walletId/unfreeze", ctrl.UnfreezeWallet)
+	r.POST("/admin/wallets/:walletId/close", ctrl.CloseWallet)
 	return r
 }
 
-func makeWallet(balance float64) repository.Wallet {
+func makeWallet(balance int64) repository.Wallet {
 	return repository.Wallet{
 		ID:        uuid.New(),
-		Balance:   balance,
+		Balance:   decimal.NewFromInt(balance),
+		Currency:  "USD",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 }
 
+func amountOf(v string) interface{} {
+	return mock.MatchedBy(func(amount decimal.Decimal) bool {
+		return amount.Equal(decimal.RequireFromString(v))
+	})
+}
+
+// operation сопоставляет операцию без холда с ожидаемыми полями
+func operation(walletID uuid.UUID, opType, amount string) interface{} {
+	return mock.MatchedBy(func(op walletSvc.Operation) bool {
+		return op.WalletID == walletID && op.Type == opType && op.HoldID == nil &&
+			op.Amount.Equal(decimal.RequireFromString(amount))
+	})
+}
+
+// asStaff выполняет запрос от имени ключа сотрудника staffID
+func asStaff(req *http.Request, staffID string) *http.Request {
+	p := auth.Principal{ID: "key-" + staffID, Scopes: []string{auth.RoleOperator}, Subject: auth.StaffSubject(staffID)}
+	return req.WithContext(auth.WithPrincipal(req.Context(), p))
+}
+
 func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
 	t.Helper()
 	var body map[string]interface{}
@@ -72,9 +159,10 @@ func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]interface
 
 func TestProcessOperation_Deposit_Success(t *testing.T) {
 	w := makeWallet(150)
+	w.HeldBalance = decimal.NewFromInt(20)
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, w.ID, walletSvc.OperationDeposit, 50.0).
-		Return(w, nil)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(w.ID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{Wallet: w}, nil)
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, w.ID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -85,15 +173,16 @@ func TestProcessOperation_Deposit_Success(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.InDelta(t, 150.0, resp["balance"], 0.001)
+	assert.Equal(t, "150", resp["balance"])
+	assert.Equal(t, "130", resp["available_balance"])
 	mockSvc.AssertExpectations(t)
 }
 
 func TestProcessOperation_Withdraw_Success(t *testing.T) {
 	w := makeWallet(70)
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, w.ID, walletSvc.OperationWithdraw, 30.0).
-		Return(w, nil)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(w.ID, walletSvc.OperationWithdraw, "30")).
+		Return(walletSvc.OperationResult{Wallet: w}, nil)
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"WITHDRAW","amount":30}`, w.ID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -104,7 +193,7 @@ func TestProcessOperation_Withdraw_Success(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.InDelta(t, 70.0, resp["balance"], 0.001)
+	assert.Equal(t, "70", resp["balance"])
 	mockSvc.AssertExpectations(t)
 }
 
@@ -132,6 +221,14 @@ func TestProcessOperation_InvalidBody_MissingFields(t *testing.T) {
 	setupRouter(mockSvc).ServeHTTP(rec, req)
 
 	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var resp problem.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, problem.CodeValidationFailed, resp.Code)
+	assert.Equal(t, []problem.FieldError{
+		{Field: "valletId", Reason: "is required"},
+		{Field: "operationType", Reason: "is required"},
+	}, resp.Errors)
 	mockSvc.AssertNotCalled(t, "ProcessOperation")
 }
 
@@ -149,11 +246,63 @@ func TestProcessOperation_InvalidBody_NegativeAmount(t *testing.T) {
 	mockSvc.AssertNotCalled(t, "ProcessOperation")
 }
 
+func TestProcessOperation_AmountTooLarge(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":"1000000000000000000"}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "VALIDATION_FAILED", resp["code"])
+	mockSvc.AssertNotCalled(t, "ProcessOperation")
+}
+
+func TestProcessOperation_StringAmount(t *testing.T) {
+	w := makeWallet(150)
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(w.ID, walletSvc.OperationDeposit, "0.3")).
+		Return(walletSvc.OperationResult{Wallet: w}, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":"0.30"}`, w.ID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_InvalidBody_TooManyDecimals(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":"10.005"}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp problem.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, problem.CodeValidationFailed, resp.Code)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "amount", resp.Errors[0].Field)
+	mockSvc.AssertNotCalled(t, "ProcessOperation")
+}
+
 func TestProcessOperation_WalletNotFound(t *testing.T) {
 	walletID := uuid.New()
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, walletID, walletSvc.OperationDeposit, 50.0).
-		Return(repository.Wallet{}, walletSvc.ErrWalletNotFound)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(walletID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{}, walletSvc.ErrWalletNotFound)
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, walletID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -164,15 +313,16 @@ func TestProcessOperation_WalletNotFound(t *testing.T) {
 
 	require.Equal(t, http.StatusNotFound, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, walletSvc.ErrWalletNotFound.Error(), resp["error"])
+	assert.Equal(t, "WALLET_NOT_FOUND", resp["code"])
+	assert.Equal(t, walletSvc.ErrWalletNotFound.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
 func TestProcessOperation_InsufficientFunds(t *testing.T) {
 	walletID := uuid.New()
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, walletID, walletSvc.OperationWithdraw, 100.0).
-		Return(repository.Wallet{}, walletSvc.ErrInsufficientFunds)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(walletID, walletSvc.OperationWithdraw, "100")).
+		Return(walletSvc.OperationResult{}, walletSvc.ErrInsufficientFunds)
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"WITHDRAW","amount":100}`, walletID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -183,15 +333,16 @@ func TestProcessOperation_InsufficientFunds(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, walletSvc.ErrInsufficientFunds.Error(), resp["error"])
+	assert.Equal(t, "INSUFFICIENT_FUNDS", resp["code"])
+	assert.Equal(t, walletSvc.ErrInsufficientFunds.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
 func TestProcessOperation_InvalidOperation(t *testing.T) {
 	walletID := uuid.New()
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, walletID, "REFUND", 50.0).
-		Return(repository.Wallet{}, walletSvc.ErrInvalidOperation)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(walletID, "REFUND", "50")).
+		Return(walletSvc.OperationResult{}, walletSvc.ErrInvalidOperation)
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"REFUND","amount":50}`, walletID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -202,15 +353,16 @@ func TestProcessOperation_InvalidOperation(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, walletSvc.ErrInvalidOperation.Error(), resp["error"])
+	assert.Equal(t, "INVALID_OPERATION", resp["code"])
+	assert.Equal(t, walletSvc.ErrInvalidOperation.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
 func TestProcessOperation_ServiceError(t *testing.T) {
 	walletID := uuid.New()
 	mockSvc := new(MockWalletService)
-	mockSvc.On("ProcessOperation", mock.Anything, walletID, walletSvc.OperationDeposit, 50.0).
-		Return(repository.Wallet{}, errors.New("db error"))
+	mockSvc.On("ProcessOperation", mock.Anything, operation(walletID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{}, errors.New("db error"))
 
 	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, walletID)
 	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
@@ -221,10 +373,106 @@ func TestProcessOperation_ServiceError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, "internal server error", resp["error"])
+	assert.Equal(t, "INTERNAL_ERROR", resp["code"])
+	assert.Equal(t, "internal server error", resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
+func TestProcessOperation_IdempotencyKey_FirstRequest(t *testing.T) {
+	w := makeWallet(150)
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperationIdempotent", mock.Anything, "key-1", operation(w.ID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{Wallet: w}, false, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, w.ID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "key-1")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "150", resp["balance"])
+	mockSvc.AssertNotCalled(t, "ProcessOperation")
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_IdempotencyKey_Replayed(t *testing.T) {
+	w := makeWallet(150)
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperationIdempotent", mock.Anything, "key-1", operation(w.ID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{Wallet: w}, true, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, w.ID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "key-1")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_IdempotencyKey_Reused(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperationIdempotent", mock.Anything, "key-1", operation(walletID, walletSvc.OperationDeposit, "70")).
+		Return(walletSvc.OperationResult{}, false, walletSvc.ErrIdempotencyKeyReused)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":70}`, walletID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "key-1")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", resp["code"])
+	assert.Equal(t, walletSvc.ErrIdempotencyKeyReused.Error(), resp["detail"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_IdempotencyKey_Conflict(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperationIdempotent", mock.Anything, "key-1", operation(walletID, walletSvc.OperationDeposit, "50")).
+		Return(walletSvc.OperationResult{}, false, walletSvc.ErrIdempotencyKeyConflict)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, walletID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "key-1")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_IdempotencyKey_TooLong(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"DEPOSIT","amount":50}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockSvc.AssertNotCalled(t, "ProcessOperationIdempotent")
+}
+
 func TestGetBalance_Success(t *testing.T) {
 	w := makeWallet(200)
 	mockSvc := new(MockWalletService)
@@ -238,10 +486,28 @@ func TestGetBalance_Success(t *testing.T) {
 	require.Equal(t, http.StatusOK, rec.Code)
 	resp := decodeBody(t, rec)
 	assert.Equal(t, w.ID.String(), resp["id"])
-	assert.InDelta(t, 200.0, resp["balance"], 0.001)
+	assert.Equal(t, "200", resp["balance"])
 	mockSvc.AssertExpectations(t)
 }
 
+func TestGetBalance_AvailableBalance(t *testing.T) {
+	w := makeWallet(200)
+	w.HeldBalance = decimal.NewFromInt(50)
+	mockSvc := new(MockWalletService)
+	mockSvc.On("GetBalance", mock.Anything, w.ID).Return(w, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+w.ID.String(), nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "200", resp["balance"])
+	assert.Equal(t, "50", resp["held_balance"])
+	assert.Equal(t, "150", resp["available_balance"])
+}
+
 func TestGetBalance_InvalidWalletID(t *testing.T) {
 	mockSvc := new(MockWalletService)
 
@@ -267,7 +533,8 @@ func TestGetBalance_WalletNotFound(t *testing.T) {
 
 	require.Equal(t, http.StatusNotFound, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, walletSvc.ErrWalletNotFound.Error(), resp["error"])
+	assert.Equal(t, "WALLET_NOT_FOUND", resp["code"])
+	assert.Equal(t, walletSvc.ErrWalletNotFound.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
@@ -284,13 +551,14 @@ func TestGetBalance_ServiceError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, "internal server error", resp["error"])
+	assert.Equal(t, "INTERNAL_ERROR", resp["code"])
+	assert.Equal(t, "internal server error", resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 func TestCreateWallet_Success(t *testing.T) {
 	w := makeWallet(0)
 	mockSvc := new(MockWalletService)
-	mockSvc.On("CreateWallet", mock.Anything).Return(w, nil)
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{}).Return(w, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/wallets", nil)
 	rec := httptest.NewRecorder()
@@ -300,13 +568,97 @@ func TestCreateWallet_Success(t *testing.T) {
 	require.Equal(t, http.StatusCreated, rec.Code)
 	resp := decodeBody(t, rec)
 	assert.Equal(t, w.ID.String(), resp["id"])
-	assert.InDelta(t, 0.0, resp["balance"], 0.001)
+	assert.Equal(t, "0", resp["balance"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateWallet_WithCurrency(t *testing.T) {
+	w := makeWallet(0)
+	w.Currency = "EUR"
+	mockSvc := new(MockWalletService)
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{Currency: "EUR"}).Return(w, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"currency":"EUR"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "EUR", resp["currency"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateWallet_InvalidCurrencyFormat(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"currency":"EURO"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockSvc.AssertNotCalled(t, "CreateWallet")
+}
+
+func TestCreateWallet_WithExternalRef(t *testing.T) {
+	w := makeWallet(0)
+	owner, ref := "merchant-1", "order-42"
+	w.OwnerID, w.ExternalRef = &owner, &ref
+	mockSvc := new(MockWalletService)
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{OwnerID: owner, ExternalRef: ref}).Return(w, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"ownerId":"merchant-1","externalRef":"order-42"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, owner, resp["owner_id"])
+	assert.Equal(t, ref, resp["external_ref"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateWallet_ExternalRefExists(t *testing.T) {
+	mockSvc := new(MockWalletService)
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{ExternalRef: "order-42"}).Return(repository.Wallet{}, walletSvc.ErrExternalRefExists)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"externalRef":"order-42"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "EXTERNAL_REF_EXISTS", resp["code"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateWallet_UnsupportedCurrency(t *testing.T) {
+	mockSvc := new(MockWalletService)
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{Currency: "XXX"}).Return(repository.Wallet{}, walletSvc.ErrUnsupportedCurrency)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets", strings.NewReader(`{"currency":"XXX"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "UNSUPPORTED_CURRENCY", resp["code"])
+	assert.Equal(t, walletSvc.ErrUnsupportedCurrency.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
 
 func TestCreateWallet_ServiceError(t *testing.T) {
 	mockSvc := new(MockWalletService)
-	mockSvc.On("CreateWallet", mock.Anything).Return(repository.Wallet{}, errors.New("db error"))
+	mockSvc.On("CreateWallet", mock.Anything, walletSvc.NewWallet{}).Return(repository.Wallet{}, errors.New("db error"))
 
 	req := httptest.NewRequest(http.MethodPost, "/wallets", nil)
 	rec := httptest.NewRecorder()
@@ -315,6 +667,474 @@ func TestCreateWallet_ServiceError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rec.Code)
 	resp := decodeBody(t, rec)
-	assert.Equal(t, "internal server error", resp["error"])
+	assert.Equal(t, "INTERNAL_ERROR", resp["code"])
+	assert.Equal(t, "internal server error", resp["detail"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestListTransactions_Success(t *testing.T) {
+	walletID := uuid.New()
+	page := walletSvc.TransactionPage{
+		Transactions: []walletSvc.Transaction{{
+			ID:            uuid.New(),
+			WalletID:      walletID,
+			Type:          walletSvc.OperationDeposit,
+			Amount:        decimal.NewFromInt(50),
+			BalanceBefore: decimal.NewFromInt(100),
+			BalanceAfter:  decimal.NewFromInt(150),
+			CreatedAt:     time.Now(),
+		}},
+		NextCursor: "next",
+	}
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ListTransactions", mock.Anything, walletID, mock.MatchedBy(func(f walletSvc.TransactionFilter) bool {
+		return f.Type == walletSvc.OperationDeposit && f.Limit == 10 && f.Cursor == "abc" &&
+			f.MinAmount != nil && f.MinAmount.Equal(decimal.NewFromInt(5)) && f.MaxAmount == nil &&
+			f.From != nil && f.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) && f.To == nil
+	})).Return(page, nil)
+
+	url := "/wallets/" + walletID.String() + "/transactions?type=DEPOSIT&limit=10&cursor=abc&minAmount=5&from=2024-01-01T00:00:00Z"
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "next", resp["next_cursor"])
+	require.Len(t, resp["transactions"], 1)
+	tx := resp["transactions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "150", tx["balance_after"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestListTransactions_InvalidQuery(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+uuid.New().String()+"/transactions?limit=1000", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockSvc.AssertNotCalled(t, "ListTransactions")
+}
+
+func TestListTransactions_InvalidCursor(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ListTransactions", mock.Anything, walletID, mock.Anything).
+		Return(walletSvc.TransactionPage{}, walletSvc.ErrInvalidCursor)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/transactions?cursor=bad", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "INVALID_CURSOR", resp["code"])
+	assert.Equal(t, walletSvc.ErrInvalidCursor.Error(), resp["detail"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestListTransactions_WalletNotFound(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ListTransactions", mock.Anything, walletID, mock.Anything).
+		Return(walletSvc.TransactionPage{}, walletSvc.ErrWalletNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/"+walletID.String()+"/transactions", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestTransfer_Success(t *testing.T) {
+	from := makeWallet(60)
+	to := makeWallet(50)
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Transfer", mock.Anything, from.ID, to.ID, amountOf("40")).
+		Return(walletSvc.TransferResult{From: from, To: to}, nil)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":40}`, from.ID, to.ID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "60", resp["from"].(map[string]interface{})["balance"])
+	assert.Equal(t, "50", resp["to"].(map[string]interface{})["balance"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestTransfer_InvalidBody(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"amount":40}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockSvc.AssertNotCalled(t, "Transfer")
+}
+
+func TestTransfer_InsufficientFunds(t *testing.T) {
+	fromID, toID := uuid.New(), uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Transfer", mock.Anything, fromID, toID, amountOf("40")).
+		Return(walletSvc.TransferResult{}, walletSvc.ErrInsufficientFunds)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":40}`, fromID, toID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", resp["code"])
+	assert.Equal(t, walletSvc.ErrInsufficientFunds.Error(), resp["detail"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestTransfer_CurrencyMismatch(t *testing.T) {
+	fromID, toID := uuid.New(), uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Transfer", mock.Anything, fromID, toID, amountOf("40")).
+		Return(walletSvc.TransferResult{}, walletSvc.ErrCurrencyMismatch)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":40}`, fromID, toID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "CURRENCY_MISMATCH", resp["code"])
+	assert.Equal(t, walletSvc.ErrCurrencyMismatch.Error(), resp["detail"])
 	mockSvc.AssertExpectations(t)
 }
+
+func TestTransfer_WalletNotFound(t *testing.T) {
+	fromID, toID := uuid.New(), uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Transfer", mock.Anything, fromID, toID, amountOf("40")).
+		Return(walletSvc.TransferResult{}, walletSvc.ErrWalletNotFound)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":40}`, fromID, toID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestTransfer_Convert_Success(t *testing.T) {
+	from := makeWallet(60)
+	to := makeWallet(1595)
+	to.Currency = "JPY"
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Convert", mock.Anything, from.ID, to.ID, amountOf("10.55")).
+		Return(walletSvc.ConversionResult{
+			From:      from,
+			To:        to,
+			Rate:      decimal.RequireFromString("151.2345"),
+			Credited:  decimal.NewFromInt(1595),
+			Remainder: decimal.RequireFromString("0.523975"),
+		}, nil)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":"10.55","convert":true}`, from.ID, to.ID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "151.2345", resp["rate"])
+	assert.Equal(t, "1595", resp["credited"])
+	assert.Equal(t, "0.523975", resp["remainder"])
+	assert.Equal(t, "JPY", resp["to"].(map[string]interface{})["currency"])
+	mockSvc.AssertNotCalled(t, "Transfer")
+	mockSvc.AssertExpectations(t)
+}
+
+func TestTransfer_Convert_RateNotFound(t *testing.T) {
+	fromID, toID := uuid.New(), uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("Convert", mock.Anything, fromID, toID, amountOf("40")).
+		Return(walletSvc.ConversionResult{}, walletSvc.ErrRateNotFound)
+
+	body := fmt.Sprintf(`{"fromWalletId":%q,"toWalletId":%q,"amount":40,"convert":true}`, fromID, toID)
+	req := httptest.NewRequest(http.MethodPost, "/transfers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "RATE_NOT_FOUND", resp["code"])
+	assert.Equal(t, walletSvc.ErrRateNotFound.Error(), resp["detail"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_Hold(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = decimal.NewFromInt(30)
+	holdID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(w.ID, walletSvc.OperationHold, "30")).
+		Return(walletSvc.OperationResult{
+			Wallet: w,
+			Hold:   &walletSvc.Hold{ID: holdID, WalletID: w.ID, Amount: decimal.NewFromInt(30), Status: walletSvc.HoldStatusActive},
+		}, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"HOLD","amount":30}`, w.ID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, holdID.String(), resp["hold"].(map[string]interface{})["id"])
+	assert.Equal(t, "70", resp["wallet"].(map[string]interface{})["available_balance"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_VoidWithoutAmount(t *testing.T) {
+	w := makeWallet(100)
+	holdID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperation", mock.Anything, walletSvc.Operation{WalletID: w.ID, Type: walletSvc.OperationVoid, HoldID: &holdID}).
+		Return(walletSvc.OperationResult{Wallet: w, Hold: &walletSvc.Hold{ID: holdID, Status: walletSvc.HoldStatusVoided}}, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"VOID","holdId":%q}`, w.ID, holdID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestProcessOperation_CaptureInactiveHold(t *testing.T) {
+	walletID, holdID := uuid.New(), uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperation", mock.Anything, mock.MatchedBy(func(op walletSvc.Operation) bool {
+		return op.Type == walletSvc.OperationCapture && op.HoldID != nil && *op.HoldID == holdID
+	})).
+		Return(walletSvc.OperationResult{}, walletSvc.ErrHoldNotActive)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"CAPTURE","holdId":%q,"amount":10}`, walletID, holdID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "HOLD_NOT_ACTIVE", resp["code"])
+	assert.Equal(t, walletSvc.ErrHoldNotActive.Error(), resp["detail"])
+}
+
+func TestProcessOperation_HoldIdempotencyKey(t *testing.T) {
+	w := makeWallet(100)
+	holdID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperationIdempotent", mock.Anything, "hold-1", operation(w.ID, walletSvc.OperationHold, "30")).
+		Return(walletSvc.OperationResult{Wallet: w, Hold: &walletSvc.Hold{ID: holdID, Status: walletSvc.HoldStatusActive}}, true, nil)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"HOLD","amount":30}`, w.ID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "hold-1")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	resp := decodeBody(t, rec)
+	assert.Equal(t, holdID.String(), resp["hold"].(map[string]interface{})["id"])
+	mockSvc.AssertNotCalled(t, "ProcessOperation")
+	mockSvc.AssertExpectations(t)
+}
+
+func TestReverseTransaction_Success(t *testing.T) {
+	w := makeWallet(60)
+	originalID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ReverseTransaction", mock.Anything, originalID, amountOf("15")).
+		Return(walletSvc.ReversalResult{
+			Wallet:   w,
+			Reversal: walletSvc.Transaction{ID: uuid.New(), Type: walletSvc.OperationReversal, Amount: decimal.NewFromInt(15), ReversalOf: &originalID},
+			Original: walletSvc.Transaction{ID: originalID, Type: walletSvc.OperationDeposit, ReversedAmount: decimal.NewFromInt(15)},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/"+originalID.String()+"/reverse", strings.NewReader(`{"amount":"15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, originalID.String(), resp["reversal"].(map[string]interface{})["reversal_of"])
+	assert.Equal(t, "15", resp["original"].(map[string]interface{})["reversed_amount"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestReverseTransaction_EmptyBodyReversesFully(t *testing.T) {
+	originalID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ReverseTransaction", mock.Anything, originalID, amountOf("0")).
+		Return(walletSvc.ReversalResult{Wallet: makeWallet(0)}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/"+originalID.String()+"/reverse", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestReverseTransaction_Errors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{walletSvc.ErrTransactionNotFound, http.StatusNotFound},
+		{walletSvc.ErrTransactionAlreadyReversed, http.StatusConflict},
+		{walletSvc.ErrReversalExceedsBalance, http.StatusBadRequest},
+		{walletSvc.ErrTransactionNotReversible, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			originalID := uuid.New()
+			mockSvc := new(MockWalletService)
+			mockSvc.On("ReverseTransaction", mock.Anything, originalID, mock.Anything).
+				Return(walletSvc.ReversalResult{}, tc.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/transactions/"+originalID.String()+"/reverse", nil)
+			rec := httptest.NewRecorder()
+
+			setupRouter(mockSvc).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.code, rec.Code)
+			resp := decodeBody(t, rec)
+			assert.Equal(t, tc.err.Error(), resp["detail"])
+		})
+	}
+}
+
+func TestFreezeWallet_Success(t *testing.T) {
+	w := makeWallet(100)
+	w.Status = walletSvc.WalletStatusFrozen
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ChangeStatus", mock.Anything, w.ID, walletSvc.StatusChange{
+		Status: walletSvc.WalletStatusFrozen,
+		Reason: "fraud check",
+		Actor:  auth.StaffSubject("ops"),
+	}).Return(w, nil)
+
+	// actor из тела игнорируется: сотрудник берется из аутентифицированного клиента
+	body := `{"reason":"fraud check","actor":"someone-else"}`
+	req := asStaff(httptest.NewRequest(http.MethodPost, "/admin/wallets/"+w.ID.String()+"/freeze", strings.NewReader(body)), "ops")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, walletSvc.WalletStatusFrozen, resp["status"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestChangeStatus_MissingReason(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	req := asStaff(httptest.NewRequest(http.MethodPost, "/admin/wallets/"+uuid.New().String()+"/unfreeze", strings.NewReader(`{}`)), "ops")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockSvc.AssertNotCalled(t, "ChangeStatus")
+}
+
+func TestChangeStatus_RequiresPrincipal(t *testing.T) {
+	mockSvc := new(MockWalletService)
+
+	body := `{"reason":"fraud check","actor":"ops"}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+uuid.New().String()+"/freeze", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockSvc.AssertNotCalled(t, "ChangeStatus")
+}
+
+func TestCloseWallet_NotEmpty(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ChangeStatus", mock.Anything, walletID, mock.Anything).Return(repository.Wallet{}, walletSvc.ErrWalletNotEmpty)
+
+	body := `{"reason":"customer request"}`
+	req := asStaff(httptest.NewRequest(http.MethodPost, "/admin/wallets/"+walletID.String()+"/close", strings.NewReader(body)), "ops")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "WALLET_NOT_EMPTY", resp["code"])
+	assert.Equal(t, walletSvc.ErrWalletNotEmpty.Error(), resp["detail"])
+}
+
+func TestProcessOperation_FrozenWallet(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWalletService)
+	mockSvc.On("ProcessOperation", mock.Anything, operation(walletID, "WITHDRAW", "10")).
+		Return(walletSvc.OperationResult{}, walletSvc.ErrWalletFrozen)
+
+	body := fmt.Sprintf(`{"valletId":%q,"operationType":"WITHDRAW","amount":10}`, walletID)
+	req := httptest.NewRequest(http.MethodPost, "/wallet/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	resp := decodeBody(t, rec)
+	assert.Equal(t, "WALLET_FROZEN", resp["code"])
+	assert.Equal(t, walletSvc.ErrWalletFrozen.Error(), resp["detail"])
+}
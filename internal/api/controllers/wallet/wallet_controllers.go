@@ -2,20 +2,31 @@ package wallet
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"time"
 	"tryingMicro/OrderAccepter/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"go.uber.org/zap"
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
 	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
 	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/money"
 )
 
 type WalletController interface {
 	ProcessOperation(c *gin.Context)
 	GetBalance(c *gin.Context)
 	CreateWallet(ctx *gin.Context)
+	ListTransactions(c *gin.Context)
+	Transfer(c *gin.Context)
+	ReverseTransaction(c *gin.Context)
+	FreezeWallet(c *gin.Context)
+	UnfreezeWallet(c *gin.Context)
+	CloseWallet(c *gin.Context)
 }
 
 type walletController struct {
@@ -30,73 +41,312 @@ func New(service walletService.WalletService, log logger.Logger) WalletControlle
 	}
 }
 
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
 type operationRequest struct {
-	ValletId      uuid.UUID `json:"valletId" binding:"required"`
-	OperationType string    `json:"operationType" binding:"required"`
-	Amount        float64   `json:"amount"        binding:"required,gt=0"`
+	ValletId      uuid.UUID       `json:"valletId" binding:"required"`
+	OperationType string          `json:"operationType" binding:"required"`
+	Amount        decimal.Decimal `json:"amount"`
+	// HoldId обязателен для CAPTURE и VOID
+	HoldId *uuid.UUID `json:"holdId"`
 }
 
 func (wc *walletController) ProcessOperation(c *gin.Context) {
 	var req operationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	op := walletService.Operation{
+		WalletID: req.ValletId,
+		Type:     req.OperationType,
+		Amount:   req.Amount,
+		HoldID:   req.HoldId,
+	}
+	if err := op.ValidateAmount(); err != nil {
+		_ = c.Error(problem.InvalidField("amount", err.Error()))
 		return
 	}
 
-	result, err := wc.service.ProcessOperation(c.Request.Context(), req.ValletId, req.OperationType, req.Amount)
-	if err != nil {
-		switch {
-		case errors.Is(err, walletService.ErrWalletNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		case errors.Is(err, walletService.ErrInsufficientFunds),
-			errors.Is(err, walletService.ErrInvalidOperation):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		default:
-			wc.log.Error("ProcessOperation", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	var (
+		result walletService.OperationResult
+		err    error
+	)
+	if key := c.GetHeader(idempotencyKeyHeader); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			_ = c.Error(problem.InvalidField(idempotencyKeyHeader, "must be at most 255 characters long"))
+			return
 		}
+		var replayed bool
+		result, replayed, err = wc.service.ProcessOperationIdempotent(c.Request.Context(), key, op)
+		if replayed {
+			c.Header(idempotentReplayedHeader, "true")
+		}
+	} else {
+		result, err = wc.service.ProcessOperation(c.Request.Context(), op)
+	}
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	if result.Hold != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"wallet": Response(result.Wallet),
+			"hold":   result.Hold,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, Response(result.Wallet))
+}
+
+type transferRequest struct {
+	FromWalletId uuid.UUID       `json:"fromWalletId" binding:"required"`
+	ToWalletId   uuid.UUID       `json:"toWalletId"   binding:"required"`
+	Amount       decimal.Decimal `json:"amount"`
+	// Convert разрешает перевод между кошельками в разных валютах по текущему курсу
+	Convert bool `json:"convert"`
+}
+
+func (wc *walletController) Transfer(c *gin.Context) {
+	var req transferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+	if err := money.Validate(req.Amount); err != nil {
+		_ = c.Error(problem.InvalidField("amount", err.Error()))
+		return
+	}
+	if req.Convert {
+		wc.convert(c, req)
+		return
+	}
+
+	result, err := wc.service.Transfer(c.Request.Context(), req.FromWalletId, req.ToWalletId, req.Amount)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": Response(result.From),
+		"to":   Response(result.To),
+	})
+}
+
+func (wc *walletController) convert(c *gin.Context, req transferRequest) {
+	result, err := wc.service.Convert(c.Request.Context(), req.FromWalletId, req.ToWalletId, req.Amount)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":      Response(result.From),
+		"to":        Response(result.To),
+		"rate":      result.Rate,
+		"credited":  result.Credited,
+		"remainder": result.Remainder,
+	})
 }
 
 func (wc *walletController) GetBalance(c *gin.Context) {
 	walletID, err := uuid.Parse(c.Param("walletId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet id"})
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
 		return
 	}
 
 	result, err := wc.service.GetBalance(c.Request.Context(), walletID)
 	if err != nil {
-		if errors.Is(err, walletService.ErrWalletNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		wc.log.Error("GetBalance", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, Response(result))
+}
+
+type reverseTransactionRequest struct {
+	// Amount не задан - сторнируется весь остаток операции
+	Amount decimal.Decimal `json:"amount"`
+}
+
+func (wc *walletController) ReverseTransaction(c *gin.Context) {
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+
+	// Тело необязательно: без него операция сторнируется полностью
+	var req reverseTransactionRequest
+	if err = c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	result, err := wc.service.ReverseTransaction(c.Request.Context(), transactionID, req.Amount)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet":   Response(result.Wallet),
+		"reversal": result.Reversal,
+		"original": result.Original,
+	})
+}
+
+type statusChangeRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
+
+func (wc *walletController) FreezeWallet(c *gin.Context) {
+	wc.changeStatus(c, walletService.WalletStatusFrozen)
+}
+
+func (wc *walletController) UnfreezeWallet(c *gin.Context) {
+	wc.changeStatus(c, walletService.WalletStatusActive)
+}
+
+func (wc *walletController) CloseWallet(c *gin.Context) {
+	wc.changeStatus(c, walletService.WalletStatusClosed)
+}
+
+func (wc *walletController) changeStatus(c *gin.Context, status string) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
+		return
+	}
+
+	var req statusChangeRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	// Сотрудник берется только из аутентифицированного клиента, как и в AdminController
+	actor, err := auth.Actor(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	result, err := wc.service.ChangeStatus(c.Request.Context(), walletID, walletService.StatusChange{
+		Status: status,
+		Reason: req.Reason,
+		Actor:  actor,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response(result))
+}
+
+type createWalletRequest struct {
+	Currency    string `json:"currency"    binding:"omitempty,len=3"`
+	OwnerID     string `json:"ownerId"     binding:"omitempty,max=128"`
+	ExternalRef string `json:"externalRef" binding:"omitempty,max=128"`
+}
+
 func (c *walletController) CreateWallet(ctx *gin.Context) {
-	w, err := c.service.CreateWallet(ctx.Request.Context())
+	// Тело необязательно: без него кошелек создается в валюте по умолчанию
+	var req createWalletRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = ctx.Error(problem.Binding(err))
+		return
+	}
+
+	w, err := c.service.CreateWallet(ctx.Request.Context(), walletService.NewWallet{
+		Currency:    req.Currency,
+		OwnerID:     req.OwnerID,
+		ExternalRef: req.ExternalRef,
+	})
 	if err != nil {
-		c.log.Error("CreateWallet failed", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, walletResponse(w))
+	ctx.JSON(http.StatusCreated, Response(w))
 }
 
-func walletResponse(w repository.Wallet) gin.H {
+// Response - представление кошелька в ответах API, общее для клиентских и админских маршрутов
+func Response(w repository.Wallet) gin.H {
 	return gin.H{
-		"id":         w.ID,
-		"balance":    w.Balance,
-		"created_at": w.CreatedAt,
-		"updated_at": w.UpdatedAt,
+		"id":                w.ID,
+		"balance":           w.Balance,
+		"held_balance":      w.HeldBalance,
+		"available_balance": walletService.AvailableBalance(w),
+		"currency":          w.Currency,
+		"status":            w.Status,
+		"created_at":        w.CreatedAt,
+		"updated_at":        w.UpdatedAt,
+		"owner_id":          w.OwnerID,
+		"external_ref":      w.ExternalRef,
+	}
+}
+
+type listTransactionsQuery struct {
+	Type      string     `form:"type"`
+	MinAmount string     `form:"minAmount"`
+	MaxAmount string     `form:"maxAmount"`
+	From      *time.Time `form:"from"      time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to"        time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor    string     `form:"cursor"`
+	Limit     int        `form:"limit"     binding:"omitempty,min=1,max=100"`
+}
+
+func (wc *walletController) ListTransactions(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("walletId", "must be a valid UUID"))
+		return
 	}
+
+	var query listTransactionsQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	filter := walletService.TransactionFilter{
+		Type:   query.Type,
+		From:   query.From,
+		To:     query.To,
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	}
+	if query.MinAmount != "" {
+		minAmount, err := money.Parse(query.MinAmount)
+		if err != nil {
+			_ = c.Error(problem.InvalidField("minAmount", "must be a decimal amount"))
+			return
+		}
+		filter.MinAmount = &minAmount
+	}
+	if query.MaxAmount != "" {
+		maxAmount, err := money.Parse(query.MaxAmount)
+		if err != nil {
+			_ = c.Error(problem.InvalidField("maxAmount", "must be a decimal amount"))
+			return
+		}
+		filter.MaxAmount = &maxAmount
+	}
+
+	result, err := wc.service.ListTransactions(c.Request.Context(), walletID, filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
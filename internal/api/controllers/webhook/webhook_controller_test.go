@@ -0,0 +1,171 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/webhook"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	webhookSvc "tryingMicro/OrderAccepter/internal/service/webhook"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Publish(ctx context.Context, event outbox.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+func (m *MockWebhookService) Register(ctx context.Context, r webhookSvc.Registration) (webhookSvc.Subscription, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(webhookSvc.Subscription), args.Error(1)
+}
+func (m *MockWebhookService) List(ctx context.Context) ([]webhookSvc.Subscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]webhookSvc.Subscription), args.Error(1)
+}
+func (m *MockWebhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]webhookSvc.Delivery, error) {
+	args := m.Called(ctx, subscriptionID, status, limit)
+	return args.Get(0).([]webhookSvc.Delivery), args.Error(1)
+}
+func (m *MockWebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (webhookSvc.Delivery, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(webhookSvc.Delivery), args.Error(1)
+}
+func (m *MockWebhookService) DeliverPending(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func setupRouter(svc webhookSvc.WebhookService) *gin.Engine {
+	r := gin.New()
+	r.Use(problem.Middleware(zap.NewNop()))
+	ctrl := webhook.New(svc, zap.NewNop())
+	r.POST("/webhooks/", ctrl.Register)
+	r.DELETE("/webhooks/:id", ctrl.Delete)
+	r.GET("/webhooks/:id/deliveries", ctrl.ListDeliveries)
+	r.POST("/webhooks/deliveries/:id/redeliver", ctrl.Redeliver)
+	return r
+}
+
+func TestRegister_Created(t *testing.T) {
+	walletID := uuid.New()
+	mockSvc := new(MockWebhookService)
+	mockSvc.On("Register", mock.Anything, webhookSvc.Registration{
+		URL:        "https://example.com/hook",
+		WalletID:   &walletID,
+		EventTypes: []string{"deposit"},
+	}).Return(webhookSvc.Subscription{ID: uuid.New(), Secret: "whsec_x", EventTypes: []string{"deposit"}}, nil)
+
+	body := `{"url":"https://example.com/hook","walletId":"` + walletID.String() + `","eventTypes":["deposit"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "whsec_x", resp["secret"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestRegister_InvalidSubscription(t *testing.T) {
+	mockSvc := new(MockWebhookService)
+	mockSvc.On("Register", mock.Anything, mock.Anything).Return(webhookSvc.Subscription{}, webhookSvc.ErrInvalidSubscription)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/", strings.NewReader(`{"url":"x","eventTypes":["nope"]}`))
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeInvalidWebhook, problemCode(t, rec))
+}
+
+func TestRegister_ForbiddenDestination(t *testing.T) {
+	mockSvc := new(MockWebhookService)
+	err := fmt.Errorf("%w: %w", webhookSvc.ErrInvalidSubscription, webhookSvc.ErrForbiddenDestination)
+	mockSvc.On("Register", mock.Anything, mock.Anything).Return(webhookSvc.Subscription{}, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/", strings.NewReader(`{"url":"http://127.0.0.1/","eventTypes":["deposit"]}`))
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeWebhookDestinationNotAllowed, problemCode(t, rec))
+}
+
+func TestRegister_BindingError(t *testing.T) {
+	mockSvc := new(MockWebhookService)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/", strings.NewReader(`{"url":"https://example.com"}`))
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.CodeValidationFailed, problemCode(t, rec))
+	assert.Contains(t, rec.Body.String(), `"field":"eventTypes"`)
+	mockSvc.AssertNotCalled(t, "Register")
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	id := uuid.New()
+	mockSvc := new(MockWebhookService)
+	mockSvc.On("Delete", mock.Anything, id).Return(webhookSvc.ErrSubscriptionNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/webhooks/"+id.String(), nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problem.CodeWebhookNotFound, problemCode(t, rec))
+}
+
+func TestRedeliver_Accepted(t *testing.T) {
+	id := uuid.New()
+	mockSvc := new(MockWebhookService)
+	mockSvc.On("Redeliver", mock.Anything, id).Return(webhookSvc.Delivery{ID: id, Status: webhookSvc.DeliveryStatusPending}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/deliveries/"+id.String()+"/redeliver", nil)
+	rec := httptest.NewRecorder()
+
+	setupRouter(mockSvc).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"PENDING"`)
+	mockSvc.AssertExpectations(t)
+}
+
+// problemCode разбирает ответ problem+json и возвращает его код
+func problemCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p.Code
+}
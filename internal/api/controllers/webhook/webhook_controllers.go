@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	webhookService "tryingMicro/OrderAccepter/internal/service/webhook"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+type WebhookController interface {
+	Register(c *gin.Context)
+	List(c *gin.Context)
+	Delete(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type webhookController struct {
+	service webhookService.WebhookService
+	log     logger.Logger
+}
+
+func New(service webhookService.WebhookService, log logger.Logger) WebhookController {
+	return &webhookController{
+		service: service,
+		log:     log,
+	}
+}
+
+type registerRequest struct {
+	URL string `json:"url" binding:"required"`
+	// Без walletId подписка получает события всех кошельков
+	WalletId   *uuid.UUID `json:"walletId"`
+	EventTypes []string   `json:"eventTypes" binding:"required,min=1"`
+}
+
+// Register создает подписку. Секрет для проверки подписи есть только в этом ответе.
+func (wc *webhookController) Register(c *gin.Context) {
+	var req registerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	sub, err := wc.service.Register(c.Request.Context(), webhookService.Registration{
+		URL:        req.URL,
+		WalletID:   req.WalletId,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (wc *webhookController) List(c *gin.Context) {
+	subs, err := wc.service.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": subs})
+}
+
+func (wc *webhookController) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+
+	if err = wc.service.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type listDeliveriesQuery struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (wc *webhookController) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+	var query listDeliveriesQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(problem.Binding(err))
+		return
+	}
+
+	deliveries, err := wc.service.ListDeliveries(c.Request.Context(), id, query.Status, query.Limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// Redeliver ставит доставку, в том числе из DEAD, в очередь на немедленную отправку
+func (wc *webhookController) Redeliver(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(problem.InvalidField("id", "must be a valid UUID"))
+		return
+	}
+
+	delivery, err := wc.service.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,205 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+)
+
+var errSubscriptionLimit = errors.New("subscription limit reached")
+
+// conn - одно WebSocket-соединение. Читает команды в run, пишет в writeLoop; все
+// исходящие сообщения проходят через буфер send, переполнение которого отключает клиента.
+type conn struct {
+	ctrl *wsController
+	ws   *websocket.Conn
+
+	// ctx несет клиента из запроса, по нему StreamService проверяет доступ к кошелькам
+	ctx    context.Context
+	cancel context.CancelFunc
+	send   chan any
+
+	mu          sync.Mutex
+	subs        map[uuid.UUID]context.CancelFunc
+	closeCode   int
+	closeReason string
+}
+
+func newConn(ctrl *wsController, gc *gin.Context, ws *websocket.Conn) *conn {
+	ctx, cancel := context.WithCancel(gc.Request.Context())
+	return &conn{
+		ctrl:      ctrl,
+		ws:        ws,
+		ctx:       ctx,
+		cancel:    cancel,
+		send:      make(chan any, ctrl.sendBuffer),
+		subs:      make(map[uuid.UUID]context.CancelFunc),
+		closeCode: websocket.CloseNormalClosure,
+	}
+}
+
+func (c *conn) run() {
+	writerDone := make(chan struct{})
+	go func() {
+		c.writeLoop()
+		close(writerDone)
+	}()
+
+	c.readLoop()
+	c.cancel()
+	<-writerDone
+}
+
+func (c *conn) readLoop() {
+	pongWait := 2 * c.ctrl.pingInterval
+	c.ws.SetReadLimit(maxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) && c.ctx.Err() == nil {
+				c.ctrl.log.Debug("websocket read failed", zap.Error(err))
+			}
+			return
+		}
+
+		var msg clientMessage
+		if err = json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(errorMessage{Type: typeError, Error: "invalid message"})
+			continue
+		}
+		switch msg.Type {
+		case typeSubscribe:
+			c.subscribe(msg)
+		case typeUnsubscribe:
+			c.unsubscribe(msg)
+		default:
+			c.enqueue(errorMessage{Type: typeError, ID: msg.ID, Error: "unknown message type"})
+		}
+	}
+}
+
+// writeLoop единственный пишет в сокет: gorilla/websocket не допускает параллельной записи
+func (c *conn) writeLoop() {
+	ticker := time.NewTicker(c.ctrl.pingInterval)
+	defer ticker.Stop()
+	defer c.ws.Close()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.mu.Lock()
+			code, reason := c.closeCode, c.closeReason
+			c.mu.Unlock()
+			_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+			return
+		case msg := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.ws.WriteJSON(msg); err != nil {
+				c.cancel()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}
+}
+
+// enqueue не ждет медленного клиента: при полном буфере соединение закрывается,
+// чтобы он не тормозил общие потоки событий и не копил память сервера
+func (c *conn) enqueue(msg any) {
+	select {
+	case c.send <- msg:
+	case <-c.ctx.Done():
+	default:
+		c.drop(websocket.ClosePolicyViolation, "slow consumer")
+	}
+}
+
+func (c *conn) drop(code int, reason string) {
+	c.mu.Lock()
+	if c.ctx.Err() == nil {
+		c.closeCode, c.closeReason = code, reason
+	}
+	c.mu.Unlock()
+	c.ctrl.log.Warn("websocket client dropped", zap.String("reason", reason))
+	c.cancel()
+}
+
+func (c *conn) subscribe(msg clientMessage) {
+	ack := ackMessage{Type: typeSubscribed, ID: msg.ID, WalletIDs: []uuid.UUID{}}
+	for _, walletID := range msg.WalletIds {
+		if err := c.subscribeWallet(walletID); err != nil {
+			ack.Errors = append(ack.Errors, subscriptionError{WalletID: walletID, Error: err.Error()})
+			continue
+		}
+		ack.WalletIDs = append(ack.WalletIDs, walletID)
+	}
+	c.enqueue(ack)
+}
+
+func (c *conn) subscribeWallet(walletID uuid.UUID) error {
+	c.mu.Lock()
+	_, exists := c.subs[walletID]
+	full := len(c.subs) >= c.ctrl.maxSubscriptions
+	c.mu.Unlock()
+	if exists {
+		return nil
+	}
+	if full {
+		return errSubscriptionLimit
+	}
+
+	subCtx, cancel := context.WithCancel(c.ctx)
+	events, err := c.ctrl.stream.Subscribe(subCtx, walletID, "")
+	if err != nil {
+		cancel()
+		if errors.Is(err, streamService.ErrWalletNotFound) {
+			return err
+		}
+		c.ctrl.log.Error("websocket subscribe", zap.String("walletId", walletID.String()), zap.Error(err))
+		return errors.New("internal server error")
+	}
+
+	c.mu.Lock()
+	c.subs[walletID] = cancel
+	c.mu.Unlock()
+
+	go func() {
+		for t := range events {
+			c.enqueue(eventMessage{Type: typeEvent, WalletID: walletID, Event: t})
+		}
+		// Поток закрылся не по отписке - сервер останавливается
+		if subCtx.Err() == nil {
+			c.drop(websocket.CloseGoingAway, "server shutting down")
+		}
+	}()
+	return nil
+}
+
+func (c *conn) unsubscribe(msg clientMessage) {
+	c.mu.Lock()
+	for _, walletID := range msg.WalletIds {
+		if cancel, ok := c.subs[walletID]; ok {
+			cancel()
+			delete(c.subs, walletID)
+		}
+	}
+	c.mu.Unlock()
+	c.enqueue(ackMessage{Type: typeUnsubscribed, ID: msg.ID, WalletIDs: msg.WalletIds})
+}
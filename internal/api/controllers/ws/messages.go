@@ -0,0 +1,47 @@
+package ws
+
+import (
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+const (
+	typeSubscribe    = "subscribe"
+	typeUnsubscribe  = "unsubscribe"
+	typeSubscribed   = "subscribed"
+	typeUnsubscribed = "unsubscribed"
+	typeEvent        = "event"
+	typeError        = "error"
+)
+
+// clientMessage - команда клиента. ID возвращается в ответе, чтобы клиент мог сопоставить их.
+type clientMessage struct {
+	Type      string      `json:"type"`
+	ID        string      `json:"id,omitempty"`
+	WalletIds []uuid.UUID `json:"walletIds"`
+}
+
+type subscriptionError struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Error    string    `json:"error"`
+}
+
+type ackMessage struct {
+	Type      string              `json:"type"`
+	ID        string              `json:"id,omitempty"`
+	WalletIDs []uuid.UUID         `json:"wallet_ids"`
+	Errors    []subscriptionError `json:"errors,omitempty"`
+}
+
+// eventMessage несет запись журнала: тип операции, сумму и баланс после нее
+type eventMessage struct {
+	Type     string             `json:"type"`
+	WalletID uuid.UUID          `json:"wallet_id"`
+	Event    wallet.Transaction `json:"event"`
+}
+
+type errorMessage struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
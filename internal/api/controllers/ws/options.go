@@ -0,0 +1,32 @@
+package ws
+
+import "time"
+
+type Option func(*wsController)
+
+// WithPingInterval задает период ping. Соединение закрывается, если pong не пришел за два периода.
+func WithPingInterval(d time.Duration) Option {
+	return func(wc *wsController) {
+		if d > 0 {
+			wc.pingInterval = d
+		}
+	}
+}
+
+// WithSendBuffer задает число исходящих сообщений, после которого клиент считается медленным и отключается
+func WithSendBuffer(n int) Option {
+	return func(wc *wsController) {
+		if n > 0 {
+			wc.sendBuffer = n
+		}
+	}
+}
+
+// WithMaxSubscriptions ограничивает число кошельков на одно соединение
+func WithMaxSubscriptions(n int) Option {
+	return func(wc *wsController) {
+		if n > 0 {
+			wc.maxSubscriptions = n
+		}
+	}
+}
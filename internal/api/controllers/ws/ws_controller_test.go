@@ -0,0 +1,227 @@
+package ws_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/controllers/ws"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	streamSvc "tryingMicro/OrderAccepter/internal/service/stream"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+// fakeStream отдает тесту канал и контекст каждой подписки
+type fakeStream struct {
+	mu       sync.Mutex
+	channels map[uuid.UUID]chan wallet.Transaction
+	contexts map[uuid.UUID]context.Context
+	missing  map[uuid.UUID]bool
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{
+		channels: make(map[uuid.UUID]chan wallet.Transaction),
+		contexts: make(map[uuid.UUID]context.Context),
+		missing:  make(map[uuid.UUID]bool),
+	}
+}
+
+func (f *fakeStream) Subscribe(ctx context.Context, walletID uuid.UUID, _ string) (<-chan wallet.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.missing[walletID] {
+		return nil, streamSvc.ErrWalletNotFound
+	}
+	ch := make(chan wallet.Transaction)
+	f.channels[walletID] = ch
+	f.contexts[walletID] = ctx
+	return ch, nil
+}
+
+func (f *fakeStream) Run(context.Context) error { return nil }
+
+func (f *fakeStream) channel(walletID uuid.UUID) chan wallet.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.channels[walletID]
+}
+
+func (f *fakeStream) context(walletID uuid.UUID) context.Context {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.contexts[walletID]
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func dial(t *testing.T, stream streamSvc.StreamService, opts ...ws.Option) *websocket.Conn {
+	return dialAs(t, stream, nil, opts...)
+}
+
+// dialAs открывает соединение от имени p, как это делает middleware аутентификации
+func dialAs(t *testing.T, stream streamSvc.StreamService, p *auth.Principal, opts ...ws.Option) *websocket.Conn {
+	r := gin.New()
+	if p != nil {
+		r.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), *p))
+		})
+	}
+	r.GET("/ws", ws.New(stream, zap.NewNop(), opts...).Wallets)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func readJSON(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg map[string]interface{}
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWallets_SubscribeAndReceiveEvents(t *testing.T) {
+	allowed, missing := uuid.New(), uuid.New()
+	stream := newFakeStream()
+	stream.missing[missing] = true
+	conn := dial(t, stream)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type": "subscribe", "id": "1", "walletIds": []uuid.UUID{allowed, missing},
+	}))
+	ack := readJSON(t, conn)
+	assert.Equal(t, "subscribed", ack["type"])
+	assert.Equal(t, "1", ack["id"])
+	assert.Equal(t, []interface{}{allowed.String()}, ack["wallet_ids"])
+	require.Len(t, ack["errors"], 1)
+	assert.Equal(t, streamSvc.ErrWalletNotFound.Error(), ack["errors"].([]interface{})[0].(map[string]interface{})["error"])
+
+	stream.channel(allowed) <- wallet.Transaction{ID: uuid.New(), WalletID: allowed, Type: wallet.OperationDeposit, BalanceAfter: decimal.NewFromInt(15)}
+	event := readJSON(t, conn)
+	assert.Equal(t, "event", event["type"])
+	assert.Equal(t, allowed.String(), event["wallet_id"])
+	assert.Equal(t, "15", event["event"].(map[string]interface{})["balance_after"])
+}
+
+func TestWallets_RejectsForeignWallet(t *testing.T) {
+	owner := "alice"
+	foreign := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(10), Currency: "USD", OwnerID: &owner}
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetWallet(gomock.Any(), foreign.ID).Return(foreign, nil)
+
+	stream := streamSvc.New(repo, nil, zap.NewNop())
+	conn := dialAs(t, stream, &auth.Principal{ID: "key-bob", OwnerID: "bob", Scopes: []string{auth.ScopeWalletRead}})
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type": "subscribe", "id": "1", "walletIds": []uuid.UUID{foreign.ID},
+	}))
+	ack := readJSON(t, conn)
+
+	assert.Equal(t, []interface{}{}, ack["wallet_ids"])
+	require.Len(t, ack["errors"], 1)
+	assert.Equal(t, streamSvc.ErrWalletNotFound.Error(), ack["errors"].([]interface{})[0].(map[string]interface{})["error"])
+}
+
+func TestWallets_UnsubscribeStopsStream(t *testing.T) {
+	walletID := uuid.New()
+	stream := newFakeStream()
+	conn := dial(t, stream)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "subscribe", "walletIds": []uuid.UUID{walletID}}))
+	readJSON(t, conn)
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "unsubscribe", "id": "2", "walletIds": []uuid.UUID{walletID}}))
+	ack := readJSON(t, conn)
+
+	assert.Equal(t, "unsubscribed", ack["type"])
+	select {
+	case <-stream.context(walletID).Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription context not cancelled")
+	}
+}
+
+func TestWallets_SubscriptionLimit(t *testing.T) {
+	conn := dial(t, newFakeStream(), ws.WithMaxSubscriptions(1))
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "subscribe", "walletIds": []uuid.UUID{uuid.New(), uuid.New()}}))
+	ack := readJSON(t, conn)
+
+	assert.Len(t, ack["wallet_ids"], 1)
+	assert.Len(t, ack["errors"], 1)
+}
+
+func TestWallets_Heartbeat(t *testing.T) {
+	conn := dial(t, newFakeStream(), ws.WithPingInterval(20*time.Millisecond))
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	_ = conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	_, _, _ = conn.ReadMessage()
+
+	select {
+	case <-pinged:
+	default:
+		t.Fatal("no ping received")
+	}
+}
+
+func TestWallets_DropsSlowConsumer(t *testing.T) {
+	walletID := uuid.New()
+	stream := newFakeStream()
+	conn := dial(t, stream, ws.WithSendBuffer(1))
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "subscribe", "walletIds": []uuid.UUID{walletID}}))
+	readJSON(t, conn)
+
+	// Клиент не читает: сокет забивается, буфер переполняется и сервер закрывает соединение
+	ctx := stream.context(walletID)
+	ch := stream.channel(walletID)
+	event := wallet.Transaction{ID: uuid.New(), WalletID: walletID, Type: strings.Repeat("X", 4096)}
+	deadline := time.After(10 * time.Second)
+	for ctx.Err() == nil {
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+		case <-deadline:
+			t.Fatal("slow consumer was not dropped")
+		}
+	}
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+			}
+			return
+		}
+	}
+}
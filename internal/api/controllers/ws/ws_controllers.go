@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	DefaultPingInterval     = 30 * time.Second
+	DefaultSendBuffer       = 256
+	DefaultMaxSubscriptions = 100
+
+	writeWait      = 10 * time.Second
+	maxMessageSize = 64 * 1024
+)
+
+type WSController interface {
+	// Wallets открывает WebSocket, в котором клиент подписывается на события набора кошельков
+	Wallets(c *gin.Context)
+}
+
+type wsController struct {
+	stream   streamService.StreamService
+	log      logger.Logger
+	upgrader websocket.Upgrader
+
+	pingInterval     time.Duration
+	sendBuffer       int
+	maxSubscriptions int
+}
+
+// New создает контроллер WebSocket. Маршрут требует scope чтения, а владельца каждого
+// кошелька проверяет StreamService.Subscribe по клиенту из контекста запроса.
+func New(stream streamService.StreamService, log logger.Logger, opts ...Option) WSController {
+	wc := &wsController{
+		stream:           stream,
+		log:              log,
+		pingInterval:     DefaultPingInterval,
+		sendBuffer:       DefaultSendBuffer,
+		maxSubscriptions: DefaultMaxSubscriptions,
+	}
+	for _, opt := range opts {
+		opt(wc)
+	}
+	return wc
+}
+
+func (wc *wsController) Wallets(c *gin.Context) {
+	ws, err := wc.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту ошибкой
+		wc.log.Warn("websocket upgrade failed", zap.Error(err))
+		return
+	}
+	newConn(wc, c, ws).run()
+}
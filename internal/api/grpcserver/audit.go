@@ -0,0 +1,43 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	auditService "tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+// auditInterceptor пишет в журнал аудита вызовы, которые сервис отметил как
+// изменяющие. Чтение сервис не отмечает, и записи не появляется.
+func auditInterceptor(recorder auditService.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, scope := auditService.WithScope(ctx)
+		resp, err := handler(ctx, req)
+
+		entry, described := scope.Entry()
+		if !described {
+			return resp, err
+		}
+		code := int32(status.Code(err))
+		entry.Actor = auditService.Actor(ctx)
+		entry.Route = info.FullMethod
+		entry.StatusCode = &code
+		if m, ok := req.(proto.Message); ok {
+			if payload, mErr := (proto.MarshalOptions{Deterministic: true}).Marshal(m); mErr == nil {
+				entry.PayloadDigest = auditService.Digest(payload)
+			}
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			entry.ClientIP = p.Addr.String()
+			if host, _, splitErr := net.SplitHostPort(entry.ClientIP); splitErr == nil {
+				entry.ClientIP = host
+			}
+		}
+		_ = recorder.Record(ctx, entry)
+		return resp, err
+	}
+}
@@ -0,0 +1,106 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/service/apikey"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/walletpb"
+)
+
+// Authenticator проверяет ключ или JWT. Ему удовлетворяет server.Authenticators,
+// поэтому gRPC принимает те же учетные данные, что и HTTP.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// methodScopes - scope, нужный для метода. Метод без записи недоступен никому
+var methodScopes = map[string]string{
+	walletpb.WalletService_GetBalance_FullMethodName:       auth.ScopeWalletRead,
+	walletpb.WalletService_StreamEvents_FullMethodName:     auth.ScopeWalletRead,
+	walletpb.WalletService_ProcessOperation_FullMethodName: auth.ScopeWalletWrite,
+	walletpb.WalletService_CreateWallet_FullMethodName:     auth.ScopeWalletWrite,
+}
+
+func authUnaryInterceptor(a Authenticator, log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, a, info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authStreamInterceptor(a Authenticator, log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// principalStream подменяет контекст потока контекстом с клиентом
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate кладет клиента в контекст или возвращает Unauthenticated/PermissionDenied.
+// Как и в HTTP, в лог попадает только открытый префикс ключа.
+func authenticate(ctx context.Context, a Authenticator, method string, log logger.Logger) (context.Context, error) {
+	token := credentials(ctx)
+	if token == "" {
+		log.Warn("authentication failed", zap.String("method", method), zap.String("reason", "missing credentials"))
+		return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+	}
+	p, err := a.Authenticate(ctx, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			log.Warn("authentication failed",
+				zap.String("method", method),
+				zap.String("keyPrefix", apikey.Prefix(token)),
+				zap.String("reason", err.Error()),
+			)
+			return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+		}
+		return nil, statusError(log, method, err)
+	}
+
+	scope, known := methodScopes[method]
+	if !known || !p.HasScope(scope) {
+		log.Warn("access denied", zap.String("principal", p.ID), zap.String("scope", scope), zap.String("method", method))
+		return nil, status.Error(codes.PermissionDenied, "missing scope "+scope)
+	}
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+// credentials берет ключ или JWT из метаданных authorization: Bearer или x-api-key
+func credentials(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
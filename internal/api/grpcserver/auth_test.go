@@ -0,0 +1,105 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/walletpb"
+)
+
+type fakeAuthenticator map[string][]string
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, token string) (auth.Principal, error) {
+	scopes, ok := f[token]
+	if !ok {
+		return auth.Principal{}, auth.ErrUnauthenticated
+	}
+	return auth.Principal{ID: token, OwnerID: "merchant-1", Scopes: scopes}, nil
+}
+
+var keys = fakeAuthenticator{
+	"wk_read_secret":  {auth.ScopeWalletRead},
+	"wk_write_secret": {auth.ScopeWalletWrite},
+}
+
+func newAuthClient(t *testing.T, wallet *mocks.MockWalletService, stream *mocks.MockStreamService) walletpb.WalletServiceClient {
+	return newClient(t, wallet, stream,
+		grpc.UnaryInterceptor(authUnaryInterceptor(keys, zap.NewNop())),
+		grpc.StreamInterceptor(authStreamInterceptor(keys, zap.NewNop())),
+	)
+}
+
+func withKey(header, value string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), header, value)
+}
+
+func TestAuth_RejectsUnauthenticatedCalls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// Сервис не вызывается ни разу: моки без ожиданий провалят тест
+	client := newAuthClient(t, mocks.NewMockWalletService(ctrl), mocks.NewMockStreamService(ctrl))
+	req := &walletpb.ProcessOperationRequest{WalletId: uuid.NewString(), OperationType: walletService.OperationDeposit, Amount: "10"}
+
+	_, err := client.ProcessOperation(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.ProcessOperation(withKey("authorization", "Bearer wk_unknown"), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.CreateWallet(withKey("authorization", "Basic wk_write_secret"), &walletpb.CreateWalletRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	events, err := client.StreamEvents(context.Background(), &walletpb.StreamEventsRequest{WalletId: uuid.NewString()})
+	require.NoError(t, err)
+	_, err = events.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuth_Scopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := newAuthClient(t, mocks.NewMockWalletService(ctrl), mocks.NewMockStreamService(ctrl))
+
+	_, err := client.ProcessOperation(withKey("x-api-key", "wk_read_secret"), &walletpb.ProcessOperationRequest{
+		WalletId: uuid.NewString(), OperationType: walletService.OperationDeposit, Amount: "10",
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetBalance(withKey("x-api-key", "wk_write_secret"), &walletpb.GetBalanceRequest{WalletId: uuid.NewString()})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuth_PrincipalReachesService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	wallet := mocks.NewMockWalletService(ctrl)
+	stream := mocks.NewMockStreamService(ctrl)
+	w := repository.Wallet{ID: uuid.New()}
+	hasPrincipal := gomock.Cond(func(ctx context.Context) bool {
+		p, ok := auth.FromContext(ctx)
+		return ok && p.ID == "wk_read_secret"
+	})
+	wallet.EXPECT().GetBalance(hasPrincipal, w.ID).Return(w, nil)
+	events := make(chan walletService.Transaction)
+	close(events)
+	stream.EXPECT().Subscribe(hasPrincipal, w.ID, "").Return((<-chan walletService.Transaction)(events), nil)
+	client := newAuthClient(t, wallet, stream)
+
+	_, err := client.GetBalance(withKey("authorization", "Bearer wk_read_secret"), &walletpb.GetBalanceRequest{WalletId: w.ID.String()})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(withKey("x-api-key", "wk_read_secret"), 5*time.Second)
+	defer cancel()
+	sub, err := client.StreamEvents(ctx, &walletpb.StreamEventsRequest{WalletId: w.ID.String()})
+	require.NoError(t, err)
+	_, err = sub.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
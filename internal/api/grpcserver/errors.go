@@ -0,0 +1,48 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tryingMicro/OrderAccepter/internal/auth"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+// statusError переводит ошибки сервисного слоя в коды gRPC. Соответствие то же, что
+// у HTTP-контроллеров, кроме нехватки средств: для gRPC это FailedPrecondition.
+func statusError(log logger.Logger, method string, err error) error {
+	switch {
+	case errors.Is(err, walletService.ErrWalletNotFound),
+		errors.Is(err, walletService.ErrHoldNotFound),
+		errors.Is(err, streamService.ErrWalletNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, walletService.ErrInsufficientFunds),
+		errors.Is(err, walletService.ErrWalletFrozen),
+		errors.Is(err, walletService.ErrWalletClosed),
+		errors.Is(err, walletService.ErrHoldNotActive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, walletService.ErrInvalidOperation),
+		errors.Is(err, walletService.ErrInvalidAmount),
+		errors.Is(err, walletService.ErrHoldIDRequired),
+		errors.Is(err, walletService.ErrUnsupportedCurrency),
+		errors.Is(err, walletService.ErrIdempotencyKeyReused),
+		errors.Is(err, streamService.ErrInvalidLastEventID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, walletService.ErrIdempotencyKeyConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		log.Error(method, zap.Error(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
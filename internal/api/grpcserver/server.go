@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"tryingMicro/OrderAccepter/internal/service"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/walletpb"
+	"tryingMicro/OrderAccepter/util/config"
+)
+
+type Server interface {
+	Run(config config.Config) error
+	Shutdown(ctx context.Context) error
+}
+
+type server struct {
+	grpcServer *grpc.Server
+}
+
+// NewServer требует учетные данные на каждый вызов. Аутентификация идет первой,
+// чтобы аудит видел клиента.
+func NewServer(services *service.Services, authenticator Authenticator, log logger.Logger) Server {
+	unary := []grpc.UnaryServerInterceptor{authUnaryInterceptor(authenticator, log)}
+	if services.Audit != nil {
+		unary = append(unary, auditInterceptor(services.Audit))
+	}
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.StreamInterceptor(authStreamInterceptor(authenticator, log)),
+	)
+	walletpb.RegisterWalletServiceServer(grpcServer, newWalletServer(services.Wallet, services.Stream, log))
+	return &server{grpcServer: grpcServer}
+}
+
+func (s *server) Run(config config.Config) error {
+	lis, err := net.Listen("tcp", config.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	return s.grpcServer.Serve(lis)
+}
+
+// Shutdown дожидается активных вызовов, а по истечении ctx обрывает их
+func (s *server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,196 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"tryingMicro/OrderAccepter/internal/repository"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/walletpb"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type walletServer struct {
+	walletpb.UnimplementedWalletServiceServer
+
+	wallet walletService.WalletService
+	stream streamService.StreamService
+	log    logger.Logger
+}
+
+func newWalletServer(wallet walletService.WalletService, stream streamService.StreamService, log logger.Logger) *walletServer {
+	return &walletServer{
+		wallet: wallet,
+		stream: stream,
+		log:    log,
+	}
+}
+
+func (s *walletServer) CreateWallet(ctx context.Context, req *walletpb.CreateWalletRequest) (*walletpb.Wallet, error) {
+	w, err := s.wallet.CreateWallet(ctx, walletService.NewWallet{
+		Currency:    req.GetCurrency(),
+		OwnerID:     req.GetOwnerId(),
+		ExternalRef: req.GetExternalRef(),
+	})
+	if err != nil {
+		return nil, statusError(s.log, "CreateWallet", err)
+	}
+	return walletMessage(w), nil
+}
+
+func (s *walletServer) GetBalance(ctx context.Context, req *walletpb.GetBalanceRequest) (*walletpb.Wallet, error) {
+	walletID, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid wallet id")
+	}
+
+	w, err := s.wallet.GetBalance(ctx, walletID)
+	if err != nil {
+		return nil, statusError(s.log, "GetBalance", err)
+	}
+	return walletMessage(w), nil
+}
+
+func (s *walletServer) ProcessOperation(ctx context.Context, req *walletpb.ProcessOperationRequest) (*walletpb.ProcessOperationResponse, error) {
+	op, err := operationFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result walletService.OperationResult
+	if key := req.GetIdempotencyKey(); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Error(codes.InvalidArgument, "invalid idempotency key")
+		}
+		result, _, err = s.wallet.ProcessOperationIdempotent(ctx, key, op)
+	} else {
+		result, err = s.wallet.ProcessOperation(ctx, op)
+	}
+	if err != nil {
+		return nil, statusError(s.log, "ProcessOperation", err)
+	}
+	return &walletpb.ProcessOperationResponse{
+		Wallet: walletMessage(result.Wallet),
+		Hold:   holdMessage(result.Hold),
+	}, nil
+}
+
+// operationFromRequest разбирает запрос. Пустая сумма допустима: CAPTURE без суммы
+// списывает весь холд, а VOID ее не использует
+func operationFromRequest(req *walletpb.ProcessOperationRequest) (walletService.Operation, error) {
+	op := walletService.Operation{Type: req.GetOperationType()}
+
+	var err error
+	if op.WalletID, err = uuid.Parse(req.GetWalletId()); err != nil {
+		return op, status.Error(codes.InvalidArgument, "invalid wallet id")
+	}
+	if raw := req.GetAmount(); raw != "" {
+		if op.Amount, err = decimal.NewFromString(raw); err != nil {
+			return op, status.Error(codes.InvalidArgument, "invalid amount")
+		}
+	}
+	if raw := req.GetHoldId(); raw != "" {
+		holdID, err := uuid.Parse(raw)
+		if err != nil {
+			return op, status.Error(codes.InvalidArgument, "invalid hold id")
+		}
+		op.HoldID = &holdID
+	}
+	if err = op.ValidateAmount(); err != nil {
+		return op, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return op, nil
+}
+
+func (s *walletServer) StreamEvents(req *walletpb.StreamEventsRequest, stream walletpb.WalletService_StreamEventsServer) error {
+	walletID, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid wallet id")
+	}
+
+	events, err := s.stream.Subscribe(stream.Context(), walletID, req.GetLastEventId())
+	if err != nil {
+		return statusError(s.log, "StreamEvents", err)
+	}
+	for t := range events {
+		if err = stream.Send(transactionMessage(t)); err != nil {
+			return err
+		}
+	}
+	// Канал закрывается при отключении клиента или остановке сервера
+	if err = stream.Context().Err(); err != nil {
+		return statusError(s.log, "StreamEvents", err)
+	}
+	return status.Error(codes.Unavailable, "server is shutting down")
+}
+
+func walletMessage(w repository.Wallet) *walletpb.Wallet {
+	return &walletpb.Wallet{
+		Id:               w.ID.String(),
+		Balance:          w.Balance.String(),
+		HeldBalance:      w.HeldBalance.String(),
+		AvailableBalance: walletService.AvailableBalance(w).String(),
+		Currency:         w.Currency,
+		Status:           w.Status,
+		CreatedAt:        timestamppb.New(w.CreatedAt),
+		UpdatedAt:        timestamppb.New(w.UpdatedAt),
+		OwnerId:          deref(w.OwnerID),
+		ExternalRef:      deref(w.ExternalRef),
+	}
+}
+
+func holdMessage(h *walletService.Hold) *walletpb.Hold {
+	if h == nil {
+		return nil
+	}
+	msg := &walletpb.Hold{
+		Id:        h.ID.String(),
+		WalletId:  h.WalletID.String(),
+		Amount:    h.Amount.String(),
+		Status:    h.Status,
+		CreatedAt: timestamppb.New(h.CreatedAt),
+		ExpiresAt: timestamppb.New(h.ExpiresAt),
+	}
+	if h.CapturedAmount != nil {
+		captured := h.CapturedAmount.String()
+		msg.CapturedAmount = &captured
+	}
+	return msg
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func transactionMessage(t walletService.Transaction) *walletpb.Transaction {
+	return &walletpb.Transaction{
+		Id:                   t.ID.String(),
+		WalletId:             t.WalletID.String(),
+		Type:                 t.Type,
+		Amount:               t.Amount.String(),
+		BalanceBefore:        t.BalanceBefore.String(),
+		BalanceAfter:         t.BalanceAfter.String(),
+		CreatedAt:            timestamppb.New(t.CreatedAt),
+		CounterpartyWalletId: optionalID(t.CounterpartyWalletID),
+		HoldId:               optionalID(t.HoldID),
+		ReversalOf:           optionalID(t.ReversalOf),
+	}
+}
+
+func optionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
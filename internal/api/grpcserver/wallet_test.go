@@ -0,0 +1,171 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/walletpb"
+)
+
+func newClient(t *testing.T, wallet *mocks.MockWalletService, stream *mocks.MockStreamService, opts ...grpc.ServerOption) walletpb.WalletServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(opts...)
+	walletpb.RegisterWalletServiceServer(srv, newWalletServer(wallet, stream, zap.NewNop()))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return walletpb.NewWalletServiceClient(conn)
+}
+
+func TestGetBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	wallet := mocks.NewMockWalletService(ctrl)
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(100), HeldBalance: decimal.NewFromInt(30), Currency: "USD", Status: "ACTIVE"}
+	wallet.EXPECT().GetBalance(gomock.Any(), w.ID).Return(w, nil)
+
+	resp, err := newClient(t, wallet, mocks.NewMockStreamService(ctrl)).GetBalance(context.Background(), &walletpb.GetBalanceRequest{WalletId: w.ID.String()})
+
+	require.NoError(t, err)
+	assert.Equal(t, "100", resp.GetBalance())
+	assert.Equal(t, "70", resp.GetAvailableBalance())
+	assert.Equal(t, "USD", resp.GetCurrency())
+}
+
+func TestProcessOperation_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"not found", walletService.ErrWalletNotFound, codes.NotFound},
+		{"insufficient funds", walletService.ErrInsufficientFunds, codes.FailedPrecondition},
+		{"invalid operation", walletService.ErrInvalidOperation, codes.InvalidArgument},
+		{"hold not active", walletService.ErrHoldNotActive, codes.FailedPrecondition},
+		{"internal", assert.AnError, codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			wallet := mocks.NewMockWalletService(ctrl)
+			walletID := uuid.New()
+			op := walletService.Operation{WalletID: walletID, Type: walletService.OperationWithdraw, Amount: decimal.RequireFromString("10.5")}
+			wallet.EXPECT().ProcessOperation(gomock.Any(), op).Return(walletService.OperationResult{}, tt.err)
+
+			_, err := newClient(t, wallet, mocks.NewMockStreamService(ctrl)).ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{
+				WalletId:      walletID.String(),
+				OperationType: walletService.OperationWithdraw,
+				Amount:        "10.5",
+			})
+
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestProcessOperation_Idempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	wallet := mocks.NewMockWalletService(ctrl)
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(60)}
+	op := walletService.Operation{WalletID: w.ID, Type: walletService.OperationDeposit, Amount: decimal.NewFromInt(10)}
+	wallet.EXPECT().ProcessOperationIdempotent(gomock.Any(), "key-1", op).
+		Return(walletService.OperationResult{Wallet: w}, true, nil)
+
+	resp, err := newClient(t, wallet, mocks.NewMockStreamService(ctrl)).ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{
+		WalletId:       w.ID.String(),
+		OperationType:  walletService.OperationDeposit,
+		Amount:         "10",
+		IdempotencyKey: "key-1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "60", resp.GetWallet().GetBalance())
+	assert.Nil(t, resp.GetHold())
+}
+
+func TestProcessOperation_Capture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	wallet := mocks.NewMockWalletService(ctrl)
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(70)}
+	holdID := uuid.New()
+	captured := decimal.NewFromInt(30)
+	op := walletService.Operation{WalletID: w.ID, Type: walletService.OperationCapture, HoldID: &holdID}
+	wallet.EXPECT().ProcessOperation(gomock.Any(), op).Return(walletService.OperationResult{
+		Wallet: w,
+		Hold:   &walletService.Hold{ID: holdID, WalletID: w.ID, Amount: captured, CapturedAmount: &captured, Status: walletService.HoldStatusCaptured},
+	}, nil)
+
+	resp, err := newClient(t, wallet, mocks.NewMockStreamService(ctrl)).ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{
+		WalletId:      w.ID.String(),
+		OperationType: walletService.OperationCapture,
+		HoldId:        holdID.String(),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "70", resp.GetWallet().GetBalance())
+	assert.Equal(t, holdID.String(), resp.GetHold().GetId())
+	assert.Equal(t, walletService.HoldStatusCaptured, resp.GetHold().GetStatus())
+	assert.Equal(t, "30", resp.GetHold().GetCapturedAmount())
+}
+
+func TestProcessOperation_InvalidRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := newClient(t, mocks.NewMockWalletService(ctrl), mocks.NewMockStreamService(ctrl))
+
+	_, err := client.ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{WalletId: "nope", Amount: "1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{WalletId: uuid.NewString(), Amount: "-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ProcessOperation(context.Background(), &walletpb.ProcessOperationRequest{
+		WalletId: uuid.NewString(), OperationType: walletService.OperationVoid, HoldId: "nope",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stream := mocks.NewMockStreamService(ctrl)
+	walletID := uuid.New()
+	holdID := uuid.New()
+	events := make(chan walletService.Transaction, 1)
+	events <- walletService.Transaction{ID: uuid.New(), WalletID: walletID, Type: walletService.OperationHold, BalanceAfter: decimal.NewFromInt(5), HoldID: &holdID}
+	stream.EXPECT().Subscribe(gomock.Any(), walletID, "").Return((<-chan walletService.Transaction)(events), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := newClient(t, mocks.NewMockWalletService(ctrl), stream).StreamEvents(ctx, &walletpb.StreamEventsRequest{WalletId: walletID.String()})
+	require.NoError(t, err)
+
+	msg, err := client.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, walletService.OperationHold, msg.GetType())
+	assert.Equal(t, "5", msg.GetBalanceAfter())
+	assert.Equal(t, holdID.String(), msg.GetHoldId())
+	assert.Nil(t, msg.ReversalOf)
+
+	// Закрытие потока сервисом (остановка сервера) завершает вызов с Unavailable
+	close(events)
+	_, err = client.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
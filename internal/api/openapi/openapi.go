@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// spec - описание HTTP API, вшитое в бинарник. Сервируется как есть и используется
+// валидатором, поэтому расхождение спецификации и контроллеров ловится тестами.
+//
+//go:embed openapi.json
+var spec []byte
+
+// uuidFormat - любой UUID в каноническом виде, без ограничения на версию
+const uuidFormat = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+func init() {
+	// kin-openapi не проверяет format: uuid без явной регистрации
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(uuidFormat))
+}
+
+// Spec возвращает исходный документ OpenAPI
+func Spec() []byte {
+	return spec
+}
+
+// Load разбирает вшитую спецификацию и проверяет ее на корректность
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ServeSpec отдает спецификацию: GET /api/v1/openapi.json
+func ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// ServeDocs отдает страницу документации на Redoc, которая читает openapi.json
+func ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+var docsPage = []byte(`<!DOCTYPE html>
+<html>
+<head>
+  <title>Wallet API</title>
+  <meta charset="utf-8"/>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`)
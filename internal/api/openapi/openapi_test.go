@@ -0,0 +1,260 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"tryingMicro/OrderAccepter/internal/api/controllers/wallet"
+	"tryingMicro/OrderAccepter/internal/api/openapi"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// setupRouter собирает реальный контроллер кошельков за валидатором. Любой ответ,
+// не совпавший со спецификацией, попадает в logs.
+func setupRouter(t *testing.T, svc *mocks.MockWalletService) (*gin.Engine, *observer.ObservedLogs) {
+	t.Helper()
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	mw, err := openapi.Validator(doc, zap.New(core), openapi.WithRequestValidation(true), openapi.WithResponseValidation(true))
+	require.NoError(t, err)
+
+	r := gin.New()
+	api := r.Group("/api/v1", mw, problem.Middleware(zap.NewNop()))
+	ctrl := wallet.New(svc, zap.NewNop())
+	api.POST("/wallet/", ctrl.ProcessOperation)
+	api.POST("/wallets/", ctrl.CreateWallet)
+	api.GET("/wallets/:walletId", ctrl.GetBalance)
+	api.GET("/wallets/:walletId/transactions", ctrl.ListTransactions)
+	api.GET("/openapi.json", openapi.ServeSpec)
+	return r, logs
+}
+
+func do(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func assertNoDrift(t *testing.T, logs *observer.ObservedLogs) {
+	t.Helper()
+	for _, e := range logs.All() {
+		t.Errorf("%s: %v", e.Message, e.ContextMap())
+	}
+}
+
+func makeWallet() repository.Wallet {
+	return repository.Wallet{
+		ID:          uuid.New(),
+		Balance:     decimal.RequireFromString("100.50"),
+		HeldBalance: decimal.NewFromInt(20),
+		Currency:    "USD",
+		Status:      walletService.WalletStatusActive,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func TestLoad(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	assert.NotNil(t, doc.Paths.Find("/wallet/"))
+	assert.NotNil(t, doc.Paths.Find("/wallets/{walletId}"))
+}
+
+func TestServeSpec(t *testing.T) {
+	r, _ := setupRouter(t, mocks.NewMockWalletService(gomock.NewController(t)))
+
+	w := do(r, http.MethodGet, "/api/v1/openapi.json", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, json.Valid(w.Body.Bytes()))
+	assert.Equal(t, openapi.Spec(), w.Body.Bytes())
+}
+
+func TestContract_ProcessOperation(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	wl := makeWallet()
+	svc.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).Return(walletService.OperationResult{Wallet: wl}, nil)
+
+	w := do(r, http.MethodPost, "/api/v1/wallet/", `{"valletId":"`+wl.ID.String()+`","operationType":"DEPOSIT","amount":"10.5"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestContract_HoldOperation(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	wl := makeWallet()
+	hold := walletService.Hold{
+		ID:        uuid.New(),
+		WalletID:  wl.ID,
+		Amount:    decimal.NewFromInt(20),
+		Status:    walletService.HoldStatusActive,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	svc.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).
+		Return(walletService.OperationResult{Wallet: wl, Hold: &hold}, nil)
+
+	w := do(r, http.MethodPost, "/api/v1/wallet/", `{"valletId":"`+wl.ID.String()+`","operationType":"HOLD","amount":20}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestContract_CreateWallet(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	created := makeWallet()
+	owner, ref := "merchant-1", "order-42"
+	created.OwnerID, created.ExternalRef = &owner, &ref
+	svc.EXPECT().CreateWallet(gomock.Any(), walletService.NewWallet{Currency: "EUR", ExternalRef: ref}).Return(created, nil)
+
+	w := do(r, http.MethodPost, "/api/v1/wallets/", `{"currency":"EUR","externalRef":"order-42"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestContract_GetBalance(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	wl := makeWallet()
+	svc.EXPECT().GetBalance(gomock.Any(), wl.ID).Return(wl, nil)
+
+	w := do(r, http.MethodGet, "/api/v1/wallets/"+wl.ID.String(), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestContract_GetBalance_NotFound(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	id := uuid.New()
+	svc.EXPECT().GetBalance(gomock.Any(), id).Return(repository.Wallet{}, walletService.ErrWalletNotFound)
+
+	w := do(r, http.MethodGet, "/api/v1/wallets/"+id.String(), "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestContract_ListTransactions(t *testing.T) {
+	svc := mocks.NewMockWalletService(gomock.NewController(t))
+	r, logs := setupRouter(t, svc)
+	walletID, counterparty := uuid.New(), uuid.New()
+	rate := decimal.RequireFromString("0.92")
+	page := walletService.TransactionPage{
+		Transactions: []walletService.Transaction{{
+			ID:                   uuid.New(),
+			WalletID:             walletID,
+			Type:                 "CONVERT_OUT",
+			Amount:               decimal.NewFromInt(10),
+			BalanceBefore:        decimal.NewFromInt(100),
+			BalanceAfter:         decimal.NewFromInt(90),
+			CreatedAt:            time.Now(),
+			CounterpartyWalletID: &counterparty,
+			FxRate:               &rate,
+			ReversedAmount:       decimal.Zero,
+		}},
+		NextCursor: "abc",
+	}
+	svc.EXPECT().ListTransactions(gomock.Any(), walletID, gomock.Any()).Return(page, nil)
+
+	w := do(r, http.MethodGet, "/api/v1/wallets/"+walletID.String()+"/transactions?limit=10", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertNoDrift(t, logs)
+}
+
+func TestRequestValidation(t *testing.T) {
+	id := uuid.NewString()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		field  string
+	}{
+		{"missing wallet id", http.MethodPost, "/api/v1/wallet/", `{"operationType":"DEPOSIT","amount":"1"}`, "valletId"},
+		{"unknown operation", http.MethodPost, "/api/v1/wallet/", `{"valletId":"` + id + `","operationType":"STEAL","amount":"1"}`, "operationType"},
+		{"bad amount", http.MethodPost, "/api/v1/wallet/", `{"valletId":"` + id + `","operationType":"DEPOSIT","amount":"ten"}`, "amount"},
+		{"bad wallet id", http.MethodGet, "/api/v1/wallets/not-a-uuid", "", "walletId"},
+		{"limit out of range", http.MethodGet, "/api/v1/wallets/" + id + "/transactions?limit=1000", "", "limit"},
+		{"bad currency", http.MethodPost, "/api/v1/wallets/", `{"currency":"EURO"}`, "currency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Сервис не должен вызываться: запрос отсекается валидатором
+			r, _ := setupRouter(t, mocks.NewMockWalletService(gomock.NewController(t)))
+
+			w := do(r, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			var body problem.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, problem.CodeValidationFailed, body.Code)
+			require.Len(t, body.Errors, 1)
+			assert.Equal(t, tt.field, body.Errors[0].Field)
+		})
+	}
+}
+
+func TestResponseValidation_ReportsDrift(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	mw, err := openapi.Validator(doc, zap.New(core), openapi.WithResponseValidation(true))
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/api/v1/wallets/:walletId", mw, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("walletId")})
+	})
+
+	w := do(r, http.MethodGet, "/api/v1/wallets/"+uuid.NewString(), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, logs.FilterMessage("response does not match openapi spec").Len())
+}
+
+func TestValidator_Disabled(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	mw, err := openapi.Validator(doc, zap.NewNop(), openapi.WithRequestValidation(false))
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/api/v1/wallets/:walletId", mw, func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := do(r, http.MethodGet, "/api/v1/wallets/not-a-uuid", "")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
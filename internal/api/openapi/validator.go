@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+type Option func(*validator)
+
+// WithRequestValidation включает проверку запросов: невалидный запрос получает 400
+// VALIDATION_FAILED и не доходит до контроллера
+func WithRequestValidation(enabled bool) Option {
+	return func(v *validator) {
+		v.requests = enabled
+	}
+}
+
+// WithResponseValidation включает проверку ответов. Ответ уже отправлен клиенту,
+// поэтому расхождение со спецификацией только логируется
+func WithResponseValidation(enabled bool) Option {
+	return func(v *validator) {
+		v.responses = enabled
+	}
+}
+
+type validator struct {
+	router    routers.Router
+	log       logger.Logger
+	requests  bool
+	responses bool
+	options   *openapi3filter.Options
+}
+
+// Validator проверяет запросы и ответы по спецификации. Маршруты, которых нет
+// в спецификации, пропускаются без проверки.
+func Validator(doc *openapi3.T, log logger.Logger, opts ...Option) (gin.HandlerFunc, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{
+		router:   router,
+		log:      log,
+		requests: true,
+		options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			// Значения по умолчанию подставляет контроллер, запрос не меняем
+			SkipSettingDefaults: true,
+		},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v.handle, nil
+}
+
+func (v *validator) handle(c *gin.Context) {
+	if !v.requests && !v.responses {
+		c.Next()
+		return
+	}
+	route, pathParams, err := v.router.FindRoute(c.Request)
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    c.Request,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	}
+	if v.requests {
+		if err = openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			problem.Abort(c, requestProblem(err))
+			return
+		}
+	}
+	if !v.responses {
+		c.Next()
+		return
+	}
+
+	rec := &recorder{ResponseWriter: c.Writer}
+	c.Writer = rec
+	c.Next()
+
+	resp := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Status(),
+		Header:                 rec.Header(),
+		Options:                v.options,
+	}
+	resp.SetBodyBytes(rec.body.Bytes())
+	if err = openapi3filter.ValidateResponse(context.WithoutCancel(c.Request.Context()), resp); err != nil {
+		v.log.Error("response does not match openapi spec",
+			zap.String("method", c.Request.Method),
+			zap.String("path", route.Path),
+			zap.Int("status", rec.Status()),
+			zap.Error(err),
+		)
+	}
+}
+
+// requestProblem переводит ошибку kin-openapi в VALIDATION_FAILED с указанием поля
+func requestProblem(err error) *problem.Problem {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		p := problem.Validation()
+		p.Detail = "request does not match the API specification"
+		return p
+	}
+	field := "body"
+	if reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+	reason := reqErr.Reason
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		if ptr := schemaErr.JSONPointer(); len(ptr) > 0 && reqErr.Parameter == nil {
+			field = strings.Join(ptr, ".")
+		}
+		reason = schemaErr.Reason
+	} else if reason == "" && reqErr.Err != nil {
+		reason = reqErr.Err.Error()
+	}
+	return problem.InvalidField(field, reason)
+}
+
+// recorder копирует тело ответа для проверки, не задерживая его отправку
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
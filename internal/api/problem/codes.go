@@ -0,0 +1,139 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"tryingMicro/OrderAccepter/internal/auth"
+	apiKeyService "tryingMicro/OrderAccepter/internal/service/apikey"
+	fxService "tryingMicro/OrderAccepter/internal/service/fx"
+	streamService "tryingMicro/OrderAccepter/internal/service/stream"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+	webhookService "tryingMicro/OrderAccepter/internal/service/webhook"
+)
+
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInternal         = "INTERNAL_ERROR"
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodeForbidden        = "FORBIDDEN"
+	CodePayloadTooLarge  = "PAYLOAD_TOO_LARGE"
+
+	CodeWalletNotFound          = "WALLET_NOT_FOUND"
+	CodeWalletFrozen            = "WALLET_FROZEN"
+	CodeWalletClosed            = "WALLET_CLOSED"
+	CodeWalletNotEmpty          = "WALLET_NOT_EMPTY"
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	CodeInsufficientFunds       = "INSUFFICIENT_FUNDS"
+	CodeInvalidOperation        = "INVALID_OPERATION"
+	CodeInvalidAmount           = "INVALID_AMOUNT"
+	CodeSameWallet              = "SAME_WALLET"
+	CodeInvalidOwner            = "INVALID_OWNER"
+	CodeExternalRefExists       = "EXTERNAL_REF_EXISTS"
+	CodeInvalidExternalRef      = "INVALID_EXTERNAL_REF"
+
+	CodeUnsupportedCurrency = "UNSUPPORTED_CURRENCY"
+	CodeCurrencyMismatch    = "CURRENCY_MISMATCH"
+	CodeSameCurrency        = "SAME_CURRENCY"
+	CodeRateNotFound        = "RATE_NOT_FOUND"
+
+	CodeTransactionNotFound        = "TRANSACTION_NOT_FOUND"
+	CodeInvalidCursor              = "INVALID_CURSOR"
+	CodeInvalidFilter              = "INVALID_FILTER"
+	CodeTransactionNotReversible   = "TRANSACTION_NOT_REVERSIBLE"
+	CodeTransactionAlreadyReversed = "TRANSACTION_ALREADY_REVERSED"
+	CodeReversalExceedsBalance     = "REVERSAL_EXCEEDS_BALANCE"
+	CodeHoldNotFound               = "HOLD_NOT_FOUND"
+	CodeHoldNotActive              = "HOLD_NOT_ACTIVE"
+	CodeHoldIDRequired             = "HOLD_ID_REQUIRED"
+	CodeIdempotencyKeyReused       = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyConflict     = "IDEMPOTENCY_KEY_CONFLICT"
+
+	CodeInvalidAdjustment    = "INVALID_ADJUSTMENT"
+	CodeAdjustmentNotFound   = "ADJUSTMENT_NOT_FOUND"
+	CodeAdjustmentNotPending = "ADJUSTMENT_NOT_PENDING"
+	CodeSelfApproval         = "SELF_APPROVAL"
+
+	CodeApiKeyNotFound = "API_KEY_NOT_FOUND"
+	CodeApiKeyRevoked  = "API_KEY_REVOKED"
+	CodeInvalidApiKey  = "INVALID_API_KEY"
+
+	CodeInvalidRate = "INVALID_RATE"
+
+	CodeWebhookNotFound              = "WEBHOOK_NOT_FOUND"
+	CodeWebhookDeliveryNotFound      = "WEBHOOK_DELIVERY_NOT_FOUND"
+	CodeInvalidWebhook               = "INVALID_WEBHOOK"
+	CodeWebhookDestinationNotAllowed = "WEBHOOK_DESTINATION_NOT_ALLOWED"
+
+	CodeInvalidLastEventID = "INVALID_LAST_EVENT_ID"
+)
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// mappings сопоставляет ошибки сервиса с ответами. Detail берется из самой
+// сигнальной ошибки, поэтому обернутые причины наружу не попадают.
+var mappings = []mapping{
+	{walletService.ErrWalletNotFound, http.StatusNotFound, CodeWalletNotFound, "Wallet not found"},
+	{walletService.ErrWalletFrozen, http.StatusConflict, CodeWalletFrozen, "Wallet is frozen"},
+	{walletService.ErrWalletClosed, http.StatusConflict, CodeWalletClosed, "Wallet is closed"},
+	{walletService.ErrWalletNotEmpty, http.StatusConflict, CodeWalletNotEmpty, "Wallet is not empty"},
+	{walletService.ErrInvalidStatusTransition, http.StatusConflict, CodeInvalidStatusTransition, "Invalid status transition"},
+	{walletService.ErrInsufficientFunds, http.StatusBadRequest, CodeInsufficientFunds, "Insufficient funds"},
+	{walletService.ErrInvalidOperation, http.StatusBadRequest, CodeInvalidOperation, "Invalid operation"},
+	{walletService.ErrInvalidAmount, http.StatusBadRequest, CodeInvalidAmount, "Invalid amount"},
+	{walletService.ErrSameWallet, http.StatusBadRequest, CodeSameWallet, "Same wallet"},
+	{walletService.ErrInvalidOwner, http.StatusBadRequest, CodeInvalidOwner, "Invalid wallet owner"},
+	{walletService.ErrExternalRefExists, http.StatusConflict, CodeExternalRefExists, "External reference already exists"},
+	{walletService.ErrInvalidExternalRef, http.StatusBadRequest, CodeInvalidExternalRef, "Invalid external reference"},
+	{walletService.ErrUnsupportedCurrency, http.StatusBadRequest, CodeUnsupportedCurrency, "Unsupported currency"},
+	{walletService.ErrCurrencyMismatch, http.StatusBadRequest, CodeCurrencyMismatch, "Currency mismatch"},
+	{walletService.ErrSameCurrency, http.StatusBadRequest, CodeSameCurrency, "Same currency"},
+	{walletService.ErrRateNotFound, http.StatusNotFound, CodeRateNotFound, "Exchange rate not found"},
+	{walletService.ErrTransactionNotFound, http.StatusNotFound, CodeTransactionNotFound, "Transaction not found"},
+	{walletService.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor, "Invalid cursor"},
+	{walletService.ErrInvalidFilter, http.StatusBadRequest, CodeInvalidFilter, "Invalid filter"},
+	{walletService.ErrTransactionNotReversible, http.StatusBadRequest, CodeTransactionNotReversible, "Transaction not reversible"},
+	{walletService.ErrTransactionAlreadyReversed, http.StatusConflict, CodeTransactionAlreadyReversed, "Transaction already reversed"},
+	{walletService.ErrReversalExceedsBalance, http.StatusBadRequest, CodeReversalExceedsBalance, "Reversal exceeds balance"},
+	{walletService.ErrHoldNotFound, http.StatusNotFound, CodeHoldNotFound, "Hold not found"},
+	{walletService.ErrHoldNotActive, http.StatusConflict, CodeHoldNotActive, "Hold is not active"},
+	{walletService.ErrHoldIDRequired, http.StatusBadRequest, CodeHoldIDRequired, "Hold id required"},
+	{walletService.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency key reused"},
+	{walletService.ErrIdempotencyKeyConflict, http.StatusConflict, CodeIdempotencyKeyConflict, "Idempotency key conflict"},
+	{walletService.ErrInvalidAdjustment, http.StatusBadRequest, CodeInvalidAdjustment, "Invalid manual adjustment"},
+	{walletService.ErrAdjustmentNotFound, http.StatusNotFound, CodeAdjustmentNotFound, "Manual adjustment not found"},
+	{walletService.ErrAdjustmentNotPending, http.StatusConflict, CodeAdjustmentNotPending, "Manual adjustment already decided"},
+	{walletService.ErrSelfApproval, http.StatusForbidden, CodeSelfApproval, "Self-approval is not allowed"},
+	{apiKeyService.ErrKeyNotFound, http.StatusNotFound, CodeApiKeyNotFound, "API key not found"},
+	{apiKeyService.ErrKeyRevoked, http.StatusConflict, CodeApiKeyRevoked, "API key is revoked"},
+	{apiKeyService.ErrInvalidKey, http.StatusBadRequest, CodeInvalidApiKey, "Invalid API key parameters"},
+	{fxService.ErrInvalidRate, http.StatusBadRequest, CodeInvalidRate, "Invalid exchange rate"},
+	{fxService.ErrRateNotFound, http.StatusNotFound, CodeRateNotFound, "Exchange rate not found"},
+	// Запрещенный адрес приходит обернутым в ErrInvalidSubscription, поэтому проверяется раньше
+	{webhookService.ErrForbiddenDestination, http.StatusBadRequest, CodeWebhookDestinationNotAllowed, "Webhook destination not allowed"},
+	{webhookService.ErrInvalidSubscription, http.StatusBadRequest, CodeInvalidWebhook, "Invalid webhook subscription"},
+	{webhookService.ErrSubscriptionNotFound, http.StatusNotFound, CodeWebhookNotFound, "Webhook subscription not found"},
+	{webhookService.ErrDeliveryNotFound, http.StatusNotFound, CodeWebhookDeliveryNotFound, "Webhook delivery not found"},
+	{streamService.ErrWalletNotFound, http.StatusNotFound, CodeWalletNotFound, "Wallet not found"},
+	{streamService.ErrInvalidLastEventID, http.StatusBadRequest, CodeInvalidLastEventID, "Invalid Last-Event-ID"},
+	{auth.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthenticated, "Unauthenticated"},
+}
+
+// FromError превращает ошибку в Problem. Неизвестные ошибки становятся 500
+func FromError(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return New(m.status, m.code, m.title, m.err.Error())
+		}
+	}
+	return internal()
+}
@@ -0,0 +1,28 @@
+package problem
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+// Middleware превращает ошибку, переданную обработчиком через c.Error, в ответ
+// problem+json. Непредвиденные ошибки логируются и отдаются как 500 без подробностей.
+func Middleware(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		p := FromError(err)
+		if p.Code == CodeInternal {
+			log.Error("request failed",
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+				zap.Error(err),
+			)
+		}
+		Abort(c, p)
+	}
+}
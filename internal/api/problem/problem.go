@@ -0,0 +1,66 @@
+package problem
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType - тип ответа с ошибкой по RFC 7807
+const ContentType = "application/problem+json"
+
+// Problem - тело ответа с ошибкой. Code стабилен и предназначен для машин,
+// Detail - для людей и может меняться.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Code     string       `json:"code"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError описывает ошибку в конкретном поле запроса
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// New создает Problem с типом, производным от кода
+func New(status int, code, title, detail string) *Problem {
+	return &Problem{
+		Type:   typeURI(code),
+		Title:  title,
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// Problem реализует error, чтобы его можно было передать через c.Error
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Code + ": " + p.Detail
+	}
+	return p.Code
+}
+
+// Abort прерывает обработку запроса и отвечает p
+func Abort(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func typeURI(code string) string {
+	return "urn:problem-type:" + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+// internal - ответ на непредвиденные ошибки, причина остается только в логах
+func internal() *Problem {
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error", "internal server error")
+}
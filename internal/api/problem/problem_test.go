@@ -0,0 +1,118 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	walletService "tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(t *testing.T, handler gin.HandlerFunc, body string) (*httptest.ResponseRecorder, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.ErrorLevel)
+	r := gin.New()
+	r.Use(problem.Middleware(zap.New(core)))
+	r.POST("/wallets/:walletId", handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets/123", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec, logs
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+	return p
+}
+
+func TestMiddleware_ServiceError(t *testing.T) {
+	rec, logs := serve(t, func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("%w: wallet 123", walletService.ErrInsufficientFunds))
+	}, "")
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	p := decode(t, rec)
+	assert.Equal(t, problem.CodeInsufficientFunds, p.Code)
+	assert.Equal(t, "urn:problem-type:insufficient-funds", p.Type)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	// Обернутая причина наружу не попадает
+	assert.Equal(t, walletService.ErrInsufficientFunds.Error(), p.Detail)
+	assert.Equal(t, "/wallets/123", p.Instance)
+	assert.Zero(t, logs.Len())
+}
+
+func TestMiddleware_UnknownErrorIsHidden(t *testing.T) {
+	rec, logs := serve(t, func(c *gin.Context) {
+		_ = c.Error(errors.New("pq: connection refused to 10.0.0.1"))
+	}, "")
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	p := decode(t, rec)
+	assert.Equal(t, problem.CodeInternal, p.Code)
+	assert.NotContains(t, p.Detail, "10.0.0.1")
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestMiddleware_WrittenResponseIsKept(t *testing.T) {
+	rec, _ := serve(t, func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+		_ = c.Error(walletService.ErrWalletNotFound)
+	}, "")
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+type bindRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+	Limit    int    `json:"limit"`
+}
+
+func TestBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []problem.FieldError
+	}{
+		{"required", `{}`, []problem.FieldError{{Field: "currency", Reason: "is required"}}},
+		{"len", `{"currency":"EURO"}`, []problem.FieldError{{Field: "currency", Reason: "must be exactly 3 characters long"}}},
+		{"type", `{"currency":"EUR","limit":"ten"}`, []problem.FieldError{{Field: "limit", Reason: "must be int"}}},
+		{"syntax", `{`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, _ := serve(t, func(c *gin.Context) {
+				var req bindRequest
+				if err := c.ShouldBindJSON(&req); err != nil {
+					_ = c.Error(problem.Binding(err))
+				}
+			}, tt.body)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			p := decode(t, rec)
+			assert.Equal(t, problem.CodeValidationFailed, p.Code)
+			assert.Equal(t, tt.fields, p.Errors)
+			assert.NotContains(t, p.Detail, "Key:")
+		})
+	}
+}
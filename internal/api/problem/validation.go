@@ -0,0 +1,87 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// В ошибках валидации поля называются так же, как в запросе, а не как в структуре
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// Validation описывает ошибки полей запроса
+func Validation(fields ...FieldError) *Problem {
+	p := New(http.StatusBadRequest, CodeValidationFailed, "Validation failed", "request validation failed")
+	p.Errors = fields
+	return p
+}
+
+// InvalidField - ошибка в одном поле запроса: теле, параметре пути, query или заголовке
+func InvalidField(field, reason string) *Problem {
+	return Validation(FieldError{Field: field, Reason: reason})
+}
+
+// Binding превращает ошибку ShouldBind* в VALIDATION_FAILED, не раскрывая текст валидатора
+func Binding(err error) *Problem {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{Field: fe.Field(), Reason: reason(fe)})
+		}
+		return Validation(fields...)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return InvalidField(typeErr.Field, "must be "+typeErr.Type.String())
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		p := Validation()
+		p.Detail = "request body is not valid JSON"
+		return p
+	}
+	p := Validation()
+	p.Detail = "request could not be decoded"
+	return p
+}
+
+func reason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", fe.Param())
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return "failed " + fe.Tag() + " check"
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/service/apikey"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticator проверяет учетные данные запроса и возвращает клиента
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// Authenticators пробует способы аутентификации по очереди. Отказ одного
+// (auth.ErrUnauthenticated) передает токен следующему, прочие ошибки прерывают цепочку.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	errs := make([]error, 0, len(a))
+	for _, authenticator := range a {
+		p, err := authenticator.Authenticate(ctx, token)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, auth.ErrUnauthenticated) {
+			return auth.Principal{}, err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return auth.Principal{}, auth.ErrUnauthenticated
+	}
+	return auth.Principal{}, errors.Join(errs...)
+}
+
+// authenticate пускает дальше только запросы с действующим ключом и кладет
+// клиента в контекст запроса. В лог попадает только открытый префикс ключа.
+func authenticate(a Authenticator, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := credentials(c.Request)
+		if token == "" {
+			reject(c, log, token, errors.New("missing credentials"))
+			return
+		}
+		p, err := a.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrUnauthenticated) {
+				reject(c, log, token, err)
+				return
+			}
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// requireScope отвечает 403, если у клиента нет scope
+func requireScope(scope string, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := auth.FromContext(c.Request.Context())
+		if !ok || !p.HasScope(scope) {
+			log.Warn("access denied",
+				zap.String("principal", p.ID),
+				zap.String("scope", scope),
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+			)
+			_ = c.Error(problem.New(http.StatusForbidden, problem.CodeForbidden, "Forbidden", "missing scope "+scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireRole отвечает 403, если у клиента нет роли поддержки role или старшей
+func requireRole(role string, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := auth.FromContext(c.Request.Context())
+		if !ok || !p.HasRole(role) {
+			log.Warn("access denied",
+				zap.String("principal", p.ID),
+				zap.String("role", role),
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+			)
+			_ = c.Error(problem.New(http.StatusForbidden, problem.CodeForbidden, "Forbidden", "missing role "+role))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func reject(c *gin.Context, log logger.Logger, token string, reason error) {
+	log.Warn("authentication failed",
+		zap.String("keyPrefix", apikey.Prefix(token)),
+		zap.String("reason", reason.Error()),
+		zap.String("clientIp", c.ClientIP()),
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.Request.URL.Path),
+	)
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	_ = c.Error(auth.ErrUnauthenticated)
+	c.Abort()
+}
+
+// credentials берет ключ или JWT из Authorization: Bearer или X-API-Key
+func credentials(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get(apiKeyHeader))
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"tryingMicro/OrderAccepter/internal/api/controllers"
+	"tryingMicro/OrderAccepter/internal/api/problem"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service"
+	"tryingMicro/OrderAccepter/internal/service/health"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeAuthenticator знает ключи по значению токена
+type fakeAuthenticator map[string][]string
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, token string) (auth.Principal, error) {
+	scopes, ok := f[token]
+	if !ok {
+		return auth.Principal{}, fmt.Errorf("%w: unknown key", auth.ErrUnauthenticated)
+	}
+	return auth.Principal{ID: token, Scopes: scopes}, nil
+}
+
+var keys = fakeAuthenticator{
+	"wk_read_secret":     {auth.ScopeWalletRead},
+	"wk_write_secret":    {auth.ScopeWalletWrite},
+	"wk_admin_secret":    {auth.ScopeAdmin},
+	"wk_viewer_secret":   {auth.RoleViewer},
+	"wk_operator_secret": {auth.RoleOperator},
+}
+
+func newTestServer(t *testing.T, log *zap.Logger) (*gin.Engine, *mocks.MockWalletService) {
+	t.Helper()
+	wallet := mocks.NewMockWalletService(gomock.NewController(t))
+	engine := gin.New()
+	engine.Use(problem.Middleware(zap.NewNop()))
+	ctrls := controllers.NewControllers(&service.Services{Wallet: wallet, Health: health.New(nil, nil, zap.NewNop())}, zap.NewNop())
+	s := NewServer(engine, ctrls, keys, log).(*server)
+	s.setupRoutes()
+	return engine, wallet
+}
+
+func call(r *gin.Engine, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v[0])
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestRoutes_RequireCredentials(t *testing.T) {
+	r, _ := newTestServer(t, zap.NewNop())
+
+	rec := call(r, http.MethodGet, "/api/v1/wallets/"+uuid.NewString(), nil)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, rec.Body.String(), problem.CodeUnauthenticated)
+}
+
+func TestRoutes_PublicSpec(t *testing.T) {
+	r, _ := newTestServer(t, zap.NewNop())
+
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "/api/v1/openapi.json", nil).Code)
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "/api/v1/docs", nil).Code)
+	// Пробы балансировщика и оркестратора ходят без ключей
+	assert.Equal(t, http.StatusOK, call(r, http.MethodGet, "/healthz", nil).Code)
+}
+
+func TestRoutes_Scopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		code   int
+	}{
+		{"read key reads", "wk_read_secret", http.MethodGet, "/api/v1/wallets/{id}", http.StatusOK},
+		{"read key cannot write", "wk_read_secret", http.MethodPost, "/api/v1/wallets/", http.StatusForbidden},
+		{"write key cannot read", "wk_write_secret", http.MethodGet, "/api/v1/wallets/{id}", http.StatusForbidden},
+		{"write key cannot manage keys", "wk_write_secret", http.MethodPost, "/api/v1/admin/api-keys", http.StatusForbidden},
+		{"read key cannot manage webhooks", "wk_read_secret", http.MethodGet, "/api/v1/webhooks/", http.StatusForbidden},
+		{"admin key reads", "wk_admin_secret", http.MethodGet, "/api/v1/wallets/{id}", http.StatusOK},
+		// Дошли до контроллера: пустое тело не проходит валидацию
+		{"admin key manages keys", "wk_admin_secret", http.MethodPost, "/api/v1/admin/api-keys", http.StatusBadRequest},
+		{"write key manages webhooks", "wk_write_secret", http.MethodPost, "/api/v1/webhooks/", http.StatusBadRequest},
+		{"unknown key", "wk_other_secret", http.MethodGet, "/api/v1/wallets/{id}", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, wallet := newTestServer(t, zap.NewNop())
+			id := uuid.New()
+			wallet.EXPECT().GetBalance(gomock.Any(), id).Return(repository.Wallet{ID: id}, nil).AnyTimes()
+
+			rec := call(r, tt.method, strings.Replace(tt.path, "{id}", id.String(), 1), bearer(tt.token))
+
+			assert.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestRoutes_SupportRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		code   int
+	}{
+		{"viewer looks up wallet", "wk_viewer_secret", http.MethodGet, "/api/v1/admin/wallets/{id}", http.StatusOK},
+		{"viewer cannot adjust", "wk_viewer_secret", http.MethodPost, "/api/v1/admin/wallets/{id}/adjustments", http.StatusForbidden},
+		{"viewer cannot freeze", "wk_viewer_secret", http.MethodPost, "/api/v1/admin/wallets/{id}/freeze", http.StatusForbidden},
+		// Дошли до контроллера: пустое тело не проходит валидацию
+		{"operator adjusts", "wk_operator_secret", http.MethodPost, "/api/v1/admin/wallets/{id}/adjustments", http.StatusBadRequest},
+		{"operator freezes", "wk_operator_secret", http.MethodPost, "/api/v1/admin/wallets/{id}/freeze", http.StatusBadRequest},
+		{"operator reverses", "wk_operator_secret", http.MethodPost, "/api/v1/admin/transactions/not-a-uuid/reverse", http.StatusBadRequest},
+		{"viewer cannot reverse", "wk_viewer_secret", http.MethodPost, "/api/v1/admin/transactions/{id}/reverse", http.StatusForbidden},
+		{"write key cannot reverse", "wk_write_secret", http.MethodPost, "/api/v1/admin/transactions/{id}/reverse", http.StatusForbidden},
+		{"operator cannot close", "wk_operator_secret", http.MethodPost, "/api/v1/admin/wallets/{id}/close", http.StatusForbidden},
+		{"operator cannot manage keys", "wk_operator_secret", http.MethodGet, "/api/v1/admin/api-keys", http.StatusForbidden},
+		{"admin has every role", "wk_admin_secret", http.MethodGet, "/api/v1/admin/wallets/{id}", http.StatusOK},
+		{"write key is not support", "wk_write_secret", http.MethodGet, "/api/v1/admin/wallets/{id}", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, wallet := newTestServer(t, zap.NewNop())
+			id := uuid.New()
+			wallet.EXPECT().GetBalance(gomock.Any(), id).Return(repository.Wallet{ID: id}, nil).AnyTimes()
+
+			rec := call(r, tt.method, strings.Replace(tt.path, "{id}", id.String(), 1), bearer(tt.token))
+
+			assert.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAuthenticate_APIKeyHeader(t *testing.T) {
+	r, wallet := newTestServer(t, zap.NewNop())
+	id := uuid.New()
+	wallet.EXPECT().GetBalance(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (repository.Wallet, error) {
+			p, ok := auth.FromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "wk_read_secret", p.ID)
+			return repository.Wallet{ID: id}, nil
+		})
+
+	rec := call(r, http.MethodGet, "/api/v1/wallets/"+id.String(), http.Header{apiKeyHeader: {"wk_read_secret"}})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuthenticate_DoesNotLogSecrets(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	r, _ := newTestServer(t, zap.New(core))
+
+	call(r, http.MethodGet, "/api/v1/wallets/"+uuid.NewString(), bearer("wk_abc123_topsecretvalue"))
+
+	entries := logs.FilterMessage("authentication failed").All()
+	if assert.Len(t, entries, 1) {
+		fields := fmt.Sprint(entries[0].ContextMap())
+		assert.Contains(t, fields, "abc123")
+		assert.NotContains(t, fields, "topsecretvalue")
+	}
+}
+
+func TestAuthenticators_FallsThrough(t *testing.T) {
+	jwt := fakeAuthenticator{"eyJ.payload.sig": {auth.ScopeWalletRead}}
+	chain := Authenticators{keys, jwt}
+
+	p, err := chain.Authenticate(context.Background(), "eyJ.payload.sig")
+	assert.NoError(t, err)
+	assert.Equal(t, "eyJ.payload.sig", p.ID)
+
+	p, err = chain.Authenticate(context.Background(), "wk_admin_secret")
+	assert.NoError(t, err)
+	assert.True(t, p.HasScope(auth.ScopeAdmin))
+
+	_, err = chain.Authenticate(context.Background(), "nope")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestAuthenticators_StopsOnInternalError(t *testing.T) {
+	failing := authenticatorFunc(func(context.Context, string) (auth.Principal, error) {
+		return auth.Principal{}, errors.New("db is down")
+	})
+	chain := Authenticators{failing, keys}
+
+	_, err := chain.Authenticate(context.Background(), "wk_admin_secret")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+type authenticatorFunc func(ctx context.Context, token string) (auth.Principal, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	return f(ctx, token)
+}
@@ -6,6 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"tryingMicro/OrderAccepter/internal/api/controllers"
+	"tryingMicro/OrderAccepter/internal/api/openapi"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	"tryingMicro/OrderAccepter/package/logger"
 	"tryingMicro/OrderAccepter/util/config"
 )
 
@@ -15,16 +19,20 @@ type Server interface {
 }
 
 type server struct {
-	httpServer  *http.Server
-	controllers *controllers.Controllers
-	router      *gin.Engine
+	httpServer    *http.Server
+	controllers   *controllers.Controllers
+	router        *gin.Engine
+	authenticator Authenticator
+	log           logger.Logger
 }
 
-func NewServer(engine *gin.Engine, ctrl *controllers.Controllers) Server {
+func NewServer(engine *gin.Engine, ctrl *controllers.Controllers, authenticator Authenticator, log logger.Logger) Server {
 	return &server{
-		httpServer:  nil,
-		controllers: ctrl,
-		router:      engine,
+		httpServer:    nil,
+		controllers:   ctrl,
+		router:        engine,
+		authenticator: authenticator,
+		log:           log,
 	}
 }
 
@@ -41,16 +49,69 @@ func (s *server) Run(config config.Config) error {
 }
 
 func (s *server) setupRoutes() {
+	read := requireScope(auth.ScopeWalletRead, s.log)
+	write := requireScope(auth.ScopeWalletWrite, s.log)
+	admin := requireScope(auth.ScopeAdmin, s.log)
+	viewer := requireRole(auth.RoleViewer, s.log)
+	operator := requireRole(auth.RoleOperator, s.log)
+
+	s.router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	s.router.GET("/healthz", s.controllers.Health.Live)
+	s.router.GET("/readyz", s.controllers.Health.Ready)
+
 	api := s.router.Group("/api/v1")
 	{
-		wallet := api.Group("/wallet")
+		api.GET("/openapi.json", openapi.ServeSpec)
+		api.GET("/docs", openapi.ServeDocs)
+	}
+	secured := api.Group("", authenticate(s.authenticator, s.log))
+	{
+		wallet := secured.Group("/wallet")
+		{
+			wallet.POST("/", write, s.controllers.Wallet.ProcessOperation)
+		}
+		wallets := secured.Group("/wallets")
+		{
+			wallets.GET("/:walletId", read, s.controllers.Wallet.GetBalance)
+			wallets.GET("/:walletId/transactions", read, s.controllers.Wallet.ListTransactions)
+			wallets.GET("/:walletId/stream", read, s.controllers.Stream.WalletStream)
+			wallets.POST("/", write, s.controllers.Wallet.CreateWallet)
+		}
+		transfers := secured.Group("/transfers")
+		{
+			transfers.POST("/", write, s.controllers.Wallet.Transfer)
+		}
+		fxRates := secured.Group("/fx-rates")
+		{
+			fxRates.GET("/", read, s.controllers.Fx.GetRate)
+		}
+		secured.GET("/ws", read, s.controllers.WS.Wallets)
+		webhooks := secured.Group("/webhooks", write)
 		{
-			wallet.POST("/", s.controllers.Wallet.ProcessOperation)
+			webhooks.POST("/", s.controllers.Webhook.Register)
+			webhooks.GET("/", s.controllers.Webhook.List)
+			webhooks.DELETE("/:id", s.controllers.Webhook.Delete)
+			webhooks.GET("/:id/deliveries", s.controllers.Webhook.ListDeliveries)
+			webhooks.POST("/deliveries/:id/redeliver", s.controllers.Webhook.Redeliver)
 		}
-		wallets := api.Group("/wallets")
+		// В /admin пускаются все роли поддержки, остальное проверяется на маршрутах
+		adminGroup := secured.Group("/admin", viewer)
 		{
-			wallets.GET("/:walletId", s.controllers.Wallet.GetBalance)
-			wallets.POST("/", s.controllers.Wallet.CreateWallet)
+			adminGroup.GET("/wallets", s.controllers.Admin.FindWallets)
+			adminGroup.GET("/wallets/:walletId", s.controllers.Admin.GetWallet)
+			adminGroup.GET("/adjustments", s.controllers.Admin.ListAdjustments)
+			adminGroup.POST("/wallets/:walletId/freeze", operator, s.controllers.Wallet.FreezeWallet)
+			adminGroup.POST("/wallets/:walletId/unfreeze", operator, s.controllers.Wallet.UnfreezeWallet)
+			adminGroup.POST("/wallets/:walletId/adjustments", operator, s.controllers.Admin.RequestAdjustment)
+			adminGroup.POST("/adjustments/:id/approve", operator, s.controllers.Admin.ApproveAdjustment)
+			adminGroup.POST("/adjustments/:id/reject", operator, s.controllers.Admin.RejectAdjustment)
+			adminGroup.POST("/transactions/:id/reverse", operator, s.controllers.Wallet.ReverseTransaction)
+			adminGroup.POST("/wallets/:walletId/close", admin, s.controllers.Wallet.CloseWallet)
+			adminGroup.POST("/fx-rates", admin, s.controllers.Fx.UploadRates)
+			adminGroup.POST("/api-keys", admin, s.controllers.ApiKey.Issue)
+			adminGroup.GET("/api-keys", admin, s.controllers.ApiKey.List)
+			adminGroup.DELETE("/api-keys/:id", admin, s.controllers.ApiKey.Revoke)
+			adminGroup.POST("/api-keys/:id/rotate", admin, s.controllers.ApiKey.Rotate)
 		}
 	}
 }
@@ -12,10 +12,20 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	"tryingMicro/OrderAccepter/internal/api/audit"
 	"tryingMicro/OrderAccepter/internal/api/controllers"
+	"tryingMicro/OrderAccepter/internal/api/grpcserver"
+	"tryingMicro/OrderAccepter/internal/api/openapi"
+	"tryingMicro/OrderAccepter/internal/api/problem"
 	"tryingMicro/OrderAccepter/internal/api/server"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/auth/jwt"
+	"tryingMicro/OrderAccepter/internal/metrics"
 	"tryingMicro/OrderAccepter/internal/repository"
 	"tryingMicro/OrderAccepter/internal/service"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/internal/service/webhook"
+	"tryingMicro/OrderAccepter/internal/tracing"
 	"tryingMicro/OrderAccepter/package/logger"
 	"tryingMicro/OrderAccepter/util/config"
 )
@@ -34,6 +44,18 @@ func main() {
 
 	ctx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg)
+	if err != nil {
+		logger.Fatal("failed to set up tracing", zap.Error(err))
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	poolConfig, err := pgxpool.ParseConfig(cfg.DBURL())
 	if err != nil {
 		logger.Fatal("failed to parse db config", zap.Error(err))
@@ -42,6 +64,7 @@ func main() {
 	poolConfig.MinConns = 5
 	poolConfig.MaxConnLifetime = 30 * time.Minute
 	poolConfig.MaxConnIdleTime = 5 * time.Minute
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -54,15 +77,66 @@ func main() {
 	}
 	logger.Info("connected to database")
 
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(pool))
+
 	repo := repository.NewRepository(pool)
-	services := service.NewServices(repo, logger)
+	services := service.NewServices(repo, repository.NewNotifier(pool), pool, logger, cfg)
 
 	ctrls := controllers.NewControllers(services, logger)
 
+	// Воркеры работают без клиента API, поэтому их вызовы явно помечены внутренними
+	workersCtx, stopWorkers := context.WithCancel(auth.WithInternal(ctx))
+	defer stopWorkers()
+	go purgeIdempotencyKeys(workersCtx, services, logger)
+	go expireHolds(workersCtx, services, logger)
+
+	publisher, closePublisher, err := newPublisher(cfg)
+	if err != nil {
+		logger.Fatal("failed to create outbox publisher", zap.Error(err))
+	}
+	defer closePublisher()
+	// Вебхуки получают события через тот же релей: он ставит их в очередь доставки
+	publisher = outbox.MultiPublisher{publisher, services.Webhook}
+	go relayOutbox(workersCtx, services, publisher, cfg.OutboxRelayInterval, logger)
+	go deliverWebhooks(workersCtx, services, cfg.WebhookDeliveryInterval, logger)
+	go services.Stream.Run(workersCtx)
+
 	router := gin.Default()
-	srv := server.NewServer(router, ctrls)
+	doc, err := openapi.Load()
+	if err != nil {
+		logger.Fatal("invalid openapi spec", zap.Error(err))
+	}
+	validator, err := openapi.Validator(doc, logger,
+		openapi.WithRequestValidation(cfg.OpenAPIValidateRequests),
+		openapi.WithResponseValidation(cfg.OpenAPIValidateResponses),
+	)
+	if err != nil {
+		logger.Fatal("failed to build openapi validator", zap.Error(err))
+	}
+	// problem.Middleware стоит после валидатора, чтобы ответы с ошибками тоже сверялись со спецификацией.
+	// Трассировка, метрики и аудит снаружи всех: они видят и отклоненные валидатором запросы, и окончательный код ответа
+	router.Use(tracing.Middleware(), metrics.Middleware(), audit.Middleware(services.Audit), validator, problem.Middleware(logger))
+	authenticators := server.Authenticators{services.ApiKey}
+	if cfg.AuthJWKSPath != "" {
+		verifier, err := jwt.New(cfg.AuthJWKSPath, logger,
+			jwt.WithIssuer(cfg.AuthJWTIssuer),
+			jwt.WithAudience(cfg.AuthJWTAudience),
+			jwt.WithOwnerClaim(cfg.AuthJWTOwnerClaim),
+			jwt.WithLeeway(cfg.AuthJWTLeeway),
+		)
+		if err != nil {
+			logger.Fatal("failed to load jwks", zap.Error(err))
+		}
+		go func() {
+			if err := verifier.Run(workersCtx); err != nil {
+				logger.Error("jwks watcher stopped", zap.Error(err))
+			}
+		}()
+		authenticators = append(authenticators, verifier)
+	}
+	srv := server.NewServer(router, ctrls, authenticators, logger)
 
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 	go func() {
 		logger.Info("starting server", zap.String("addr", cfg.ServerAddr))
 		if err = srv.Run(cfg); err != nil {
@@ -70,6 +144,17 @@ func main() {
 		}
 	}()
 
+	var grpcSrv grpcserver.Server
+	if cfg.GRPCAddr != "" {
+		grpcSrv = grpcserver.NewServer(services, authenticators, logger)
+		go func() {
+			logger.Info("starting grpc server", zap.String("addr", cfg.GRPCAddr))
+			if err := grpcSrv.Run(cfg); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
 	osChan := make(chan os.Signal, 1)
 	signal.Notify(osChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
@@ -78,9 +163,120 @@ func main() {
 		logger.Fatal(fmt.Sprintf("server error: %s", err))
 	case sig := <-osChan:
 		logger.Info("shutting down", zap.String("signal", sig.String()))
+		// /readyz отвечает 503 сразу, а сервер еще принимает запросы, пока балансировщик выводит экземпляр
+		services.Health.Drain()
+		time.Sleep(cfg.ShutdownDrainDelay)
+		// Остановка воркеров закрывает SSE-потоки, иначе Shutdown ждал бы их вечно
+		stopWorkers()
 		if err = srv.Shutdown(ctx); err != nil {
 			logger.Fatal("shutdown error", zap.Error(err))
 		}
+		if grpcSrv != nil {
+			if err = grpcSrv.Shutdown(ctx); err != nil {
+				logger.Fatal("grpc shutdown error", zap.Error(err))
+			}
+		}
 		logger.Info("server stopped")
 	}
 }
+
+// purgeIdempotencyKeys периодически удаляет просроченные ключи идемпотентности
+func purgeIdempotencyKeys(ctx context.Context, services *service.Services, logger logger.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := services.Wallet.PurgeExpiredIdempotencyKeys(ctx)
+			if err == nil && n > 0 {
+				logger.Info("expired idempotency keys purged", zap.Int64("count", n))
+			}
+		}
+	}
+}
+
+// expireHolds раз в минуту снимает холды с истекшим TTL
+func expireHolds(ctx context.Context, services *service.Services, logger logger.Logger) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := services.Wallet.ExpireHolds(ctx)
+			if err == nil && n > 0 {
+				logger.Info("expired holds released", zap.Int("count", n))
+			}
+		}
+	}
+}
+
+// newPublisher выбирает реализацию outbox.Publisher по OUTBOX_PUBLISHER
+func newPublisher(cfg config.Config) (outbox.Publisher, func(), error) {
+	switch cfg.OutboxPublisher {
+	case "", "stdout":
+		return outbox.NewWriterPublisher(os.Stdout), func() {}, nil
+	case "file":
+		f, err := os.OpenFile(cfg.OutboxFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return outbox.NewWriterPublisher(f), func() { _ = f.Close() }, nil
+	case "http":
+		if cfg.OutboxWebhookURL == "" {
+			return nil, nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for http publisher")
+		}
+		return outbox.NewHTTPPublisher(cfg.OutboxWebhookURL, cfg.OutboxWebhookTimeout), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
+
+// relayOutbox отправляет накопленные события outbox, пока есть что отправлять
+func relayOutbox(ctx context.Context, services *service.Services, pub outbox.Publisher, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := services.Outbox.RelayPending(ctx, pub)
+				if err != nil || n < outbox.DefaultBatchSize {
+					break
+				}
+				logger.Debug("outbox batch relayed", zap.Int("count", n))
+			}
+		}
+	}
+}
+
+// deliverWebhooks отправляет подписчикам доставки, время которых пришло
+func deliverWebhooks(ctx context.Context, services *service.Services, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := services.Webhook.DeliverPending(ctx)
+				if err != nil || n < webhook.DefaultBatchSize {
+					break
+				}
+				logger.Debug("webhook batch delivered", zap.Int("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// claims - зарегистрированные claims RFC 7519 и остальные поля токена
+type claims struct {
+	Subject   string
+	Issuer    string
+	Name      string
+	Audience  audience
+	ExpiresAt *time.Time
+	NotBefore *time.Time
+	Scope     string
+	Scp       []string
+	Extra     map[string]any
+}
+
+func (c *claims) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Subject   string       `json:"sub"`
+		Issuer    string       `json:"iss"`
+		Name      string       `json:"name"`
+		Audience  audience     `json:"aud"`
+		ExpiresAt *numericDate `json:"exp"`
+		NotBefore *numericDate `json:"nbf"`
+		Scope     string       `json:"scope"`
+		Scp       audience     `json:"scp"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &c.Extra); err != nil {
+		return err
+	}
+	c.Subject, c.Issuer, c.Name = raw.Subject, raw.Issuer, raw.Name
+	c.Audience, c.Scope, c.Scp = raw.Audience, raw.Scope, raw.Scp
+	c.ExpiresAt, c.NotBefore = raw.ExpiresAt.time(), raw.NotBefore.time()
+	return nil
+}
+
+// scopes объединяет scope (строка через пробел, RFC 8693) и scp (массив)
+func (c claims) scopes() []string {
+	result := strings.Fields(c.Scope)
+	for _, s := range c.Scp {
+		for _, scope := range strings.Fields(s) {
+			if !slices.Contains(result, scope) {
+				result = append(result, scope)
+			}
+		}
+	}
+	return result
+}
+
+// audience принимает и строку, и массив строк, как aud в RFC 7519
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(v string) bool {
+	return slices.Contains(a, v)
+}
+
+// numericDate - секунды Unix, возможно дробные
+type numericDate float64
+
+func (d *numericDate) time() *time.Time {
+	if d == nil {
+		return nil
+	}
+	sec, frac := math.Modf(float64(*d))
+	t := time.Unix(int64(sec), int64(frac*1e9))
+	return &t
+}
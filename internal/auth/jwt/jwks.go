@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgHS256 = "HS256"
+)
+
+var ErrNoKeys = errors.New("jwks contains no usable keys")
+
+// jwk - ключ из JWKS (RFC 7517). Поля, которые нам не нужны, не разбираются
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// key - ключ проверки подписи: *rsa.PublicKey, *ecdsa.PublicKey или []byte для HS256
+type key struct {
+	kid string
+	alg string
+	pub any
+}
+
+type keySet struct {
+	keys []key
+}
+
+func loadKeySet(path string) (*keySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(data)
+}
+
+// parseKeySet разбирает JWKS. Ключи шифрования и неподдерживаемых типов
+// пропускаются; набор без единого ключа подписи считается ошибкой.
+func parseKeySet(data []byte) (*keySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	set := &keySet{}
+	for _, j := range doc.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.key()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", j.Kid, err)
+		}
+		if k.pub == nil {
+			continue
+		}
+		set.keys = append(set.keys, k)
+	}
+	if len(set.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return set, nil
+}
+
+func (j jwk) key() (key, error) {
+	k := key{kid: j.Kid}
+	var err error
+	switch j.Kty {
+	case "RSA":
+		k.alg = AlgRS256
+		k.pub, err = j.rsa()
+	case "EC":
+		if j.Crv != "P-256" {
+			return k, nil
+		}
+		k.alg = AlgES256
+		k.pub, err = j.ecdsa()
+	case "oct":
+		k.alg = AlgHS256
+		k.pub, err = decodeField("k", j.K)
+	default:
+		return k, nil
+	}
+	if err != nil {
+		return key{}, err
+	}
+	// alg в JWK необязателен, но если задан - должен совпадать с типом ключа
+	if j.Alg != "" && j.Alg != k.alg {
+		return key{}, nil
+	}
+	return k, nil
+}
+
+func (j jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := decodeField("n", j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeField("e", j.E)
+	if err != nil {
+		return nil, err
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func (j jwk) ecdsa() (*ecdsa.PublicKey, error) {
+	x, err := decodeField("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeField("y", j.Y)
+	if err != nil {
+		return nil, err
+	}
+	// Несжатая точка 0x04 || X || Y; ecdh проверяет, что она лежит на кривой
+	point := make([]byte, 0, 65)
+	point = append(point, 4)
+	point = append(point, leftPad(x, 32)...)
+	point = append(point, leftPad(y, 32)...)
+	if _, err = ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid ec point: %w", err)
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+func decodeField(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing %q", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q: %w", name, err)
+	}
+	return b, nil
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	defaultLeeway     = 30 * time.Second
+	defaultOwnerClaim = "owner_id"
+)
+
+// Verifier проверяет JWT, выпущенные шлюзом, по ключам из локального JWKS
+type Verifier interface {
+	// Authenticate проверяет подпись и claims токена. Любая причина отказа - auth.ErrUnauthenticated
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+	// Reload перечитывает JWKS. При ошибке остаются прежние ключи
+	Reload() error
+	// Run перечитывает JWKS при изменении файла до отмены ctx
+	Run(ctx context.Context) error
+}
+
+type verifier struct {
+	path   string
+	keys   atomic.Pointer[keySet]
+	logger logger.Logger
+
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	ownerClaim string
+}
+
+// New загружает JWKS из path. Без валидного набора ключей сервис не стартует
+func New(path string, log logger.Logger, opts ...Option) (Verifier, error) {
+	v := &verifier{
+		path:       path,
+		logger:     log,
+		leeway:     defaultLeeway,
+		ownerClaim: defaultOwnerClaim,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if err := v.Reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *verifier) Reload() error {
+	set, err := loadKeySet(v.path)
+	if err != nil {
+		return fmt.Errorf("load jwks %s: %w", v.path, err)
+	}
+	v.keys.Store(set)
+	v.logger.Info("jwks loaded", zap.String("path", v.path), zap.Int("keys", len(set.keys)))
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (v *verifier) Authenticate(_ context.Context, token string) (auth.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return auth.Principal{}, fmt.Errorf("%w: malformed token", auth.ErrUnauthenticated)
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: malformed token header", auth.ErrUnauthenticated)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: malformed signature", auth.ErrUnauthenticated)
+	}
+	if err = v.verifySignature(h, parts[0]+"."+parts[1], sig); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: %s", auth.ErrUnauthenticated, err)
+	}
+
+	var c claims
+	if err = decodeSegment(parts[1], &c); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: malformed claims", auth.ErrUnauthenticated)
+	}
+	if err = v.validate(c); err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: %s", auth.ErrUnauthenticated, err)
+	}
+	return v.principal(c), nil
+}
+
+// verifySignature ищет ключ по kid и alg заголовка. Алгоритм задает ключ, а не
+// токен: HS256-токен не пройдет проверку RSA-ключом как общим секретом.
+func (v *verifier) verifySignature(h header, signed string, sig []byte) error {
+	switch h.Alg {
+	case AlgRS256, AlgES256, AlgHS256:
+	default:
+		return fmt.Errorf("unsupported alg %q", h.Alg)
+	}
+	digest := sha256.Sum256([]byte(signed))
+	found := false
+	for _, k := range v.keys.Load().keys {
+		if k.alg != h.Alg || (h.Kid != "" && k.kid != h.Kid) {
+			continue
+		}
+		found = true
+		if verify(k, signed, digest[:], sig) {
+			return nil
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown key %q", h.Kid)
+	}
+	return errors.New("invalid signature")
+}
+
+func verify(k key, signed string, digest, sig []byte) bool {
+	switch pub := k.pub.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		// JWS хранит подпись ES256 как r || s по 32 байта, а не в DER
+		if len(sig) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	case []byte:
+		mac := hmac.New(sha256.New, pub)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	return false
+}
+
+func (v *verifier) validate(c claims) error {
+	now := time.Now()
+	if c.Subject == "" {
+		return errors.New("missing sub")
+	}
+	if c.ExpiresAt == nil {
+		return errors.New("missing exp")
+	}
+	if now.After(c.ExpiresAt.Add(v.leeway)) {
+		return errors.New("token expired")
+	}
+	if c.NotBefore != nil && now.Add(v.leeway).Before(*c.NotBefore) {
+		return errors.New("token not yet valid")
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("unexpected iss %q", c.Issuer)
+	}
+	if v.audience != "" && !c.Audience.contains(v.audience) {
+		return errors.New("unexpected aud")
+	}
+	return nil
+}
+
+// principal переносит claims в клиента. Неизвестные scope шлюза отбрасываются
+func (v *verifier) principal(c claims) auth.Principal {
+	p := auth.Principal{ID: c.Subject, Name: c.Name, Subject: auth.TokenSubject(c.Subject)}
+	if p.Name == "" {
+		p.Name = c.Subject
+	}
+	for _, scope := range c.scopes() {
+		if auth.ValidScope(scope) {
+			p.Scopes = append(p.Scopes, scope)
+		}
+	}
+	if owner, ok := c.Extra[v.ownerClaim].(string); ok {
+		p.OwnerID = owner
+	}
+	return p
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
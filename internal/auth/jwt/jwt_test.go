@@ -0,0 +1,215 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/auth/jwt"
+)
+
+var (
+	rsaKey, _  = rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _   = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, k *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+}
+
+func ecJWK(kid string, k *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32)))}
+}
+
+func octJWK(kid string, secret []byte) map[string]string {
+	return map[string]string{"kty": "oct", "kid": kid, "k": b64(secret)}
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func newVerifier(t *testing.T, opts ...jwt.Option) (jwt.Verifier, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey), octJWK("hs-1", hmacSecret))
+	v, err := jwt.New(path, zap.NewNop(), opts...)
+	require.NoError(t, err)
+	return v, path
+}
+
+// sign собирает JWS compact с подписью выбранным алгоритмом
+func sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	var err error
+	switch alg {
+	case jwt.AlgRS256:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	case jwt.AlgES256:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case jwt.AlgHS256:
+		mac := hmac.New(sha256.New, hmacSecret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	require.NoError(t, err)
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":      "svc-billing",
+		"iss":      "https://gateway",
+		"aud":      []string{"wallet"},
+		"exp":      time.Now().Add(time.Hour).Unix(),
+		"scope":    "wallet:read wallet:write payments:refund",
+		"owner_id": "merchant-1",
+	}
+}
+
+func TestAuthenticate_Algorithms(t *testing.T) {
+	v, _ := newVerifier(t, jwt.WithIssuer("https://gateway"), jwt.WithAudience("wallet"))
+
+	for alg, kid := range map[string]string{jwt.AlgRS256: "rsa-1", jwt.AlgES256: "ec-1", jwt.AlgHS256: "hs-1"} {
+		t.Run(alg, func(t *testing.T) {
+			p, err := v.Authenticate(context.Background(), sign(t, alg, kid, validClaims()))
+
+			require.NoError(t, err)
+			assert.Equal(t, "svc-billing", p.ID)
+			assert.Equal(t, "jwt:svc-billing", p.Subject)
+			assert.Equal(t, "merchant-1", p.OwnerID)
+			assert.Equal(t, []string{auth.ScopeWalletRead, auth.ScopeWalletWrite}, p.Scopes)
+		})
+	}
+}
+
+func TestAuthenticate_ScpClaimAndNoKid(t *testing.T) {
+	v, _ := newVerifier(t, jwt.WithOwnerClaim("tenant"))
+	claims := validClaims()
+	delete(claims, "scope")
+	claims["scp"] = []string{auth.ScopeAdmin}
+	claims["tenant"] = "merchant-7"
+
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.AlgES256, "", claims))
+
+	require.NoError(t, err)
+	assert.True(t, p.HasScope(auth.ScopeAdmin))
+	assert.Equal(t, "merchant-7", p.OwnerID)
+}
+
+func TestAuthenticate_Rejects(t *testing.T) {
+	v, _ := newVerifier(t, jwt.WithIssuer("https://gateway"), jwt.WithAudience("wallet"), jwt.WithLeeway(time.Second))
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+	valid := sign(t, jwt.AlgRS256, "rsa-1", validClaims())
+	parts := strings.Split(valid, ".")
+
+	cases := map[string]string{
+		"garbage":        "not-a-jwt",
+		"api key":        "wk_abc_secret",
+		"expired":        sign(t, jwt.AlgRS256, "rsa-1", with("exp", time.Now().Add(-time.Minute).Unix())),
+		"no exp":         sign(t, jwt.AlgRS256, "rsa-1", with("exp", nil)),
+		"no sub":         sign(t, jwt.AlgRS256, "rsa-1", with("sub", nil)),
+		"not yet valid":  sign(t, jwt.AlgRS256, "rsa-1", with("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong issuer":   sign(t, jwt.AlgRS256, "rsa-1", with("iss", "https://evil")),
+		"wrong audience": sign(t, jwt.AlgRS256, "rsa-1", with("aud", "other")),
+		"unknown kid":    sign(t, jwt.AlgRS256, "rsa-2", validClaims()),
+		"alg mismatch":   sign(t, jwt.AlgHS256, "rsa-1", validClaims()),
+		"alg none":       b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".",
+		"tampered":       parts[0] + "." + b64([]byte(`{"sub":"root","exp":9999999999,"scope":"admin"}`)) + "." + parts[2],
+	}
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Authenticate(context.Background(), token)
+			assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+		})
+	}
+}
+
+func TestNew_InvalidJWKS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	_, err := jwt.New(path, zap.NewNop())
+	assert.Error(t, err)
+
+	writeJWKS(t, path, map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"})
+	_, err = jwt.New(path, zap.NewNop())
+	assert.ErrorIs(t, err, jwt.ErrNoKeys)
+
+	writeJWKS(t, path, map[string]string{"kty": "EC", "kid": "bad", "crv": "P-256", "x": b64([]byte{1}), "y": b64([]byte{2})})
+	_, err = jwt.New(path, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestRun_ReloadsOnChange(t *testing.T) {
+	v, path := newVerifier(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = v.Run(ctx)
+	}()
+	// Даем наблюдателю подписаться на каталог
+	time.Sleep(50 * time.Millisecond)
+
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writeJWKS(t, path, rsaJWK("rsa-2", &rotated.PublicKey))
+
+	old := sign(t, jwt.AlgRS256, "rsa-1", validClaims())
+	assert.Eventually(t, func() bool {
+		_, err := v.Authenticate(context.Background(), old)
+		return err != nil
+	}, 3*time.Second, 20*time.Millisecond)
+
+	// Битый файл не сбрасывает уже загруженные ключи
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	time.Sleep(400 * time.Millisecond)
+	_, err = v.Authenticate(context.Background(), old)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+	assert.Contains(t, err.Error(), `unknown key "rsa-1"`)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,37 @@
+package jwt
+
+import "time"
+
+type Option func(*verifier)
+
+// WithIssuer требует совпадения iss. Пустая строка отключает проверку
+func WithIssuer(issuer string) Option {
+	return func(v *verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience требует, чтобы aud содержал audience. Пустая строка отключает проверку
+func WithAudience(audience string) Option {
+	return func(v *verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway задает допуск расхождения часов для exp и nbf. Ноль оставляет 30 секунд
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *verifier) {
+		if leeway > 0 {
+			v.leeway = leeway
+		}
+	}
+}
+
+// WithOwnerClaim задает claim, из которого берется владелец клиента
+func WithOwnerClaim(claim string) Option {
+	return func(v *verifier) {
+		if claim != "" {
+			v.ownerClaim = claim
+		}
+	}
+}
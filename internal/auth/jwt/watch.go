@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// Редакторы и kubernetes пишут файл в несколько событий, перечитываем один раз после паузы
+const reloadDebounce = 200 * time.Millisecond
+
+// Run следит за каталогом, а не за файлом: при атомарной замене через rename
+// наблюдение за самим файлом теряется вместе со старым inode.
+func (v *verifier) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	path := filepath.Clean(v.path)
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	reload := time.NewTimer(reloadDebounce)
+	reload.Stop()
+	defer reload.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if affects(ev, path) {
+				reload.Reset(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			v.logger.Warn("jwks watcher error", zap.Error(err))
+		case <-reload.C:
+			if err := v.Reload(); err != nil {
+				v.logger.Error("failed to reload jwks, keeping previous keys", zap.Error(err))
+			}
+		}
+	}
+}
+
+// affects отбирает события по нашему файлу. В ConfigMap kubernetes файл - симлинк
+// на каталог ..data, который подменяется целиком, поэтому его события тоже учитываются.
+func affects(ev fsnotify.Event, path string) bool {
+	if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+		return false
+	}
+	name := filepath.Clean(ev.Name)
+	return name == path || filepath.Base(name) == "..data"
+}
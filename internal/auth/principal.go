@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrUnauthenticated не уточняет причину для клиента: она попадает только в лог
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+const (
+	ScopeWalletRead  = "wallet:read"
+	ScopeWalletWrite = "wallet:write"
+	// ScopeAdmin дает доступ ко всем маршрутам
+	ScopeAdmin = "admin"
+
+	// Роли поддержки для /admin. Старшая роль включает младшие
+	RoleViewer   = "support:viewer"
+	RoleOperator = "support:operator"
+	RoleAdmin    = ScopeAdmin
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ValidScope сообщает, известен ли scope
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeWalletRead, ScopeWalletWrite, ScopeAdmin, RoleViewer, RoleOperator:
+		return true
+	}
+	return false
+}
+
+// Principal - аутентифицированный клиент API
+type Principal struct {
+	// ID - идентификатор ключа или субъекта токена
+	ID     string
+	Name   string
+	Scopes []string
+	// OwnerID - владелец, от имени которого действует клиент
+	OwnerID string
+	// Subject - человек за учетными данными, по нему разделяются обязанности.
+	// В отличие от ID не меняется при ротации ключа, см. KeySubject и TokenSubject
+	Subject string
+}
+
+// KeySubject - личность клиентского API-ключа по его имени. Имя переходит к ключу при
+// ротации, поэтому Subject ее переживает. Ключи персонала вместо имени используют StaffSubject.
+// Префикс отделяет ключи от субъектов JWT с тем же значением.
+func KeySubject(name string) string {
+	return "key:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+// StaffSubject - личность сотрудника, которому выдан ключ поддержки или admin
+func StaffSubject(staffID string) string {
+	return "staff:" + strings.ToLower(strings.TrimSpace(staffID))
+}
+
+// TokenSubject - личность субъекта JWT шлюза
+func TokenSubject(sub string) string {
+	return "jwt:" + sub
+}
+
+// HasScope сообщает, разрешен ли клиенту scope. admin включает все остальные
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// HasRole сообщает, есть ли у клиента роль role или старшая
+func (p Principal) HasRole(role string) bool {
+	need, ok := roleRank[role]
+	if !ok {
+		return false
+	}
+	for _, scope := range p.Scopes {
+		if roleRank[scope] >= need {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRead сообщает, может ли клиент читать кошелек владельца ownerID.
+// Поддержка видит любые кошельки, кошельки без владельца доступны только ей.
+func (p Principal) CanRead(ownerID *string) bool {
+	return p.HasRole(RoleViewer) || p.owns(ownerID)
+}
+
+// CanWrite сообщает, может ли клиент менять кошелек владельца ownerID.
+// Роли поддержки здесь не помогают: в чужой кошелек пишет только admin.
+func (p Principal) CanWrite(ownerID *string) bool {
+	return p.HasScope(ScopeAdmin) || p.owns(ownerID)
+}
+
+func (p Principal) owns(ownerID *string) bool {
+	return p.OwnerID != "" && ownerID != nil && *ownerID == p.OwnerID
+}
+
+type principalKey struct{}
+
+type internalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает клиента, которого аутентифицировал middleware
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Actor возвращает сотрудника, выполняющего действие из ctx. Это Subject, а не ID ключа:
+// иначе сотрудник подтвердил бы свою корректировку вторым или ротированным ключом.
+func Actor(ctx context.Context) (string, error) {
+	p, ok := FromContext(ctx)
+	if !ok || p.Subject == "" {
+		return "", ErrUnauthenticated
+	}
+	return p.Subject, nil
+}
+
+// WithInternal помечает вызов как внутренний (воркеры, служебные команды).
+// Только такие вызовы проходят проверки доступа без клиента в контексте.
+func WithInternal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+// IsInternal сообщает, помечен ли вызов через WithInternal
+func IsInternal(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalKey{}).(bool)
+	return internal
+}
+
+// CanRead сообщает, может ли вызов из ctx читать кошелек владельца ownerID.
+// Вызов без клиента и без пометки WithInternal запрещен.
+func CanRead(ctx context.Context, ownerID *string) bool {
+	if IsInternal(ctx) {
+		return true
+	}
+	p, ok := FromContext(ctx)
+	return ok && p.CanRead(ownerID)
+}
+
+// CanWrite сообщает, может ли вызов из ctx менять кошелек владельца ownerID
+func CanWrite(ctx context.Context, ownerID *string) bool {
+	if IsInternal(ctx) {
+		return true
+	}
+	p, ok := FromContext(ctx)
+	return ok && p.CanWrite(ownerID)
+}
@@ -0,0 +1,192 @@
+// Команда audit выгружает журнал аудита и проверяет его цепочку хешей.
+//
+//	audit verify                 проверить журнал в базе
+//	audit verify -file log.jsonl проверить выгрузку без доступа к базе
+//	audit verify -checkpoint cp.json
+//	                             дополнительно сверить с сохраненной головой
+//	audit checkpoint [-out cp.json]
+//	                             проверить журнал и выгрузить его голову
+//	audit export [-after N] [-out log.jsonl]
+//
+// Сама цепочка не защищает от того, у кого есть запись в базу: он может пересчитать
+// все хеши или отрезать хвост. Checkpoint, снятый регулярно и хранящийся вне базы,
+// это обнаруживает.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap/zapcore"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/util/config"
+)
+
+const usage = "usage: audit verify [-file log.jsonl] [-checkpoint cp.json] | audit checkpoint [-out cp.json] | audit export [-after N] [-out log.jsonl]"
+
+func main() {
+	if len(os.Args) < 2 {
+		fail(errors.New(usage))
+	}
+	ctx := context.Background()
+
+	var err error
+	switch os.Args[1] {
+	case "verify":
+		err = verify(ctx, os.Args[2:])
+	case "checkpoint":
+		err = checkpoint(ctx, os.Args[2:])
+	case "export":
+		err = export(ctx, os.Args[2:])
+	default:
+		err = errors.New(usage)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func verify(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	file := flags.String("file", "", "проверить выгрузку export вместо базы")
+	cpFile := flags.String("checkpoint", "", "файл audit checkpoint, с которым сверить журнал")
+	_ = flags.Parse(args)
+
+	cp, err := readCheckpoint(*cpFile)
+	if err != nil {
+		return err
+	}
+
+	var result audit.Verification
+	if *file != "" {
+		f, openErr := os.Open(*file)
+		if openErr != nil {
+			return openErr
+		}
+		defer f.Close()
+		result, err = audit.VerifyExport(f, cp)
+	} else {
+		svc, closeDB, dbErr := connect(ctx)
+		if dbErr != nil {
+			return dbErr
+		}
+		defer closeDB()
+		result, err = svc.Verify(ctx, cp)
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(result)
+	if err != nil {
+		return err
+	}
+	if !result.Complete {
+		fmt.Fprintln(os.Stderr, "warning: export does not start at the first entry, earlier entries were not checked")
+	}
+	return nil
+}
+
+func checkpoint(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	out := flags.String("out", "", "файл checkpoint; по умолчанию stdout")
+	_ = flags.Parse(args)
+
+	svc, closeDB, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	// Голову неверной цепочки сохранять нельзя: она узаконит подмену
+	result, err := svc.Verify(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if result.Entries == 0 {
+		return errors.New("audit log is empty, nothing to checkpoint")
+	}
+	data, err := json.Marshal(audit.Checkpoint{ID: result.LastID, Hash: result.Head})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o600)
+}
+
+// readCheckpoint читает файл audit checkpoint; пустой путь - сверки нет
+func readCheckpoint(path string) (*audit.Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp audit.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	if cp.ID <= 0 || cp.Hash == "" {
+		return nil, errors.New("read checkpoint: id and hash are required")
+	}
+	return &cp, nil
+}
+
+func export(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	after := flags.Int64("after", 0, "выгрузить записи с id больше этого")
+	out := flags.String("out", "", "файл выгрузки; по умолчанию stdout")
+	_ = flags.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	svc, closeDB, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	n, err := svc.Export(ctx, w, *after)
+	fmt.Fprintf(os.Stderr, "exported %d entries\n", n)
+	return err
+}
+
+// connect подключается к базе по тому же config.env, что и сервис
+func connect(ctx context.Context) (audit.AuditService, func(), error) {
+	cfg, err := config.InitConfig(".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	log, err := logger.NewLogger(zapcore.WarnLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	pool, err := pgxpool.New(ctx, cfg.DBURL())
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return audit.New(repository.NewRepository(pool), log), pool.Close, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
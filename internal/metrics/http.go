@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute - метка запросов мимо маршрутов: путь как есть раздул бы число рядов
+const unmatchedRoute = "unmatched"
+
+// Middleware считает запросы и их длительность по шаблону маршрута. Стоит первым,
+// чтобы учитывать и ответы, которые дописывают внутренние middleware.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
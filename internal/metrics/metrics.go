@@ -0,0 +1,93 @@
+// Package metrics - метрики Prometheus сервиса. Все коллекторы живут в своем
+// Registry, а не в глобальном: /metrics отдает только то, что объявлено здесь.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Результаты repository.WithTx
+const (
+	TxCommit      = "commit"
+	TxRollback    = "rollback"
+	TxCommitError = "commit_error"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route template, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	Operations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Wallet service mutations by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	LockWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "locker",
+		Name:      "wait_seconds",
+		Help:      "Time spent waiting for an in-process wallet lock.",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	})
+
+	LocksHeld = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "locker",
+		Name:      "locks_held",
+		Help:      "Wallet locks currently held.",
+	})
+
+	Transactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transactions_total",
+		Help:      "Database transactions opened by repository.WithTx by result.",
+	}, []string{"result"})
+
+	AuditSuppressed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "suppressed_total",
+		Help:      "Anonymous rejected requests not written to the audit log because of sampling.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		Operations,
+		LockWait,
+		LocksHeld,
+		Transactions,
+		AuditSuppressed,
+	)
+}
+
+// Handler отдает метрики Registry в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
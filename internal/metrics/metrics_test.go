@@ -0,0 +1,48 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tryingMicro/OrderAccepter/internal/metrics"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	r := gin.New()
+	r.Use(metrics.Middleware())
+	r.GET("/wallets/:walletId", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	ok := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/wallets/:walletId", "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	beforeOK, beforeUnmatched := testutil.ToFloat64(ok), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/wallets/a", "/wallets/b", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, beforeOK+2, testutil.ToFloat64(ok))
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched))
+}
+
+func TestHandler_ExposesRegistry(t *testing.T) {
+	metrics.Transactions.WithLabelValues(metrics.TxCommit).Inc()
+	metrics.LocksHeld.Set(0)
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `wallet_db_transactions_total{result="commit"}`)
+	assert.Contains(t, body, "wallet_locker_locks_held 0")
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -2,3 +2,5 @@ package mocks
 
 //go:generate mockgen -source=../../internal/repository/repository.go -destination=mock_repository.go -package=mocks
 //go:generate mockgen -source=../../internal/repository/querier.go -destination=mock_querier.go -package=mocks
+//go:generate mockgen -source=../../internal/service/wallet/wallet.go -destination=mock_wallet_service.go -package=mocks
+//go:generate mockgen -source=../../internal/service/stream/stream.go -destination=mock_stream_service.go -package=mocks
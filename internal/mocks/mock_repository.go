@@ -15,6 +15,7 @@ import (
 	repository "tryingMicro/OrderAccepter/internal/repository"
 
 	uuid "github.com/google/uuid"
+	decimal "github.com/shopspring/decimal"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,19 +43,393 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimDueWebhookDeliveries mocks base method.
+func (m *MockRepository) ClaimDueWebhookDeliveries(ctx context.Context, arg repository.ClaimDueWebhookDeliveriesParams) ([]repository.ClaimDueWebhookDeliveriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebhookDeliveries", ctx, arg)
+	ret0, _ := ret[0].([]repository.ClaimDueWebhookDeliveriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebhookDeliveries indicates an expected call of ClaimDueWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimDueWebhookDeliveries(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimDueWebhookDeliveries), ctx, arg)
+}
+
+// ClaimPendingWalletEvents mocks base method.
+func (m *MockRepository) ClaimPendingWalletEvents(ctx context.Context, arg repository.ClaimPendingWalletEventsParams) ([]repository.WalletEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingWalletEvents", ctx, arg)
+	ret0, _ := ret[0].([]repository.WalletEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingWalletEvents indicates an expected call of ClaimPendingWalletEvents.
+func (mr *MockRepositoryMockRecorder) ClaimPendingWalletEvents(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingWalletEvents", reflect.TypeOf((*MockRepository)(nil).ClaimPendingWalletEvents), ctx, arg)
+}
+
+// CreateApiKey mocks base method.
+func (m *MockRepository) CreateApiKey(ctx context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApiKey", ctx, arg)
+	ret0, _ := ret[0].(repository.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockRepositoryMockRecorder) CreateApiKey(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockRepository)(nil).CreateApiKey), ctx, arg)
+}
+
+// CreateAuditEntry mocks base method.
+func (m *MockRepository) CreateAuditEntry(ctx context.Context, arg repository.CreateAuditEntryParams) (repository.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", ctx, arg)
+	ret0, _ := ret[0].(repository.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry.
+func (mr *MockRepositoryMockRecorder) CreateAuditEntry(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*MockRepository)(nil).CreateAuditEntry), ctx, arg)
+}
+
+// CreateFxRate mocks base method.
+func (m *MockRepository) CreateFxRate(ctx context.Context, arg repository.CreateFxRateParams) (repository.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFxRate", ctx, arg)
+	ret0, _ := ret[0].(repository.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFxRate indicates an expected call of CreateFxRate.
+func (mr *MockRepositoryMockRecorder) CreateFxRate(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxRate", reflect.TypeOf((*MockRepository)(nil).CreateFxRate), ctx, arg)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockRepository) CreateIdempotencyKey(ctx context.Context, arg repository.CreateIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(repository.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CreateIdempotencyKey(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CreateIdempotencyKey), ctx, arg)
+}
+
+// CreateManualAdjustment mocks base method.
+func (m *MockRepository) CreateManualAdjustment(ctx context.Context, arg repository.CreateManualAdjustmentParams) (repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateManualAdjustment", ctx, arg)
+	ret0, _ := ret[0].(repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateManualAdjustment indicates an expected call of CreateManualAdjustment.
+func (mr *MockRepositoryMockRecorder) CreateManualAdjustment(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateManualAdjustment", reflect.TypeOf((*MockRepository)(nil).CreateManualAdjustment), ctx, arg)
+}
+
 // CreateWallet mocks base method.
-func (m *MockRepository) CreateWallet(ctx context.Context, id uuid.UUID) (repository.Wallet, error) {
+func (m *MockRepository) CreateWallet(ctx context.Context, arg repository.CreateWalletParams) (repository.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", ctx, id)
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, arg)
 	ret0, _ := ret[0].(repository.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockRepositoryMockRecorder) CreateWallet(ctx, id any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateWallet(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockRepository)(nil).CreateWallet), ctx, arg)
+}
+
+// CreateWalletEvent mocks base method.
+func (m *MockRepository) CreateWalletEvent(ctx context.Context, arg repository.CreateWalletEventParams) (repository.WalletEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletEvent", ctx, arg)
+	ret0, _ := ret[0].(repository.WalletEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWalletEvent indicates an expected call of CreateWalletEvent.
+func (mr *MockRepositoryMockRecorder) CreateWalletEvent(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletEvent", reflect.TypeOf((*MockRepository)(nil).CreateWalletEvent), ctx, arg)
+}
+
+// CreateWalletHold mocks base method.
+func (m *MockRepository) CreateWalletHold(ctx context.Context, arg repository.CreateWalletHoldParams) (repository.WalletHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletHold", ctx, arg)
+	ret0, _ := ret[0].(repository.WalletHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWalletHold indicates an expected call of CreateWalletHold.
+func (mr *MockRepositoryMockRecorder) CreateWalletHold(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletHold", reflect.TypeOf((*MockRepository)(nil).CreateWalletHold), ctx, arg)
+}
+
+// CreateWalletStatusChange mocks base method.
+func (m *MockRepository) CreateWalletStatusChange(ctx context.Context, arg repository.CreateWalletStatusChangeParams) (repository.WalletStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletStatusChange", ctx, arg)
+	ret0, _ := ret[0].(repository.WalletStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWalletStatusChange indicates an expected call of CreateWalletStatusChange.
+func (mr *MockRepositoryMockRecorder) CreateWalletStatusChange(ctx, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockRepository)(nil).CreateWallet), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletStatusChange", reflect.TypeOf((*MockRepository)(nil).CreateWalletStatusChange), ctx, arg)
+}
+
+// CreateWalletTransaction mocks base method.
+func (m *MockRepository) CreateWalletTransaction(ctx context.Context, arg repository.CreateWalletTransactionParams) (repository.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletTransaction", ctx, arg)
+	ret0, _ := ret[0].(repository.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWalletTransaction indicates an expected call of CreateWalletTransaction.
+func (mr *MockRepositoryMockRecorder) CreateWalletTransaction(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletTransaction", reflect.TypeOf((*MockRepository)(nil).CreateWalletTransaction), ctx, arg)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockRepository) CreateWebhookDelivery(ctx context.Context, arg repository.CreateWebhookDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) CreateWebhookDelivery(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).CreateWebhookDelivery), ctx, arg)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockRepository) CreateWebhookSubscription(ctx context.Context, arg repository.CreateWebhookSubscriptionParams) (repository.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, arg)
+	ret0, _ := ret[0].(repository.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) CreateWebhookSubscription(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).CreateWebhookSubscription), ctx, arg)
+}
+
+// DecideManualAdjustment mocks base method.
+func (m *MockRepository) DecideManualAdjustment(ctx context.Context, arg repository.DecideManualAdjustmentParams) (repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideManualAdjustment", ctx, arg)
+	ret0, _ := ret[0].(repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideManualAdjustment indicates an expected call of DecideManualAdjustment.
+func (mr *MockRepositoryMockRecorder) DecideManualAdjustment(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideManualAdjustment", reflect.TypeOf((*MockRepository)(nil).DecideManualAdjustment), ctx, arg)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredIdempotencyKeys), ctx)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockRepository) DeleteWebhookSubscription(ctx context.Context, arg repository.DeleteWebhookSubscriptionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteWebhookSubscription(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteWebhookSubscription), ctx, arg)
+}
+
+// GetApiKey mocks base method.
+func (m *MockRepository) GetApiKey(ctx context.Context, id uuid.UUID) (repository.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKey", ctx, id)
+	ret0, _ := ret[0].(repository.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKey indicates an expected call of GetApiKey.
+func (mr *MockRepositoryMockRecorder) GetApiKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKey", reflect.TypeOf((*MockRepository)(nil).GetApiKey), ctx, id)
+}
+
+// GetApiKeyByPrefix mocks base method.
+func (m *MockRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (repository.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(repository.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyByPrefix indicates an expected call of GetApiKeyByPrefix.
+func (mr *MockRepositoryMockRecorder) GetApiKeyByPrefix(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyByPrefix", reflect.TypeOf((*MockRepository)(nil).GetApiKeyByPrefix), ctx, prefix)
+}
+
+// GetAuditLogHead mocks base method.
+func (m *MockRepository) GetAuditLogHead(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogHead", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogHead indicates an expected call of GetAuditLogHead.
+func (mr *MockRepositoryMockRecorder) GetAuditLogHead(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogHead", reflect.TypeOf((*MockRepository)(nil).GetAuditLogHead), ctx)
+}
+
+// GetFxRateAt mocks base method.
+func (m *MockRepository) GetFxRateAt(ctx context.Context, arg repository.GetFxRateAtParams) (repository.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFxRateAt", ctx, arg)
+	ret0, _ := ret[0].(repository.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFxRateAt indicates an expected call of GetFxRateAt.
+func (mr *MockRepositoryMockRecorder) GetFxRateAt(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFxRateAt", reflect.TypeOf((*MockRepository)(nil).GetFxRateAt), ctx, arg)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockRepository) GetIdempotencyKey(ctx context.Context, arg repository.GetIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(repository.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) GetIdempotencyKey(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).GetIdempotencyKey), ctx, arg)
+}
+
+// GetManualAdjustment mocks base method.
+func (m *MockRepository) GetManualAdjustment(ctx context.Context, id uuid.UUID) (repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManualAdjustment", ctx, id)
+	ret0, _ := ret[0].(repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManualAdjustment indicates an expected call of GetManualAdjustment.
+func (mr *MockRepositoryMockRecorder) GetManualAdjustment(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManualAdjustment", reflect.TypeOf((*MockRepository)(nil).GetManualAdjustment), ctx, id)
+}
+
+// GetManualAdjustmentForUpdate mocks base method.
+func (m *MockRepository) GetManualAdjustmentForUpdate(ctx context.Context, id uuid.UUID) (repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManualAdjustmentForUpdate", ctx, id)
+	ret0, _ := ret[0].(repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManualAdjustmentForUpdate indicates an expected call of GetManualAdjustmentForUpdate.
+func (mr *MockRepositoryMockRecorder) GetManualAdjustmentForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManualAdjustmentForUpdate", reflect.TypeOf((*MockRepository)(nil).GetManualAdjustmentForUpdate), ctx, id)
+}
+
+// GetReversedAmount mocks base method.
+func (m *MockRepository) GetReversedAmount(ctx context.Context, reversalOf *uuid.UUID) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversedAmount", ctx, reversalOf)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversedAmount indicates an expected call of GetReversedAmount.
+func (mr *MockRepositoryMockRecorder) GetReversedAmount(ctx, reversalOf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversedAmount", reflect.TypeOf((*MockRepository)(nil).GetReversedAmount), ctx, reversalOf)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockRepository) GetSchemaVersion(ctx context.Context) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockRepositoryMockRecorder) GetSchemaVersion(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockRepository)(nil).GetSchemaVersion), ctx)
 }
 
 // GetWallet mocks base method.
@@ -72,6 +447,21 @@ func (mr *MockRepositoryMockRecorder) GetWallet(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockRepository)(nil).GetWallet), ctx, id)
 }
 
+// GetWalletByExternalRef mocks base method.
+func (m *MockRepository) GetWalletByExternalRef(ctx context.Context, arg repository.GetWalletByExternalRefParams) (repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByExternalRef", ctx, arg)
+	ret0, _ := ret[0].(repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByExternalRef indicates an expected call of GetWalletByExternalRef.
+func (mr *MockRepositoryMockRecorder) GetWalletByExternalRef(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByExternalRef", reflect.TypeOf((*MockRepository)(nil).GetWalletByExternalRef), ctx, arg)
+}
+
 // GetWalletForUpdate mocks base method.
 func (m *MockRepository) GetWalletForUpdate(ctx context.Context, id uuid.UUID) (repository.Wallet, error) {
 	m.ctrl.T.Helper()
@@ -87,6 +477,330 @@ func (mr *MockRepositoryMockRecorder) GetWalletForUpdate(ctx, id any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWalletForUpdate), ctx, id)
 }
 
+// GetWalletHoldForUpdate mocks base method.
+func (m *MockRepository) GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (repository.WalletHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletHoldForUpdate", ctx, id)
+	ret0, _ := ret[0].(repository.WalletHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletHoldForUpdate indicates an expected call of GetWalletHoldForUpdate.
+func (mr *MockRepositoryMockRecorder) GetWalletHoldForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletHoldForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWalletHoldForUpdate), ctx, id)
+}
+
+// GetWalletTransaction mocks base method.
+func (m *MockRepository) GetWalletTransaction(ctx context.Context, id uuid.UUID) (repository.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletTransaction", ctx, id)
+	ret0, _ := ret[0].(repository.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletTransaction indicates an expected call of GetWalletTransaction.
+func (mr *MockRepositoryMockRecorder) GetWalletTransaction(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransaction", reflect.TypeOf((*MockRepository)(nil).GetWalletTransaction), ctx, id)
+}
+
+// GetWalletTransactionForUpdate mocks base method.
+func (m *MockRepository) GetWalletTransactionForUpdate(ctx context.Context, id uuid.UUID) (repository.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletTransactionForUpdate", ctx, id)
+	ret0, _ := ret[0].(repository.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletTransactionForUpdate indicates an expected call of GetWalletTransactionForUpdate.
+func (mr *MockRepositoryMockRecorder) GetWalletTransactionForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransactionForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWalletTransactionForUpdate), ctx, id)
+}
+
+// ListApiKeys mocks base method.
+func (m *MockRepository) ListApiKeys(ctx context.Context) ([]repository.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApiKeys", ctx)
+	ret0, _ := ret[0].([]repository.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApiKeys indicates an expected call of ListApiKeys.
+func (mr *MockRepositoryMockRecorder) ListApiKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApiKeys", reflect.TypeOf((*MockRepository)(nil).ListApiKeys), ctx)
+}
+
+// ListAuditLog mocks base method.
+func (m *MockRepository) ListAuditLog(ctx context.Context, arg repository.ListAuditLogParams) ([]repository.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLog", ctx, arg)
+	ret0, _ := ret[0].([]repository.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLog indicates an expected call of ListAuditLog.
+func (mr *MockRepositoryMockRecorder) ListAuditLog(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLog", reflect.TypeOf((*MockRepository)(nil).ListAuditLog), ctx, arg)
+}
+
+// ListExpiredWalletHolds mocks base method.
+func (m *MockRepository) ListExpiredWalletHolds(ctx context.Context, limit int32) ([]repository.WalletHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredWalletHolds", ctx, limit)
+	ret0, _ := ret[0].([]repository.WalletHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredWalletHolds indicates an expected call of ListExpiredWalletHolds.
+func (mr *MockRepositoryMockRecorder) ListExpiredWalletHolds(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredWalletHolds", reflect.TypeOf((*MockRepository)(nil).ListExpiredWalletHolds), ctx, limit)
+}
+
+// ListManualAdjustments mocks base method.
+func (m *MockRepository) ListManualAdjustments(ctx context.Context, arg repository.ListManualAdjustmentsParams) ([]repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListManualAdjustments", ctx, arg)
+	ret0, _ := ret[0].([]repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListManualAdjustments indicates an expected call of ListManualAdjustments.
+func (mr *MockRepositoryMockRecorder) ListManualAdjustments(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListManualAdjustments", reflect.TypeOf((*MockRepository)(nil).ListManualAdjustments), ctx, arg)
+}
+
+// ListMatchingWebhookSubscriptions mocks base method.
+func (m *MockRepository) ListMatchingWebhookSubscriptions(ctx context.Context, arg repository.ListMatchingWebhookSubscriptionsParams) ([]repository.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMatchingWebhookSubscriptions", ctx, arg)
+	ret0, _ := ret[0].([]repository.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMatchingWebhookSubscriptions indicates an expected call of ListMatchingWebhookSubscriptions.
+func (mr *MockRepositoryMockRecorder) ListMatchingWebhookSubscriptions(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMatchingWebhookSubscriptions", reflect.TypeOf((*MockRepository)(nil).ListMatchingWebhookSubscriptions), ctx, arg)
+}
+
+// ListReversedAmounts mocks base method.
+func (m *MockRepository) ListReversedAmounts(ctx context.Context, transactionIds []uuid.UUID) ([]repository.ListReversedAmountsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReversedAmounts", ctx, transactionIds)
+	ret0, _ := ret[0].([]repository.ListReversedAmountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReversedAmounts indicates an expected call of ListReversedAmounts.
+func (mr *MockRepositoryMockRecorder) ListReversedAmounts(ctx, transactionIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReversedAmounts", reflect.TypeOf((*MockRepository)(nil).ListReversedAmounts), ctx, transactionIds)
+}
+
+// ListWalletTransactions mocks base method.
+func (m *MockRepository) ListWalletTransactions(ctx context.Context, arg repository.ListWalletTransactionsParams) ([]repository.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletTransactions", ctx, arg)
+	ret0, _ := ret[0].([]repository.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletTransactions indicates an expected call of ListWalletTransactions.
+func (mr *MockRepositoryMockRecorder) ListWalletTransactions(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletTransactions", reflect.TypeOf((*MockRepository)(nil).ListWalletTransactions), ctx, arg)
+}
+
+// ListWalletTransactionsAfter mocks base method.
+func (m *MockRepository) ListWalletTransactionsAfter(ctx context.Context, arg repository.ListWalletTransactionsAfterParams) ([]repository.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletTransactionsAfter", ctx, arg)
+	ret0, _ := ret[0].([]repository.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletTransactionsAfter indicates an expected call of ListWalletTransactionsAfter.
+func (mr *MockRepositoryMockRecorder) ListWalletTransactionsAfter(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletTransactionsAfter", reflect.TypeOf((*MockRepository)(nil).ListWalletTransactionsAfter), ctx, arg)
+}
+
+// ListWalletsByOwner mocks base method.
+func (m *MockRepository) ListWalletsByOwner(ctx context.Context, arg repository.ListWalletsByOwnerParams) ([]repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletsByOwner", ctx, arg)
+	ret0, _ := ret[0].([]repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletsByOwner indicates an expected call of ListWalletsByOwner.
+func (mr *MockRepositoryMockRecorder) ListWalletsByOwner(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletsByOwner", reflect.TypeOf((*MockRepository)(nil).ListWalletsByOwner), ctx, arg)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockRepository) ListWebhookDeliveries(ctx context.Context, arg repository.ListWebhookDeliveriesParams) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, arg)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ListWebhookDeliveries(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ListWebhookDeliveries), ctx, arg)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockRepository) ListWebhookSubscriptions(ctx context.Context, ownerID *string) ([]repository.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", ctx, ownerID)
+	ret0, _ := ret[0].([]repository.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockRepositoryMockRecorder) ListWebhookSubscriptions(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockRepository)(nil).ListWebhookSubscriptions), ctx, ownerID)
+}
+
+// LockAuditLog mocks base method.
+func (m *MockRepository) LockAuditLog(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAuditLog", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAuditLog indicates an expected call of LockAuditLog.
+func (mr *MockRepositoryMockRecorder) LockAuditLog(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAuditLog", reflect.TypeOf((*MockRepository)(nil).LockAuditLog), ctx)
+}
+
+// MarkWalletEventFailed mocks base method.
+func (m *MockRepository) MarkWalletEventFailed(ctx context.Context, arg repository.MarkWalletEventFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWalletEventFailed", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWalletEventFailed indicates an expected call of MarkWalletEventFailed.
+func (mr *MockRepositoryMockRecorder) MarkWalletEventFailed(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWalletEventFailed", reflect.TypeOf((*MockRepository)(nil).MarkWalletEventFailed), ctx, arg)
+}
+
+// MarkWalletEventPublished mocks base method.
+func (m *MockRepository) MarkWalletEventPublished(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWalletEventPublished", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWalletEventPublished indicates an expected call of MarkWalletEventPublished.
+func (mr *MockRepositoryMockRecorder) MarkWalletEventPublished(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWalletEventPublished", reflect.TypeOf((*MockRepository)(nil).MarkWalletEventPublished), ctx, id)
+}
+
+// MarkWebhookDeliveryDelivered mocks base method.
+func (m *MockRepository) MarkWebhookDeliveryDelivered(ctx context.Context, arg repository.MarkWebhookDeliveryDeliveredParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryDelivered", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDeliveryDelivered indicates an expected call of MarkWebhookDeliveryDelivered.
+func (mr *MockRepositoryMockRecorder) MarkWebhookDeliveryDelivered(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryDelivered", reflect.TypeOf((*MockRepository)(nil).MarkWebhookDeliveryDelivered), ctx, arg)
+}
+
+// MarkWebhookDeliveryFailed mocks base method.
+func (m *MockRepository) MarkWebhookDeliveryFailed(ctx context.Context, arg repository.MarkWebhookDeliveryFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryFailed", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDeliveryFailed indicates an expected call of MarkWebhookDeliveryFailed.
+func (mr *MockRepositoryMockRecorder) MarkWebhookDeliveryFailed(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryFailed", reflect.TypeOf((*MockRepository)(nil).MarkWebhookDeliveryFailed), ctx, arg)
+}
+
+// RedeliverWebhookDelivery mocks base method.
+func (m *MockRepository) RedeliverWebhookDelivery(ctx context.Context, arg repository.RedeliverWebhookDeliveryParams) (repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhookDelivery", ctx, arg)
+	ret0, _ := ret[0].(repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhookDelivery indicates an expected call of RedeliverWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) RedeliverWebhookDelivery(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).RedeliverWebhookDelivery), ctx, arg)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockRepository) RevokeApiKey(ctx context.Context, id uuid.UUID) (repository.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApiKey", ctx, id)
+	ret0, _ := ret[0].(repository.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockRepositoryMockRecorder) RevokeApiKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockRepository)(nil).RevokeApiKey), ctx, id)
+}
+
+// TouchApiKey mocks base method.
+func (m *MockRepository) TouchApiKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchApiKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchApiKey indicates an expected call of TouchApiKey.
+func (mr *MockRepositoryMockRecorder) TouchApiKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchApiKey", reflect.TypeOf((*MockRepository)(nil).TouchApiKey), ctx, id)
+}
+
 // UpdateWalletBalance mocks base method.
 func (m *MockRepository) UpdateWalletBalance(ctx context.Context, arg repository.UpdateWalletBalanceParams) (repository.Wallet, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +816,36 @@ func (mr *MockRepositoryMockRecorder) UpdateWalletBalance(ctx, arg any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletBalance", reflect.TypeOf((*MockRepository)(nil).UpdateWalletBalance), ctx, arg)
 }
 
+// UpdateWalletHoldStatus mocks base method.
+func (m *MockRepository) UpdateWalletHoldStatus(ctx context.Context, arg repository.UpdateWalletHoldStatusParams) (repository.WalletHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletHoldStatus", ctx, arg)
+	ret0, _ := ret[0].(repository.WalletHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWalletHoldStatus indicates an expected call of UpdateWalletHoldStatus.
+func (mr *MockRepositoryMockRecorder) UpdateWalletHoldStatus(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletHoldStatus", reflect.TypeOf((*MockRepository)(nil).UpdateWalletHoldStatus), ctx, arg)
+}
+
+// UpdateWalletStatus mocks base method.
+func (m *MockRepository) UpdateWalletStatus(ctx context.Context, arg repository.UpdateWalletStatusParams) (repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletStatus", ctx, arg)
+	ret0, _ := ret[0].(repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWalletStatus indicates an expected call of UpdateWalletStatus.
+func (mr *MockRepositoryMockRecorder) UpdateWalletStatus(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletStatus", reflect.TypeOf((*MockRepository)(nil).UpdateWalletStatus), ctx, arg)
+}
+
 // WithTx mocks base method.
 func (m *MockRepository) WithTx(ctx context.Context, fn func(repository.Querier) error) error {
 	m.ctrl.T.Helper()
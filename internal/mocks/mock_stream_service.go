@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../../internal/service/stream/stream.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../../internal/service/stream/stream.go -destination=mock_stream_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	wallet "tryingMicro/OrderAccepter/internal/service/wallet"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStreamService is a mock of StreamService interface.
+type MockStreamService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockStreamServiceMockRecorder is the mock recorder for MockStreamService.
+type MockStreamServiceMockRecorder struct {
+	mock *MockStreamService
+}
+
+// NewMockStreamService creates a new mock instance.
+func NewMockStreamService(ctrl *gomock.Controller) *MockStreamService {
+	mock := &MockStreamService{ctrl: ctrl}
+	mock.recorder = &MockStreamServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamService) EXPECT() *MockStreamServiceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockStreamService) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockStreamServiceMockRecorder) Run(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockStreamService)(nil).Run), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockStreamService) Subscribe(ctx context.Context, walletID uuid.UUID, lastEventID string) (<-chan wallet.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, walletID, lastEventID)
+	ret0, _ := ret[0].(<-chan wallet.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockStreamServiceMockRecorder) Subscribe(ctx, walletID, lastEventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockStreamService)(nil).Subscribe), ctx, walletID, lastEventID)
+}
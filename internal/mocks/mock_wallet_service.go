@@ -0,0 +1,301 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../../internal/service/wallet/wallet.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../../internal/service/wallet/wallet.go -destination=mock_wallet_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	repository "tryingMicro/OrderAccepter/internal/repository"
+	wallet "tryingMicro/OrderAccepter/internal/service/wallet"
+
+	uuid "github.com/google/uuid"
+	decimal "github.com/shopspring/decimal"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWalletService is a mock of WalletService interface.
+type MockWalletService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockWalletServiceMockRecorder is the mock recorder for MockWalletService.
+type MockWalletServiceMockRecorder struct {
+	mock *MockWalletService
+}
+
+// NewMockWalletService creates a new mock instance.
+func NewMockWalletService(ctrl *gomock.Controller) *MockWalletService {
+	mock := &MockWalletService{ctrl: ctrl}
+	mock.recorder = &MockWalletServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveAdjustment mocks base method.
+func (m *MockWalletService) ApproveAdjustment(ctx context.Context, id uuid.UUID, actor string) (wallet.AdjustmentResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAdjustment", ctx, id, actor)
+	ret0, _ := ret[0].(wallet.AdjustmentResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAdjustment indicates an expected call of ApproveAdjustment.
+func (mr *MockWalletServiceMockRecorder) ApproveAdjustment(ctx, id, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustment", reflect.TypeOf((*MockWalletService)(nil).ApproveAdjustment), ctx, id, actor)
+}
+
+// ChangeStatus mocks base method.
+func (m *MockWalletService) ChangeStatus(ctx context.Context, walletID uuid.UUID, change wallet.StatusChange) (repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, walletID, change)
+	ret0, _ := ret[0].(repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockWalletServiceMockRecorder) ChangeStatus(ctx, walletID, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockWalletService)(nil).ChangeStatus), ctx, walletID, change)
+}
+
+// Convert mocks base method.
+func (m *MockWalletService) Convert(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (wallet.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Convert", ctx, fromWalletID, toWalletID, amount)
+	ret0, _ := ret[0].(wallet.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockWalletServiceMockRecorder) Convert(ctx, fromWalletID, toWalletID, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockWalletService)(nil).Convert), ctx, fromWalletID, toWalletID, amount)
+}
+
+// CreateWallet mocks base method.
+func (m *MockWalletService) CreateWallet(ctx context.Context, params wallet.NewWallet) (repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, params)
+	ret0, _ := ret[0].(repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockWalletServiceMockRecorder) CreateWallet(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWalletService)(nil).CreateWallet), ctx, params)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockWalletService) ExpireHolds(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockWalletServiceMockRecorder) ExpireHolds(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockWalletService)(nil).ExpireHolds), ctx)
+}
+
+// FindWallets mocks base method.
+func (m *MockWalletService) FindWallets(ctx context.Context, lookup wallet.WalletLookup) ([]repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWallets", ctx, lookup)
+	ret0, _ := ret[0].([]repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWallets indicates an expected call of FindWallets.
+func (mr *MockWalletServiceMockRecorder) FindWallets(ctx, lookup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWallets", reflect.TypeOf((*MockWalletService)(nil).FindWallets), ctx, lookup)
+}
+
+// GetBalance mocks base method.
+func (m *MockWalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (repository.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
+	ret0, _ := ret[0].(repository.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletServiceMockRecorder) GetBalance(ctx, walletID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletService)(nil).GetBalance), ctx, walletID)
+}
+
+// GetTransaction mocks base method.
+func (m *MockWalletService) GetTransaction(ctx context.Context, transactionID uuid.UUID) (wallet.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, transactionID)
+	ret0, _ := ret[0].(wallet.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockWalletServiceMockRecorder) GetTransaction(ctx, transactionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockWalletService)(nil).GetTransaction), ctx, transactionID)
+}
+
+// ListAdjustments mocks base method.
+func (m *MockWalletService) ListAdjustments(ctx context.Context, filter wallet.AdjustmentFilter) ([]repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdjustments", ctx, filter)
+	ret0, _ := ret[0].([]repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdjustments indicates an expected call of ListAdjustments.
+func (mr *MockWalletServiceMockRecorder) ListAdjustments(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdjustments", reflect.TypeOf((*MockWalletService)(nil).ListAdjustments), ctx, filter)
+}
+
+// ListTransactions mocks base method.
+func (m *MockWalletService) ListTransactions(ctx context.Context, walletID uuid.UUID, filter wallet.TransactionFilter) (wallet.TransactionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].(wallet.TransactionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockWalletServiceMockRecorder) ListTransactions(ctx, walletID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockWalletService)(nil).ListTransactions), ctx, walletID, filter)
+}
+
+// ProcessOperation mocks base method.
+func (m *MockWalletService) ProcessOperation(ctx context.Context, op wallet.Operation) (wallet.OperationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessOperation", ctx, op)
+	ret0, _ := ret[0].(wallet.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessOperation indicates an expected call of ProcessOperation.
+func (mr *MockWalletServiceMockRecorder) ProcessOperation(ctx, op any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOperation", reflect.TypeOf((*MockWalletService)(nil).ProcessOperation), ctx, op)
+}
+
+// ProcessOperationIdempotent mocks base method.
+func (m *MockWalletService) ProcessOperationIdempotent(ctx context.Context, key string, op wallet.Operation) (wallet.OperationResult, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessOperationIdempotent", ctx, key, op)
+	ret0, _ := ret[0].(wallet.OperationResult)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ProcessOperationIdempotent indicates an expected call of ProcessOperationIdempotent.
+func (mr *MockWalletServiceMockRecorder) ProcessOperationIdempotent(ctx, key, op any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOperationIdempotent", reflect.TypeOf((*MockWalletService)(nil).ProcessOperationIdempotent), ctx, key, op)
+}
+
+// PurgeExpiredIdempotencyKeys mocks base method.
+func (m *MockWalletService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredIdempotencyKeys indicates an expected call of PurgeExpiredIdempotencyKeys.
+func (mr *MockWalletServiceMockRecorder) PurgeExpiredIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredIdempotencyKeys", reflect.TypeOf((*MockWalletService)(nil).PurgeExpiredIdempotencyKeys), ctx)
+}
+
+// RejectAdjustment mocks base method.
+func (m *MockWalletService) RejectAdjustment(ctx context.Context, id uuid.UUID, actor, reason string) (repository.ManualAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAdjustment", ctx, id, actor, reason)
+	ret0, _ := ret[0].(repository.ManualAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAdjustment indicates an expected call of RejectAdjustment.
+func (mr *MockWalletServiceMockRecorder) RejectAdjustment(ctx, id, actor, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAdjustment", reflect.TypeOf((*MockWalletService)(nil).RejectAdjustment), ctx, id, actor, reason)
+}
+
+// RequestAdjustment mocks base method.
+func (m *MockWalletService) RequestAdjustment(ctx context.Context, walletID uuid.UUID, r wallet.AdjustmentRequest) (wallet.AdjustmentResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestAdjustment", ctx, walletID, r)
+	ret0, _ := ret[0].(wallet.AdjustmentResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestAdjustment indicates an expected call of RequestAdjustment.
+func (mr *MockWalletServiceMockRecorder) RequestAdjustment(ctx, walletID, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAdjustment", reflect.TypeOf((*MockWalletService)(nil).RequestAdjustment), ctx, walletID, r)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockWalletService) ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) (wallet.ReversalResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransaction", ctx, transactionID, amount)
+	ret0, _ := ret[0].(wallet.ReversalResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockWalletServiceMockRecorder) ReverseTransaction(ctx, transactionID, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockWalletService)(nil).ReverseTransaction), ctx, transactionID, amount)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletService) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (wallet.TransferResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, fromWalletID, toWalletID, amount)
+	ret0, _ := ret[0].(wallet.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletServiceMockRecorder) Transfer(ctx, fromWalletID, toWalletID, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletService)(nil).Transfer), ctx, fromWalletID, toWalletID, amount)
+}
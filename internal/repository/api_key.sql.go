@@ -0,0 +1,184 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: api_key.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createApiKey = `-- name: CreateApiKey :one
+INSERT INTO api_keys (id, name, prefix, secret_hash, scopes, rotated_from, owner_id, staff_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+`
+
+type CreateApiKeyParams struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	SecretHash  []byte     `json:"secret_hash"`
+	Scopes      []string   `json:"scopes"`
+	RotatedFrom *uuid.UUID `json:"rotated_from"`
+	OwnerID     *string    `json:"owner_id"`
+	StaffID     *string    `json:"staff_id"`
+}
+
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createApiKey,
+		arg.ID,
+		arg.Name,
+		arg.Prefix,
+		arg.SecretHash,
+		arg.Scopes,
+		arg.RotatedFrom,
+		arg.OwnerID,
+		arg.StaffID,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.OwnerID,
+		&i.StaffID,
+	)
+	return i, err
+}
+
+const getApiKey = `-- name: GetApiKey :one
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+WHERE id = $1
+`
+
+func (q *Queries) GetApiKey(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getApiKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.OwnerID,
+		&i.StaffID,
+	)
+	return i, err
+}
+
+const getApiKeyByPrefix = `-- name: GetApiKeyByPrefix :one
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+WHERE prefix = $1
+`
+
+func (q *Queries) GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getApiKeyByPrefix, prefix)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.OwnerID,
+		&i.StaffID,
+	)
+	return i, err
+}
+
+const listApiKeys = `-- name: ListApiKeys :many
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListApiKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, listApiKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.SecretHash,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.RotatedFrom,
+			&i.OwnerID,
+			&i.StaffID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :one
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, NOW())
+WHERE id = $1
+RETURNING id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+`
+
+func (q *Queries) RevokeApiKey(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, revokeApiKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.OwnerID,
+		&i.StaffID,
+	)
+	return i, err
+}
+
+const touchApiKey = `-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+`
+
+// Отметка использования пишется не чаще раза в минуту, чтобы не нагружать каждый запрос
+func (q *Queries) TouchApiKey(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchApiKey, id)
+	return err
+}
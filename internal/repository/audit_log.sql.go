@@ -0,0 +1,136 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: audit_log.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAuditEntry = `-- name: CreateAuditEntry :one
+INSERT INTO audit_log (occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash
+`
+
+type CreateAuditEntryParams struct {
+	OccurredAt    time.Time  `json:"occurred_at"`
+	Actor         string     `json:"actor"`
+	Action        string     `json:"action"`
+	Route         string     `json:"route"`
+	WalletID      *uuid.UUID `json:"wallet_id"`
+	PayloadDigest string     `json:"payload_digest"`
+	Outcome       string     `json:"outcome"`
+	StatusCode    *int32     `json:"status_code"`
+	ClientIp      string     `json:"client_ip"`
+	PrevHash      string     `json:"prev_hash"`
+	Hash          string     `json:"hash"`
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditLog, error) {
+	row := q.db.QueryRow(ctx, createAuditEntry,
+		arg.OccurredAt,
+		arg.Actor,
+		arg.Action,
+		arg.Route,
+		arg.WalletID,
+		arg.PayloadDigest,
+		arg.Outcome,
+		arg.StatusCode,
+		arg.ClientIp,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.OccurredAt,
+		&i.Actor,
+		&i.Action,
+		&i.Route,
+		&i.WalletID,
+		&i.PayloadDigest,
+		&i.Outcome,
+		&i.StatusCode,
+		&i.ClientIp,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getAuditLogHead = `-- name: GetAuditLogHead :one
+SELECT hash
+FROM audit_log
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetAuditLogHead(ctx context.Context) (string, error) {
+	row := q.db.QueryRow(ctx, getAuditLogHead)
+	var hash string
+	err := row.Scan(&hash)
+	return hash, err
+}
+
+const listAuditLog = `-- name: ListAuditLog :many
+SELECT id, occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash
+FROM audit_log
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListAuditLogParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLog, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.OccurredAt,
+			&i.Actor,
+			&i.Action,
+			&i.Route,
+			&i.WalletID,
+			&i.PayloadDigest,
+			&i.Outcome,
+			&i.StatusCode,
+			&i.ClientIp,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAuditLog = `-- name: LockAuditLog :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_log'))
+`
+
+// Записи добавляются по одной под транзакционной блокировкой: иначе две записи
+// сослались бы на одну и ту же голову цепочки
+func (q *Queries) LockAuditLog(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockAuditLog)
+	return err
+}
@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: fx_rate.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const createFxRate = `-- name: CreateFxRate :one
+INSERT INTO fx_rates (id, base_currency, quote_currency, rate, effective_from)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (base_currency, quote_currency, effective_from) DO NOTHING
+RETURNING id, base_currency, quote_currency, rate, effective_from, created_at
+`
+
+type CreateFxRateParams struct {
+	ID            uuid.UUID       `json:"id"`
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+}
+
+// Опубликованный курс не меняется: конфликт возвращает пустой результат (pgx.ErrNoRows)
+func (q *Queries) CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error) {
+	row := q.db.QueryRow(ctx, createFxRate,
+		arg.ID,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.Rate,
+		arg.EffectiveFrom,
+	)
+	var i FxRate
+	err := row.Scan(
+		&i.ID,
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.EffectiveFrom,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFxRateAt = `-- name: GetFxRateAt :one
+SELECT id, base_currency, quote_currency, rate, effective_from, created_at
+FROM fx_rates
+WHERE base_currency = $1
+  AND quote_currency = $2
+  AND effective_from <= $3
+ORDER BY effective_from DESC
+LIMIT 1
+`
+
+type GetFxRateAtParams struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	At            time.Time `json:"at"`
+}
+
+func (q *Queries) GetFxRateAt(ctx context.Context, arg GetFxRateAtParams) (FxRate, error) {
+	row := q.db.QueryRow(ctx, getFxRateAt, arg.BaseCurrency, arg.QuoteCurrency, arg.At)
+	var i FxRate
+	err := row.Scan(
+		&i.ID,
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.EffectiveFrom,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: idempotency_key.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (caller, key, request_hash, response, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (caller, key) DO UPDATE
+    SET request_hash = EXCLUDED.request_hash,
+        response     = EXCLUDED.response,
+        created_at   = NOW(),
+        expires_at   = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING key, request_hash, response, created_at, expires_at, caller
+`
+
+type CreateIdempotencyKeyParams struct {
+	Caller      string    `json:"caller"`
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	Response    []byte    `json:"response"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Просроченный ключ перезаписывается, живой - нет (запрос вернет pgx.ErrNoRows)
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, createIdempotencyKey,
+		arg.Caller,
+		arg.Key,
+		arg.RequestHash,
+		arg.Response,
+		arg.ExpiresAt,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Caller,
+	)
+	return i, err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE
+FROM idempotency_keys
+WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, request_hash, response, created_at, expires_at, caller
+FROM idempotency_keys
+WHERE caller = $1
+  AND key = $2
+  AND expires_at > NOW()
+`
+
+type GetIdempotencyKeyParams struct {
+	Caller string `json:"caller"`
+	Key    string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Caller, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Caller,
+	)
+	return i, err
+}
@@ -0,0 +1,206 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: manual_adjustment.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const createManualAdjustment = `-- name: CreateManualAdjustment :one
+INSERT INTO manual_adjustments (id, wallet_id, direction, amount, reason, status, requested_by, transaction_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+`
+
+type CreateManualAdjustmentParams struct {
+	ID            uuid.UUID       `json:"id"`
+	WalletID      uuid.UUID       `json:"wallet_id"`
+	Direction     string          `json:"direction"`
+	Amount        decimal.Decimal `json:"amount"`
+	Reason        string          `json:"reason"`
+	Status        string          `json:"status"`
+	RequestedBy   string          `json:"requested_by"`
+	TransactionID *uuid.UUID      `json:"transaction_id"`
+}
+
+func (q *Queries) CreateManualAdjustment(ctx context.Context, arg CreateManualAdjustmentParams) (ManualAdjustment, error) {
+	row := q.db.QueryRow(ctx, createManualAdjustment,
+		arg.ID,
+		arg.WalletID,
+		arg.Direction,
+		arg.Amount,
+		arg.Reason,
+		arg.Status,
+		arg.RequestedBy,
+		arg.TransactionID,
+	)
+	var i ManualAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Direction,
+		&i.Amount,
+		&i.Reason,
+		&i.Status,
+		&i.RequestedBy,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.TransactionID,
+		&i.CreatedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const decideManualAdjustment = `-- name: DecideManualAdjustment :one
+UPDATE manual_adjustments
+SET status          = $1,
+    decided_by      = $2,
+    decision_reason = $3,
+    transaction_id  = $4,
+    decided_at      = NOW()
+WHERE id = $5
+  AND status = 'PENDING'
+RETURNING id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+`
+
+type DecideManualAdjustmentParams struct {
+	Status         string     `json:"status"`
+	DecidedBy      *string    `json:"decided_by"`
+	DecisionReason *string    `json:"decision_reason"`
+	TransactionID  *uuid.UUID `json:"transaction_id"`
+	ID             uuid.UUID  `json:"id"`
+}
+
+func (q *Queries) DecideManualAdjustment(ctx context.Context, arg DecideManualAdjustmentParams) (ManualAdjustment, error) {
+	row := q.db.QueryRow(ctx, decideManualAdjustment,
+		arg.Status,
+		arg.DecidedBy,
+		arg.DecisionReason,
+		arg.TransactionID,
+		arg.ID,
+	)
+	var i ManualAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Direction,
+		&i.Amount,
+		&i.Reason,
+		&i.Status,
+		&i.RequestedBy,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.TransactionID,
+		&i.CreatedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const getManualAdjustment = `-- name: GetManualAdjustment :one
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE id = $1
+`
+
+func (q *Queries) GetManualAdjustment(ctx context.Context, id uuid.UUID) (ManualAdjustment, error) {
+	row := q.db.QueryRow(ctx, getManualAdjustment, id)
+	var i ManualAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Direction,
+		&i.Amount,
+		&i.Reason,
+		&i.Status,
+		&i.RequestedBy,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.TransactionID,
+		&i.CreatedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const getManualAdjustmentForUpdate = `-- name: GetManualAdjustmentForUpdate :one
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) GetManualAdjustmentForUpdate(ctx context.Context, id uuid.UUID) (ManualAdjustment, error) {
+	row := q.db.QueryRow(ctx, getManualAdjustmentForUpdate, id)
+	var i ManualAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Direction,
+		&i.Amount,
+		&i.Reason,
+		&i.Status,
+		&i.RequestedBy,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.TransactionID,
+		&i.CreatedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const listManualAdjustments = `-- name: ListManualAdjustments :many
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE ($1::varchar IS NULL OR status = $1)
+  AND ($2::uuid IS NULL OR wallet_id = $2)
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListManualAdjustmentsParams struct {
+	Status   *string    `json:"status"`
+	WalletID *uuid.UUID `json:"wallet_id"`
+	RowLimit int32      `json:"row_limit"`
+}
+
+func (q *Queries) ListManualAdjustments(ctx context.Context, arg ListManualAdjustmentsParams) ([]ManualAdjustment, error) {
+	rows, err := q.db.Query(ctx, listManualAdjustments, arg.Status, arg.WalletID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ManualAdjustment{}
+	for rows.Next() {
+		var i ManualAdjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Direction,
+			&i.Amount,
+			&i.Reason,
+			&i.Status,
+			&i.RequestedBy,
+			&i.DecidedBy,
+			&i.DecisionReason,
+			&i.TransactionID,
+			&i.CreatedAt,
+			&i.DecidedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -8,11 +8,159 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+type ApiKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	SecretHash  []byte     `json:"secret_hash"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	RotatedFrom *uuid.UUID `json:"rotated_from"`
+	OwnerID     *string    `json:"owner_id"`
+	StaffID     *string    `json:"staff_id"`
+}
+
+type AuditLog struct {
+	ID            int64      `json:"id"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+	Actor         string     `json:"actor"`
+	Action        string     `json:"action"`
+	Route         string     `json:"route"`
+	WalletID      *uuid.UUID `json:"wallet_id"`
+	PayloadDigest string     `json:"payload_digest"`
+	Outcome       string     `json:"outcome"`
+	StatusCode    *int32     `json:"status_code"`
+	ClientIp      string     `json:"client_ip"`
+	PrevHash      string     `json:"prev_hash"`
+	Hash          string     `json:"hash"`
+}
+
+type FxRate struct {
+	ID            uuid.UUID       `json:"id"`
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	Response    []byte    `json:"response"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Caller      string    `json:"caller"`
+}
+
+type ManualAdjustment struct {
+	ID             uuid.UUID       `json:"id"`
+	WalletID       uuid.UUID       `json:"wallet_id"`
+	Direction      string          `json:"direction"`
+	Amount         decimal.Decimal `json:"amount"`
+	Reason         string          `json:"reason"`
+	Status         string          `json:"status"`
+	RequestedBy    string          `json:"requested_by"`
+	DecidedBy      *string         `json:"decided_by"`
+	DecisionReason *string         `json:"decision_reason"`
+	TransactionID  *uuid.UUID      `json:"transaction_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DecidedAt      *time.Time      `json:"decided_at"`
+}
+
+type SchemaMigration struct {
+	Version   int32     `json:"version"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
 type Wallet struct {
-	ID        uuid.UUID `json:"id"`
-	Balance   float64   `json:"balance"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uuid.UUID       `json:"id"`
+	Balance     decimal.Decimal `json:"balance"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Currency    string          `json:"currency"`
+	HeldBalance decimal.Decimal `json:"held_balance"`
+	Status      string          `json:"status"`
+	OwnerID     *string         `json:"owner_id"`
+	ExternalRef *string         `json:"external_ref"`
+}
+
+type WalletEvent struct {
+	ID            uuid.UUID  `json:"id"`
+	WalletID      uuid.UUID  `json:"wallet_id"`
+	EventType     string     `json:"event_type"`
+	Payload       []byte     `json:"payload"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PublishedAt   *time.Time `json:"published_at"`
+	Attempts      int32      `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+type WalletHold struct {
+	ID             uuid.UUID        `json:"id"`
+	WalletID       uuid.UUID        `json:"wallet_id"`
+	Amount         decimal.Decimal  `json:"amount"`
+	CapturedAmount *decimal.Decimal `json:"captured_amount"`
+	Status         string           `json:"status"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+}
+
+type WalletStatusChange struct {
+	ID         uuid.UUID `json:"id"`
+	WalletID   uuid.UUID `json:"wallet_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WalletTransaction struct {
+	ID                   uuid.UUID        `json:"id"`
+	WalletID             uuid.UUID        `json:"wallet_id"`
+	Type                 string           `json:"type"`
+	Amount               decimal.Decimal  `json:"amount"`
+	BalanceBefore        decimal.Decimal  `json:"balance_before"`
+	BalanceAfter         decimal.Decimal  `json:"balance_after"`
+	CreatedAt            time.Time        `json:"created_at"`
+	CounterpartyWalletID *uuid.UUID       `json:"counterparty_wallet_id"`
+	FxRate               *decimal.Decimal `json:"fx_rate"`
+	FxRemainder          *decimal.Decimal `json:"fx_remainder"`
+	HoldID               *uuid.UUID       `json:"hold_id"`
+	ReversalOf           *uuid.UUID       `json:"reversal_of"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      *string    `json:"last_error"`
+	LastStatusCode *int32     `json:"last_status_code"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	LockedUntil    *time.Time `json:"locked_until"`
+}
+
+type WebhookSubscription struct {
+	ID         uuid.UUID  `json:"id"`
+	Url        string     `json:"url"`
+	Secret     string     `json:"secret"`
+	WalletID   *uuid.UUID `json:"wallet_id"`
+	EventTypes []string   `json:"event_types"`
+	CreatedAt  time.Time  `json:"created_at"`
+	OwnerID    *string    `json:"owner_id"`
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Notifier слушает канал Postgres LISTEN/NOTIFY
+type Notifier interface {
+	// Listen блокируется до отмены ctx или обрыва соединения и вызывает fn на каждое уведомление
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
+}
+
+type notifier struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotifier(pool *pgxpool.Pool) Notifier {
+	return &notifier{pool: pool}
+}
+
+// Уведомления приходят только на то соединение, которое выполнило LISTEN, поэтому
+// соединение забирается из пула насовсем и закрывается по выходу.
+func (n *notifier) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	pooled, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(notification.Payload)
+	}
+}
@@ -8,13 +8,73 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type Querier interface {
-	CreateWallet(ctx context.Context, id uuid.UUID) (Wallet, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	ClaimPendingWalletEvents(ctx context.Context, arg ClaimPendingWalletEventsParams) ([]WalletEvent, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditLog, error)
+	// Опубликованный курс не меняется: конфликт возвращает пустой результат (pgx.ErrNoRows)
+	CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error)
+	// Просроченный ключ перезаписывается, живой - нет (запрос вернет pgx.ErrNoRows)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateManualAdjustment(ctx context.Context, arg CreateManualAdjustmentParams) (ManualAdjustment, error)
+	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
+	CreateWalletEvent(ctx context.Context, arg CreateWalletEventParams) (WalletEvent, error)
+	CreateWalletHold(ctx context.Context, arg CreateWalletHoldParams) (WalletHold, error)
+	CreateWalletStatusChange(ctx context.Context, arg CreateWalletStatusChangeParams) (WalletStatusChange, error)
+	CreateWalletTransaction(ctx context.Context, arg CreateWalletTransactionParams) (WalletTransaction, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DecideManualAdjustment(ctx context.Context, arg DecideManualAdjustmentParams) (ManualAdjustment, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
+	GetApiKey(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetAuditLogHead(ctx context.Context) (string, error)
+	GetFxRateAt(ctx context.Context, arg GetFxRateAtParams) (FxRate, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetManualAdjustment(ctx context.Context, id uuid.UUID) (ManualAdjustment, error)
+	GetManualAdjustmentForUpdate(ctx context.Context, id uuid.UUID) (ManualAdjustment, error)
+	GetReversedAmount(ctx context.Context, reversalOf *uuid.UUID) (decimal.Decimal, error)
+	// Последняя версия, до которой применены все миграции подряд. Пропуск в середине
+	// означает, что схема отстает, даже если более поздние версии записаны.
+	GetSchemaVersion(ctx context.Context) (int32, error)
 	GetWallet(ctx context.Context, id uuid.UUID) (Wallet, error)
+	GetWalletByExternalRef(ctx context.Context, arg GetWalletByExternalRefParams) (Wallet, error)
 	GetWalletForUpdate(ctx context.Context, id uuid.UUID) (Wallet, error)
+	GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (WalletHold, error)
+	GetWalletTransaction(ctx context.Context, id uuid.UUID) (WalletTransaction, error)
+	GetWalletTransactionForUpdate(ctx context.Context, id uuid.UUID) (WalletTransaction, error)
+	ListApiKeys(ctx context.Context) ([]ApiKey, error)
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
+	ListExpiredWalletHolds(ctx context.Context, limit int32) ([]WalletHold, error)
+	ListManualAdjustments(ctx context.Context, arg ListManualAdjustmentsParams) ([]ManualAdjustment, error)
+	// Подписка владельца получает события только его кошельков, подписка без владельца - всех
+	ListMatchingWebhookSubscriptions(ctx context.Context, arg ListMatchingWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	ListReversedAmounts(ctx context.Context, transactionIds []uuid.UUID) ([]ListReversedAmountsRow, error)
+	ListWalletTransactions(ctx context.Context, arg ListWalletTransactionsParams) ([]WalletTransaction, error)
+	ListWalletTransactionsAfter(ctx context.Context, arg ListWalletTransactionsAfterParams) ([]WalletTransaction, error)
+	ListWalletsByOwner(ctx context.Context, arg ListWalletsByOwnerParams) ([]Wallet, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// owner_id = NULL в фильтре - подписки всех владельцев
+	ListWebhookSubscriptions(ctx context.Context, ownerID *string) ([]WebhookSubscription, error)
+	// Записи добавляются по одной под транзакционной блокировкой: иначе две записи
+	// сослались бы на одну и ту же голову цепочки
+	LockAuditLog(ctx context.Context) error
+	MarkWalletEventFailed(ctx context.Context, arg MarkWalletEventFailedParams) error
+	MarkWalletEventPublished(ctx context.Context, id uuid.UUID) error
+	MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (WebhookDelivery, error)
+	RevokeApiKey(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	// Отметка использования пишется не чаще раза в минуту, чтобы не нагружать каждый запрос
+	TouchApiKey(ctx context.Context, id uuid.UUID) error
 	UpdateWalletBalance(ctx context.Context, arg UpdateWalletBalanceParams) (Wallet, error)
+	UpdateWalletHoldStatus(ctx context.Context, arg UpdateWalletHoldStatusParams) (WalletHold, error)
+	UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatusParams) (Wallet, error)
 }
 
 var _ Querier = (*Queries)(nil)
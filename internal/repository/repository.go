@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	"tryingMicro/OrderAccepter/internal/tracing"
 )
 
 type Repository interface {
@@ -21,15 +23,24 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 	}
 }
 
-// Обертка для транзакций
-func (r *repository) WithTx(ctx context.Context, fn func(q Querier) error) error {
+// Обертка для транзакций. Спан db.tx покрывает транзакцию от BEGIN до COMMIT/ROLLBACK
+func (r *repository) WithTx(ctx context.Context, fn func(q Querier) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.tx")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 	if err = fn(New(tx)); err != nil {
+		metrics.Transactions.WithLabelValues(metrics.TxRollback).Inc()
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		metrics.Transactions.WithLabelValues(metrics.TxCommitError).Inc()
 		return err
 	}
-	return tx.Commit(ctx)
+	metrics.Transactions.WithLabelValues(metrics.TxCommit).Inc()
+	return nil
 }
@@ -0,0 +1,28 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: schema_migration.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const getSchemaVersion = `-- name: GetSchemaVersion :one
+SELECT COALESCE((
+    SELECT MIN(s.version)
+    FROM schema_migrations s
+    WHERE NOT EXISTS (SELECT 1 FROM schema_migrations n WHERE n.version = s.version + 1)
+      AND EXISTS (SELECT 1 FROM schema_migrations f WHERE f.version = 1)
+), 0)::INT AS version
+`
+
+// Последняя версия, до которой применены все миграции подряд. Пропуск в середине
+// означает, что схема отстает, даже если более поздние версии записаны.
+func (q *Queries) GetSchemaVersion(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, getSchemaVersion)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
@@ -9,28 +9,47 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 const createWallet = `-- name: CreateWallet :one
-INSERT INTO wallets (id, balance)
-VALUES ($1, 0)
-RETURNING id, balance, created_at, updated_at
+INSERT INTO wallets (id, balance, currency, owner_id, external_ref)
+VALUES ($1, 0, $2, $3, $4)
+ON CONFLICT (owner_id, external_ref) WHERE external_ref IS NOT NULL DO NOTHING
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 `
 
-func (q *Queries) CreateWallet(ctx context.Context, id uuid.UUID) (Wallet, error) {
-	row := q.db.QueryRow(ctx, createWallet, id)
+type CreateWalletParams struct {
+	ID          uuid.UUID `json:"id"`
+	Currency    string    `json:"currency"`
+	OwnerID     *string   `json:"owner_id"`
+	ExternalRef *string   `json:"external_ref"`
+}
+
+func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, createWallet,
+		arg.ID,
+		arg.Currency,
+		arg.OwnerID,
+		arg.ExternalRef,
+	)
 	var i Wallet
 	err := row.Scan(
 		&i.ID,
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
 	)
 	return i, err
 }
 
 const getWallet = `-- name: GetWallet :one
-SELECT id, balance, created_at, updated_at
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 FROM wallets
 WHERE id = $1
 `
@@ -43,12 +62,46 @@ func (q *Queries) GetWallet(ctx context.Context, id uuid.UUID) (Wallet, error) {
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
+	)
+	return i, err
+}
+
+const getWalletByExternalRef = `-- name: GetWalletByExternalRef :one
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
+FROM wallets
+WHERE owner_id = $1
+  AND external_ref = $2
+`
+
+type GetWalletByExternalRefParams struct {
+	OwnerID     *string `json:"owner_id"`
+	ExternalRef *string `json:"external_ref"`
+}
+
+func (q *Queries) GetWalletByExternalRef(ctx context.Context, arg GetWalletByExternalRefParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, getWalletByExternalRef, arg.OwnerID, arg.ExternalRef)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
 	)
 	return i, err
 }
 
 const getWalletForUpdate = `-- name: GetWalletForUpdate :one
-SELECT id, balance, created_at, updated_at
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 FROM wallets
 WHERE id = $1
     FOR UPDATE
@@ -62,31 +115,116 @@ func (q *Queries) GetWalletForUpdate(ctx context.Context, id uuid.UUID) (Wallet,
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
 	)
 	return i, err
 }
 
+const listWalletsByOwner = `-- name: ListWalletsByOwner :many
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
+FROM wallets
+WHERE owner_id = $1
+ORDER BY created_at, id
+LIMIT $2
+`
+
+type ListWalletsByOwnerParams struct {
+	OwnerID *string `json:"owner_id"`
+	Limit   int32   `json:"limit"`
+}
+
+func (q *Queries) ListWalletsByOwner(ctx context.Context, arg ListWalletsByOwnerParams) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listWalletsByOwner, arg.OwnerID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Wallet{}
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.ID,
+			&i.Balance,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.HeldBalance,
+			&i.Status,
+			&i.OwnerID,
+			&i.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateWalletBalance = `-- name: UpdateWalletBalance :one
 UPDATE wallets
-SET balance   = $1,
-    updated_at = NOW()
-WHERE id = $2
-RETURNING id, balance, created_at, updated_at
+SET balance      = $1,
+    held_balance = held_balance + $2,
+    updated_at   = NOW()
+WHERE id = $3
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 `
 
 type UpdateWalletBalanceParams struct {
-	Balance float64   `json:"balance"`
-	ID      uuid.UUID `json:"id"`
+	Balance   decimal.Decimal `json:"balance"`
+	HeldDelta decimal.Decimal `json:"held_delta"`
+	ID        uuid.UUID       `json:"id"`
 }
 
 func (q *Queries) UpdateWalletBalance(ctx context.Context, arg UpdateWalletBalanceParams) (Wallet, error) {
-	row := q.db.QueryRow(ctx, updateWalletBalance, arg.Balance, arg.ID)
+	row := q.db.QueryRow(ctx, updateWalletBalance, arg.Balance, arg.HeldDelta, arg.ID)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
+	)
+	return i, err
+}
+
+const updateWalletStatus = `-- name: UpdateWalletStatus :one
+UPDATE wallets
+SET status     = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
+`
+
+type UpdateWalletStatusParams struct {
+	Status string    `json:"status"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatusParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, updateWalletStatus, arg.Status, arg.ID)
 	var i Wallet
 	err := row.Scan(
 		&i.ID,
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.HeldBalance,
+		&i.Status,
+		&i.OwnerID,
+		&i.ExternalRef,
 	)
 	return i, err
 }
@@ -0,0 +1,133 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: wallet_event.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimPendingWalletEvents = `-- name: ClaimPendingWalletEvents :many
+UPDATE wallet_events
+SET locked_until = $1::timestamptz
+WHERE id IN (SELECT e.id
+             FROM wallet_events e
+             WHERE e.published_at IS NULL
+               AND e.next_attempt_at <= NOW()
+               AND (e.locked_until IS NULL OR e.locked_until <= NOW())
+             ORDER BY e.created_at, e.id
+             LIMIT $2
+                 FOR UPDATE SKIP LOCKED)
+RETURNING id, wallet_id, event_type, payload, created_at, published_at, attempts, next_attempt_at, last_error, locked_until
+`
+
+type ClaimPendingWalletEventsParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	BatchSize   int32     `json:"batch_size"`
+}
+
+func (q *Queries) ClaimPendingWalletEvents(ctx context.Context, arg ClaimPendingWalletEventsParams) ([]WalletEvent, error) {
+	rows, err := q.db.Query(ctx, claimPendingWalletEvents, arg.LockedUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WalletEvent{}
+	for rows.Next() {
+		var i WalletEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.PublishedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWalletEvent = `-- name: CreateWalletEvent :one
+INSERT INTO wallet_events (id, wallet_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING id, wallet_id, event_type, payload, created_at, published_at, attempts, next_attempt_at, last_error, locked_until
+`
+
+type CreateWalletEventParams struct {
+	ID        uuid.UUID `json:"id"`
+	WalletID  uuid.UUID `json:"wallet_id"`
+	EventType string    `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+}
+
+func (q *Queries) CreateWalletEvent(ctx context.Context, arg CreateWalletEventParams) (WalletEvent, error) {
+	row := q.db.QueryRow(ctx, createWalletEvent,
+		arg.ID,
+		arg.WalletID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i WalletEvent
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.EventType,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.PublishedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const markWalletEventFailed = `-- name: MarkWalletEventFailed :exec
+UPDATE wallet_events
+SET attempts        = attempts + 1,
+    next_attempt_at = $1,
+    last_error      = $2,
+    locked_until    = NULL
+WHERE id = $3
+`
+
+type MarkWalletEventFailedParams struct {
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     *string   `json:"last_error"`
+	ID            uuid.UUID `json:"id"`
+}
+
+func (q *Queries) MarkWalletEventFailed(ctx context.Context, arg MarkWalletEventFailedParams) error {
+	_, err := q.db.Exec(ctx, markWalletEventFailed, arg.NextAttemptAt, arg.LastError, arg.ID)
+	return err
+}
+
+const markWalletEventPublished = `-- name: MarkWalletEventPublished :exec
+UPDATE wallet_events
+SET published_at = NOW(),
+    attempts     = attempts + 1,
+    last_error   = NULL,
+    locked_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkWalletEventPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markWalletEventPublished, id)
+	return err
+}
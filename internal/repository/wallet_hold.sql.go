@@ -0,0 +1,140 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: wallet_hold.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const createWalletHold = `-- name: CreateWalletHold :one
+INSERT INTO wallet_holds (id, wallet_id, amount, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+`
+
+type CreateWalletHoldParams struct {
+	ID        uuid.UUID       `json:"id"`
+	WalletID  uuid.UUID       `json:"wallet_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+func (q *Queries) CreateWalletHold(ctx context.Context, arg CreateWalletHoldParams) (WalletHold, error) {
+	row := q.db.QueryRow(ctx, createWalletHold,
+		arg.ID,
+		arg.WalletID,
+		arg.Amount,
+		arg.ExpiresAt,
+	)
+	var i WalletHold
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getWalletHoldForUpdate = `-- name: GetWalletHoldForUpdate :one
+SELECT id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+FROM wallet_holds
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (WalletHold, error) {
+	row := q.db.QueryRow(ctx, getWalletHoldForUpdate, id)
+	var i WalletHold
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const listExpiredWalletHolds = `-- name: ListExpiredWalletHolds :many
+SELECT id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+FROM wallet_holds
+WHERE status = 'ACTIVE'
+  AND expires_at <= NOW()
+ORDER BY expires_at
+LIMIT $1
+`
+
+func (q *Queries) ListExpiredWalletHolds(ctx context.Context, limit int32) ([]WalletHold, error) {
+	rows, err := q.db.Query(ctx, listExpiredWalletHolds, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WalletHold{}
+	for rows.Next() {
+		var i WalletHold
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Amount,
+			&i.CapturedAmount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWalletHoldStatus = `-- name: UpdateWalletHoldStatus :one
+UPDATE wallet_holds
+SET status          = $1,
+    captured_amount = $2,
+    updated_at      = NOW()
+WHERE id = $3
+RETURNING id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+`
+
+type UpdateWalletHoldStatusParams struct {
+	Status         string           `json:"status"`
+	CapturedAmount *decimal.Decimal `json:"captured_amount"`
+	ID             uuid.UUID        `json:"id"`
+}
+
+func (q *Queries) UpdateWalletHoldStatus(ctx context.Context, arg UpdateWalletHoldStatusParams) (WalletHold, error) {
+	row := q.db.QueryRow(ctx, updateWalletHoldStatus, arg.Status, arg.CapturedAmount, arg.ID)
+	var i WalletHold
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
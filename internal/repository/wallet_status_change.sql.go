@@ -0,0 +1,49 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: wallet_status_change.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createWalletStatusChange = `-- name: CreateWalletStatusChange :one
+INSERT INTO wallet_status_changes (id, wallet_id, from_status, to_status, reason, actor)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, wallet_id, from_status, to_status, reason, actor, created_at
+`
+
+type CreateWalletStatusChangeParams struct {
+	ID         uuid.UUID `json:"id"`
+	WalletID   uuid.UUID `json:"wallet_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+}
+
+func (q *Queries) CreateWalletStatusChange(ctx context.Context, arg CreateWalletStatusChangeParams) (WalletStatusChange, error) {
+	row := q.db.QueryRow(ctx, createWalletStatusChange,
+		arg.ID,
+		arg.WalletID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Reason,
+		arg.Actor,
+	)
+	var i WalletStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.Reason,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,289 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: wallet_transaction.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const createWalletTransaction = `-- name: CreateWalletTransaction :one
+INSERT INTO wallet_transactions (id, wallet_id, type, amount, balance_before, balance_after, counterparty_wallet_id,
+                                 fx_rate, fx_remainder, hold_id, reversal_of)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+`
+
+type CreateWalletTransactionParams struct {
+	ID                   uuid.UUID        `json:"id"`
+	WalletID             uuid.UUID        `json:"wallet_id"`
+	Type                 string           `json:"type"`
+	Amount               decimal.Decimal  `json:"amount"`
+	BalanceBefore        decimal.Decimal  `json:"balance_before"`
+	BalanceAfter         decimal.Decimal  `json:"balance_after"`
+	CounterpartyWalletID *uuid.UUID       `json:"counterparty_wallet_id"`
+	FxRate               *decimal.Decimal `json:"fx_rate"`
+	FxRemainder          *decimal.Decimal `json:"fx_remainder"`
+	HoldID               *uuid.UUID       `json:"hold_id"`
+	ReversalOf           *uuid.UUID       `json:"reversal_of"`
+}
+
+func (q *Queries) CreateWalletTransaction(ctx context.Context, arg CreateWalletTransactionParams) (WalletTransaction, error) {
+	row := q.db.QueryRow(ctx, createWalletTransaction,
+		arg.ID,
+		arg.WalletID,
+		arg.Type,
+		arg.Amount,
+		arg.BalanceBefore,
+		arg.BalanceAfter,
+		arg.CounterpartyWalletID,
+		arg.FxRate,
+		arg.FxRemainder,
+		arg.HoldID,
+		arg.ReversalOf,
+	)
+	var i WalletTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Type,
+		&i.Amount,
+		&i.BalanceBefore,
+		&i.BalanceAfter,
+		&i.CreatedAt,
+		&i.CounterpartyWalletID,
+		&i.FxRate,
+		&i.FxRemainder,
+		&i.HoldID,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS reversed_amount
+FROM wallet_transactions
+WHERE reversal_of = $1
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, reversalOf *uuid.UUID) (decimal.Decimal, error) {
+	row := q.db.QueryRow(ctx, getReversedAmount, reversalOf)
+	var reversed_amount decimal.Decimal
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
+const getWalletTransaction = `-- name: GetWalletTransaction :one
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE id = $1
+`
+
+func (q *Queries) GetWalletTransaction(ctx context.Context, id uuid.UUID) (WalletTransaction, error) {
+	row := q.db.QueryRow(ctx, getWalletTransaction, id)
+	var i WalletTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Type,
+		&i.Amount,
+		&i.BalanceBefore,
+		&i.BalanceAfter,
+		&i.CreatedAt,
+		&i.CounterpartyWalletID,
+		&i.FxRate,
+		&i.FxRemainder,
+		&i.HoldID,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const getWalletTransactionForUpdate = `-- name: GetWalletTransactionForUpdate :one
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) GetWalletTransactionForUpdate(ctx context.Context, id uuid.UUID) (WalletTransaction, error) {
+	row := q.db.QueryRow(ctx, getWalletTransactionForUpdate, id)
+	var i WalletTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Type,
+		&i.Amount,
+		&i.BalanceBefore,
+		&i.BalanceAfter,
+		&i.CreatedAt,
+		&i.CounterpartyWalletID,
+		&i.FxRate,
+		&i.FxRemainder,
+		&i.HoldID,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const listReversedAmounts = `-- name: ListReversedAmounts :many
+SELECT reversal_of::uuid AS transaction_id, SUM(amount)::numeric AS reversed_amount
+FROM wallet_transactions
+WHERE reversal_of = ANY ($1::uuid[])
+GROUP BY reversal_of
+`
+
+type ListReversedAmountsRow struct {
+	TransactionID  uuid.UUID       `json:"transaction_id"`
+	ReversedAmount decimal.Decimal `json:"reversed_amount"`
+}
+
+func (q *Queries) ListReversedAmounts(ctx context.Context, transactionIds []uuid.UUID) ([]ListReversedAmountsRow, error) {
+	rows, err := q.db.Query(ctx, listReversedAmounts, transactionIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReversedAmountsRow{}
+	for rows.Next() {
+		var i ListReversedAmountsRow
+		if err := rows.Scan(&i.TransactionID, &i.ReversedAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletTransactions = `-- name: ListWalletTransactions :many
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE wallet_id = $1
+  AND ($2::varchar IS NULL OR type = $2)
+  AND ($3::numeric IS NULL OR amount >= $3)
+  AND ($4::numeric IS NULL OR amount <= $4)
+  AND ($5::timestamptz IS NULL OR created_at >= $5)
+  AND ($6::timestamptz IS NULL OR created_at < $6)
+  AND ($7::timestamptz IS NULL
+    OR (created_at, id) < ($7, $8::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListWalletTransactionsParams struct {
+	WalletID        uuid.UUID        `json:"wallet_id"`
+	Type            *string          `json:"type"`
+	MinAmount       *decimal.Decimal `json:"min_amount"`
+	MaxAmount       *decimal.Decimal `json:"max_amount"`
+	CreatedFrom     *time.Time       `json:"created_from"`
+	CreatedTo       *time.Time       `json:"created_to"`
+	CursorCreatedAt *time.Time       `json:"cursor_created_at"`
+	CursorID        *uuid.UUID       `json:"cursor_id"`
+	PageLimit       int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListWalletTransactions(ctx context.Context, arg ListWalletTransactionsParams) ([]WalletTransaction, error) {
+	rows, err := q.db.Query(ctx, listWalletTransactions,
+		arg.WalletID,
+		arg.Type,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WalletTransaction{}
+	for rows.Next() {
+		var i WalletTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Type,
+			&i.Amount,
+			&i.BalanceBefore,
+			&i.BalanceAfter,
+			&i.CreatedAt,
+			&i.CounterpartyWalletID,
+			&i.FxRate,
+			&i.FxRemainder,
+			&i.HoldID,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletTransactionsAfter = `-- name: ListWalletTransactionsAfter :many
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE wallet_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::uuid)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListWalletTransactionsAfterParams struct {
+	WalletID       uuid.UUID `json:"wallet_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        uuid.UUID `json:"after_id"`
+	PageLimit      int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListWalletTransactionsAfter(ctx context.Context, arg ListWalletTransactionsAfterParams) ([]WalletTransaction, error) {
+	rows, err := q.db.Query(ctx, listWalletTransactionsAfter,
+		arg.WalletID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WalletTransaction{}
+	for rows.Next() {
+		var i WalletTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Type,
+			&i.Amount,
+			&i.BalanceBefore,
+			&i.BalanceAfter,
+			&i.CreatedAt,
+			&i.CounterpartyWalletID,
+			&i.FxRate,
+			&i.FxRemainder,
+			&i.HoldID,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
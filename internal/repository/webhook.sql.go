@@ -0,0 +1,385 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: webhook.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET locked_until = $1::timestamptz
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (SELECT w.id
+               FROM webhook_deliveries w
+               WHERE w.status = 'PENDING'
+                 AND w.next_attempt_at <= NOW()
+                 AND (w.locked_until IS NULL OR w.locked_until <= NOW())
+               ORDER BY w.next_attempt_at, w.id
+               LIMIT $2
+                   FOR UPDATE SKIP LOCKED)
+RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	BatchSize   int32     `json:"batch_size"`
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	EventID        uuid.UUID `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Payload        []byte    `json:"payload"`
+	Attempts       int32     `json:"attempts"`
+	Url            string    `json:"url"`
+	Secret         string    `json:"secret"`
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.LockedUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimDueWebhookDeliveriesRow{}
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subscription_id, event_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	EventID        uuid.UUID `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Payload        []byte    `json:"payload"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (id, url, secret, wallet_id, event_types, owner_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, url, secret, wallet_id, event_types, created_at, owner_id
+`
+
+type CreateWebhookSubscriptionParams struct {
+	ID         uuid.UUID  `json:"id"`
+	Url        string     `json:"url"`
+	Secret     string     `json:"secret"`
+	WalletID   *uuid.UUID `json:"wallet_id"`
+	EventTypes []string   `json:"event_types"`
+	OwnerID    *string    `json:"owner_id"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.ID,
+		arg.Url,
+		arg.Secret,
+		arg.WalletID,
+		arg.EventTypes,
+		arg.OwnerID,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Secret,
+		&i.WalletID,
+		&i.EventTypes,
+		&i.CreatedAt,
+		&i.OwnerID,
+	)
+	return i, err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+  AND ($2::varchar IS NULL OR owner_id = $2)
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID *string   `json:"owner_id"`
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookSubscription, arg.ID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listMatchingWebhookSubscriptions = `-- name: ListMatchingWebhookSubscriptions :many
+SELECT id, url, secret, wallet_id, event_types, created_at, owner_id
+FROM webhook_subscriptions
+WHERE (wallet_id IS NULL OR wallet_id = $1)
+  AND $2::text = ANY (event_types)
+  AND (owner_id IS NULL OR owner_id = (SELECT w.owner_id FROM wallets w WHERE w.id = $1))
+`
+
+type ListMatchingWebhookSubscriptionsParams struct {
+	WalletID  *uuid.UUID `json:"wallet_id"`
+	EventType string     `json:"event_type"`
+}
+
+// Подписка владельца получает события только его кошельков, подписка без владельца - всех
+func (q *Queries) ListMatchingWebhookSubscriptions(ctx context.Context, arg ListMatchingWebhookSubscriptionsParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listMatchingWebhookSubscriptions, arg.WalletID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Secret,
+			&i.WalletID,
+			&i.EventTypes,
+			&i.CreatedAt,
+			&i.OwnerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, last_status_code,
+       created_at, delivered_at, locked_until
+FROM webhook_deliveries
+WHERE subscription_id = $1
+  AND ($2::varchar IS NULL OR status = $2)
+  AND subscription_id IN (SELECT s.id
+                          FROM webhook_subscriptions s
+                          WHERE $3::varchar IS NULL
+                             OR s.owner_id = $3)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Status         *string   `json:"status"`
+	OwnerID        *string   `json:"owner_id"`
+	PageLimit      int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries,
+		arg.SubscriptionID,
+		arg.Status,
+		arg.OwnerID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.LastStatusCode,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, secret, wallet_id, event_types, created_at, owner_id
+FROM webhook_subscriptions
+WHERE ($1::varchar IS NULL OR owner_id = $1)
+ORDER BY created_at, id
+`
+
+// owner_id = NULL в фильтре - подписки всех владельцев
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, ownerID *string) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Secret,
+			&i.WalletID,
+			&i.EventTypes,
+			&i.CreatedAt,
+			&i.OwnerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDeliveryDelivered = `-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status           = 'DELIVERED',
+    attempts         = attempts + 1,
+    last_status_code = $1,
+    last_error       = NULL,
+    delivered_at     = NOW(),
+    locked_until     = NULL
+WHERE id = $2
+`
+
+type MarkWebhookDeliveryDeliveredParams struct {
+	StatusCode *int32    `json:"status_code"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliveryDelivered, arg.StatusCode, arg.ID)
+	return err
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status           = $1,
+    attempts         = attempts + 1,
+    next_attempt_at  = $2,
+    last_error       = $3,
+    last_status_code = $4,
+    locked_until     = NULL
+WHERE id = $5
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	Status        string    `json:"status"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     *string   `json:"last_error"`
+	StatusCode    *int32    `json:"status_code"`
+	ID            uuid.UUID `json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	_, err := q.db.Exec(ctx, markWebhookDeliveryFailed,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastError,
+		arg.StatusCode,
+		arg.ID,
+	)
+	return err
+}
+
+const redeliverWebhookDelivery = `-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status          = 'PENDING',
+    attempts        = 0,
+    next_attempt_at = NOW(),
+    last_error      = NULL
+WHERE webhook_deliveries.id = $1
+  AND subscription_id IN (SELECT s.id
+                          FROM webhook_subscriptions s
+                          WHERE $2::varchar IS NULL
+                             OR s.owner_id = $2)
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, last_status_code,
+          created_at, delivered_at, locked_until
+`
+
+type RedeliverWebhookDeliveryParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID *string   `json:"owner_id"`
+}
+
+func (q *Queries) RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, redeliverWebhookDelivery, arg.ID, arg.OwnerID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.LastStatusCode,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
@@ -0,0 +1,321 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	// Ключ имеет вид wk_<prefix>_<secret>: prefix хранится открыто и служит для поиска
+	tokenScheme  = "wk"
+	prefixBytes  = 6
+	secretBytes  = 32
+	maxNameLen   = 128
+	maxOwnerLen  = 128
+	maxStaffLen  = 128
+	BootstrapKey = "bootstrap"
+)
+
+type ApiKeyService interface {
+	// Issue выпускает ключ. Секрет возвращается только здесь и в Rotate
+	Issue(ctx context.Context, r Issue) (IssuedKey, error)
+	List(ctx context.Context) ([]Key, error)
+	Revoke(ctx context.Context, id uuid.UUID) (Key, error)
+	// Rotate выпускает ключ с теми же именем и scope и отзывает старый
+	Rotate(ctx context.Context, id uuid.UUID) (IssuedKey, error)
+	// Authenticate проверяет ключ из запроса. Любая причина отказа - auth.ErrUnauthenticated
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// Issue - параметры нового ключа. Клиентскому ключу нужен OwnerID, ключу поддержки
+// или admin - StaffID сотрудника; владелец у таких ключей необязателен.
+type Issue struct {
+	Name    string
+	Scopes  []string
+	OwnerID string
+	StaffID string
+}
+
+type Key struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	OwnerID     *string    `json:"owner_id"`
+	StaffID     *string    `json:"staff_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RotatedFrom *uuid.UUID `json:"rotated_from,omitempty"`
+}
+
+// IssuedKey - только что выпущенный ключ вместе с секретом
+type IssuedKey struct {
+	Key
+	Token string `json:"key"`
+}
+
+type apiKeyService struct {
+	repo          repository.Repository
+	logger        logger.Logger
+	bootstrapHash []byte
+}
+
+func New(repo repository.Repository, log logger.Logger, opts ...Option) ApiKeyService {
+	s := &apiKeyService{
+		repo:   repo,
+		logger: log,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *apiKeyService) Issue(ctx context.Context, r Issue) (IssuedKey, error) {
+	name := strings.TrimSpace(r.Name)
+	if name == "" || len(name) > maxNameLen {
+		return IssuedKey{}, fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidKey, maxNameLen)
+	}
+	scopes, err := normalizeScopes(r.Scopes)
+	if err != nil {
+		return IssuedKey{}, err
+	}
+	var owner, staff *string
+	if ownerID := strings.TrimSpace(r.OwnerID); ownerID != "" {
+		if len(ownerID) > maxOwnerLen {
+			return IssuedKey{}, fmt.Errorf("%w: owner id must be at most %d characters", ErrInvalidKey, maxOwnerLen)
+		}
+		owner = &ownerID
+	}
+	if staffID := strings.TrimSpace(r.StaffID); staffID != "" {
+		if len(staffID) > maxStaffLen {
+			return IssuedKey{}, fmt.Errorf("%w: staff id must be at most %d characters", ErrInvalidKey, maxStaffLen)
+		}
+		staff = &staffID
+	}
+
+	// Персонал действует от своего имени, а не от имени владельца кошельков
+	if isStaff(scopes) {
+		if staff == nil {
+			return IssuedKey{}, fmt.Errorf("%w: staff id is required for support and admin keys", ErrInvalidKey)
+		}
+	} else {
+		if owner == nil {
+			return IssuedKey{}, fmt.Errorf("%w: owner id is required for client keys", ErrInvalidKey)
+		}
+		if staff != nil {
+			return IssuedKey{}, fmt.Errorf("%w: staff id is only for support and admin keys", ErrInvalidKey)
+		}
+	}
+	return s.create(ctx, s.repo, repository.ApiKey{Name: name, Scopes: scopes, OwnerID: owner, StaffID: staff}, nil)
+}
+
+func (s *apiKeyService) List(ctx context.Context) ([]Key, error) {
+	rows, err := s.repo.ListApiKeys(ctx)
+	if err != nil {
+		s.logger.Error("failed to list api keys", zap.Error(err))
+		return nil, err
+	}
+	keys := make([]Key, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toKey(row))
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id uuid.UUID) (Key, error) {
+	row, err := s.repo.RevokeApiKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Key{}, ErrKeyNotFound
+		}
+		s.logger.Error("failed to revoke api key", zap.String("keyId", id.String()), zap.Error(err))
+		return Key{}, err
+	}
+	s.logger.Info("api key revoked", zap.String("keyId", id.String()), zap.String("prefix", row.Prefix))
+	return toKey(row), nil
+}
+
+func (s *apiKeyService) Rotate(ctx context.Context, id uuid.UUID) (IssuedKey, error) {
+	var issued IssuedKey
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		old, err := q.GetApiKey(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrKeyNotFound
+			}
+			return err
+		}
+		if old.RevokedAt != nil {
+			return ErrKeyRevoked
+		}
+		issued, err = s.create(ctx, q, old, &old.ID)
+		if err != nil {
+			return err
+		}
+		_, err = q.RevokeApiKey(ctx, old.ID)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrKeyRevoked) {
+			s.logger.Error("failed to rotate api key", zap.String("keyId", id.String()), zap.Error(err))
+		}
+		return IssuedKey{}, err
+	}
+	s.logger.Info("api key rotated", zap.String("keyId", id.String()), zap.String("newKeyId", issued.ID.String()))
+	return issued, nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if s.bootstrapHash != nil {
+		h := hash(token)
+		if subtle.ConstantTimeCompare(h[:], s.bootstrapHash) == 1 {
+			return auth.Principal{ID: BootstrapKey, Name: BootstrapKey, Scopes: []string{auth.ScopeAdmin}, Subject: auth.KeySubject(BootstrapKey)}, nil
+		}
+	}
+
+	prefix, secret, ok := parseToken(token)
+	if !ok {
+		return auth.Principal{}, fmt.Errorf("%w: malformed key", auth.ErrUnauthenticated)
+	}
+	row, err := s.repo.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.Principal{}, fmt.Errorf("%w: unknown key", auth.ErrUnauthenticated)
+		}
+		s.logger.Error("failed to load api key", zap.String("prefix", prefix), zap.Error(err))
+		return auth.Principal{}, err
+	}
+	h := hash(secret)
+	if subtle.ConstantTimeCompare(h[:], row.SecretHash) != 1 {
+		return auth.Principal{}, fmt.Errorf("%w: secret mismatch", auth.ErrUnauthenticated)
+	}
+	if row.RevokedAt != nil {
+		return auth.Principal{}, fmt.Errorf("%w: key revoked", auth.ErrUnauthenticated)
+	}
+
+	if err = s.repo.TouchApiKey(ctx, row.ID); err != nil {
+		s.logger.Warn("failed to update api key last use", zap.String("keyId", row.ID.String()), zap.Error(err))
+	}
+	p := auth.Principal{ID: row.ID.String(), Name: row.Name, Scopes: row.Scopes, Subject: auth.KeySubject(row.Name)}
+	if row.OwnerID != nil {
+		p.OwnerID = *row.OwnerID
+	}
+	if row.StaffID != nil {
+		p.Subject = auth.StaffSubject(*row.StaffID)
+	}
+	return p, nil
+}
+
+// create выпускает ключ с именем, scope, владельцем и сотрудником из tmpl
+func (s *apiKeyService) create(ctx context.Context, q repository.Querier, tmpl repository.ApiKey, rotatedFrom *uuid.UUID) (IssuedKey, error) {
+	prefix, err := randomHex(prefixBytes)
+	if err != nil {
+		return IssuedKey{}, err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return IssuedKey{}, err
+	}
+	h := hash(secret)
+	row, err := q.CreateApiKey(ctx, repository.CreateApiKeyParams{
+		ID:          uuid.New(),
+		Name:        tmpl.Name,
+		Prefix:      prefix,
+		SecretHash:  h[:],
+		Scopes:      tmpl.Scopes,
+		RotatedFrom: rotatedFrom,
+		OwnerID:     tmpl.OwnerID,
+		StaffID:     tmpl.StaffID,
+	})
+	if err != nil {
+		s.logger.Error("failed to create api key", zap.Error(err))
+		return IssuedKey{}, err
+	}
+	return IssuedKey{
+		Key:   toKey(row),
+		Token: tokenScheme + "_" + prefix + "_" + secret,
+	}, nil
+}
+
+// Prefix возвращает открытую часть ключа, которую можно писать в лог
+func Prefix(token string) string {
+	prefix, _, ok := parseToken(token)
+	if !ok {
+		return ""
+	}
+	return prefix
+}
+
+func parseToken(token string) (prefix, secret string, ok bool) {
+	parts := strings.SplitN(token, "_", 3)
+	if len(parts) != 3 || parts[0] != tokenScheme || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidKey)
+	}
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidKey, scope)
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// isStaff сообщает, выдается ли ключ персоналу: роль поддержки или admin
+func isStaff(scopes []string) bool {
+	return auth.Principal{Scopes: scopes}.HasRole(auth.RoleViewer)
+}
+
+func hash(s string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(s))
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func toKey(row repository.ApiKey) Key {
+	return Key{
+		ID:          row.ID,
+		Name:        row.Name,
+		Prefix:      row.Prefix,
+		Scopes:      row.Scopes,
+		OwnerID:     row.OwnerID,
+		StaffID:     row.StaffID,
+		CreatedAt:   row.CreatedAt,
+		LastUsedAt:  row.LastUsedAt,
+		RevokedAt:   row.RevokedAt,
+		RotatedFrom: row.RotatedFrom,
+	}
+}
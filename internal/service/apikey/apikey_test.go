@@ -0,0 +1,205 @@
+package apikey_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/apikey"
+)
+
+func newRepo(t *testing.T) *mocks.MockRepository {
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error {
+			return fn(repo)
+		}).AnyTimes()
+	return repo
+}
+
+// issue выпускает ключ и возвращает его вместе с сохраненной строкой
+func issue(t *testing.T, repo *mocks.MockRepository, svc apikey.ApiKeyService, scopes ...string) (apikey.IssuedKey, repository.ApiKey) {
+	t.Helper()
+	var stored repository.ApiKey
+	repo.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+			stored = repository.ApiKey{
+				ID:          arg.ID,
+				Name:        arg.Name,
+				Prefix:      arg.Prefix,
+				SecretHash:  arg.SecretHash,
+				Scopes:      arg.Scopes,
+				CreatedAt:   time.Now(),
+				RotatedFrom: arg.RotatedFrom,
+				OwnerID:     arg.OwnerID,
+			}
+			return stored, nil
+		})
+	key, err := svc.Issue(context.Background(), apikey.Issue{Name: "billing", Scopes: scopes, OwnerID: "merchant-1"})
+	require.NoError(t, err)
+	return key, stored
+}
+
+func TestIssue_StoresOnlyHash(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+
+	key, stored := issue(t, repo, svc, auth.ScopeWalletWrite, auth.ScopeWalletRead, auth.ScopeWalletRead)
+
+	assert.True(t, strings.HasPrefix(key.Token, "wk_"+stored.Prefix+"_"))
+	assert.Equal(t, stored.Prefix, apikey.Prefix(key.Token))
+	assert.Equal(t, []string{auth.ScopeWalletRead, auth.ScopeWalletWrite}, key.Scopes)
+	secret := strings.TrimPrefix(key.Token, "wk_"+stored.Prefix+"_")
+	hash := sha256.Sum256([]byte(secret))
+	assert.Equal(t, hash[:], stored.SecretHash)
+	assert.NotContains(t, string(stored.SecretHash), secret)
+}
+
+func TestIssue_Validates(t *testing.T) {
+	svc := apikey.New(newRepo(t), zap.NewNop())
+
+	_, err := svc.Issue(context.Background(), apikey.Issue{Name: "billing", Scopes: []string{"wallet:delete"}, OwnerID: "m"})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: "billing", OwnerID: "m"})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: " ", Scopes: []string{auth.ScopeAdmin}})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	// Ключ без admin обязан принадлежать владельцу
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: "billing", Scopes: []string{auth.ScopeWalletRead}})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: "billing", Scopes: []string{auth.ScopeWalletRead}, OwnerID: strings.Repeat("o", 129)})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	// Ключу персонала нужен сотрудник, клиентскому ключу сотрудник не положен
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: "support", Scopes: []string{auth.RoleViewer}})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+	_, err = svc.Issue(context.Background(), apikey.Issue{Name: "billing", Scopes: []string{auth.ScopeWalletRead}, OwnerID: "m", StaffID: "alice"})
+	assert.ErrorIs(t, err, apikey.ErrInvalidKey)
+}
+
+func TestIssue_StaffKeyWithoutOwner(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+	var stored repository.ApiKey
+	repo.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+			assert.Nil(t, arg.OwnerID)
+			stored = repository.ApiKey{ID: arg.ID, Name: arg.Name, Prefix: arg.Prefix, SecretHash: arg.SecretHash, Scopes: arg.Scopes, StaffID: arg.StaffID}
+			return stored, nil
+		})
+	repo.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (repository.ApiKey, error) { return stored, nil })
+	repo.EXPECT().TouchApiKey(gomock.Any(), gomock.Any()).Return(nil)
+
+	key, err := svc.Issue(context.Background(), apikey.Issue{Name: "Night shift", Scopes: []string{auth.RoleOperator}, StaffID: " Alice "})
+	require.NoError(t, err)
+	require.NotNil(t, key.StaffID)
+	assert.Equal(t, "Alice", *key.StaffID)
+
+	p, err := svc.Authenticate(context.Background(), key.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "staff:alice", p.Subject, "личность ключа персонала - сотрудник, а не имя ключа")
+	assert.Empty(t, p.OwnerID)
+}
+
+func TestAuthenticate(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+	key, stored := issue(t, repo, svc, auth.ScopeWalletRead)
+	repo.EXPECT().GetApiKeyByPrefix(gomock.Any(), stored.Prefix).Return(stored, nil).AnyTimes()
+	repo.EXPECT().TouchApiKey(gomock.Any(), stored.ID).Return(nil)
+
+	p, err := svc.Authenticate(context.Background(), key.Token)
+
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID.String(), p.ID)
+	assert.Equal(t, "key:billing", p.Subject, "личность ключа - его имя, она переживает ротацию")
+	assert.Equal(t, "merchant-1", p.OwnerID)
+	assert.True(t, p.HasScope(auth.ScopeWalletRead))
+	assert.False(t, p.HasScope(auth.ScopeWalletWrite))
+
+	_, err = svc.Authenticate(context.Background(), "wk_"+stored.Prefix+"_deadbeef")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+	_, err = svc.Authenticate(context.Background(), "garbage")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestAuthenticate_RevokedAndUnknown(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+	key, stored := issue(t, repo, svc, auth.ScopeWalletRead)
+	now := time.Now()
+	stored.RevokedAt = &now
+	repo.EXPECT().GetApiKeyByPrefix(gomock.Any(), stored.Prefix).Return(stored, nil)
+	repo.EXPECT().GetApiKeyByPrefix(gomock.Any(), "000000").Return(repository.ApiKey{}, pgx.ErrNoRows)
+
+	_, err := svc.Authenticate(context.Background(), key.Token)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+	_, err = svc.Authenticate(context.Background(), "wk_000000_abc")
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestAuthenticate_BootstrapKey(t *testing.T) {
+	svc := apikey.New(newRepo(t), zap.NewNop(), apikey.WithBootstrapKey("s3cret-bootstrap"))
+
+	p, err := svc.Authenticate(context.Background(), "s3cret-bootstrap")
+
+	require.NoError(t, err)
+	assert.Equal(t, apikey.BootstrapKey, p.ID)
+	assert.True(t, p.HasScope(auth.ScopeWalletWrite))
+}
+
+func TestRotate(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+	old := repository.ApiKey{ID: uuid.New(), Name: "billing", Prefix: "abc", Scopes: []string{auth.ScopeWalletRead}}
+	repo.EXPECT().GetApiKey(gomock.Any(), old.ID).Return(old, nil)
+	repo.EXPECT().CreateApiKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+			assert.Equal(t, old.Name, arg.Name)
+			assert.Equal(t, old.Scopes, arg.Scopes)
+			assert.Equal(t, &old.ID, arg.RotatedFrom)
+			assert.NotEqual(t, old.Prefix, arg.Prefix)
+			return repository.ApiKey{ID: arg.ID, Name: arg.Name, Prefix: arg.Prefix, Scopes: arg.Scopes, RotatedFrom: arg.RotatedFrom}, nil
+		})
+	repo.EXPECT().RevokeApiKey(gomock.Any(), old.ID).Return(old, nil)
+
+	key, err := svc.Rotate(context.Background(), old.ID)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, key.Token)
+	assert.Equal(t, &old.ID, key.RotatedFrom)
+}
+
+func TestRotate_Errors(t *testing.T) {
+	repo := newRepo(t)
+	svc := apikey.New(repo, zap.NewNop())
+	missing, revoked := uuid.New(), uuid.New()
+	now := time.Now()
+	repo.EXPECT().GetApiKey(gomock.Any(), missing).Return(repository.ApiKey{}, pgx.ErrNoRows)
+	repo.EXPECT().GetApiKey(gomock.Any(), revoked).Return(repository.ApiKey{ID: revoked, RevokedAt: &now}, nil)
+
+	_, err := svc.Rotate(context.Background(), missing)
+	assert.ErrorIs(t, err, apikey.ErrKeyNotFound)
+	_, err = svc.Rotate(context.Background(), revoked)
+	assert.ErrorIs(t, err, apikey.ErrKeyRevoked)
+}
+
+func TestRevoke_NotFound(t *testing.T) {
+	repo := newRepo(t)
+	id := uuid.New()
+	repo.EXPECT().RevokeApiKey(gomock.Any(), id).Return(repository.ApiKey{}, pgx.ErrNoRows)
+
+	_, err := apikey.New(repo, zap.NewNop()).Revoke(context.Background(), id)
+
+	assert.ErrorIs(t, err, apikey.ErrKeyNotFound)
+}
@@ -0,0 +1,9 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrKeyNotFound = errors.New("api key not found")
+	ErrKeyRevoked  = errors.New("api key is revoked")
+	ErrInvalidKey  = errors.New("invalid api key parameters")
+)
@@ -0,0 +1,14 @@
+package apikey
+
+type Option func(*apiKeyService)
+
+// WithBootstrapKey задает статический ключ с правами admin, чтобы выпустить первые
+// ключи на пустой базе. Пустая строка ничего не включает.
+func WithBootstrapKey(token string) Option {
+	return func(s *apiKeyService) {
+		if token != "" {
+			h := hash(token)
+			s.bootstrapHash = h[:]
+		}
+	}
+}
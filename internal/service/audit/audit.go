@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	DefaultPageSize = 500
+
+	ActorAnonymous = "anonymous"
+	OutcomeOK      = "ok"
+	// ActionRequest - вызов, не дошедший до сервиса: отказ аутентификации, валидации и т.п.
+	ActionRequest = "api.request"
+
+	recordTimeout = 5 * time.Second
+)
+
+// Recorder дописывает запись в журнал аудита
+type Recorder interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+type AuditService interface {
+	Recorder
+	// Verify проходит всю цепочку в базе. Первая нарушенная запись - ErrChainBroken.
+	// checkpoint ловит пересчет всей цепочки и обрезку хвоста; nil - без сверки
+	Verify(ctx context.Context, checkpoint *Checkpoint) (Verification, error)
+	// Export пишет записи с id больше afterID в w по одной JSON-строке
+	Export(ctx context.Context, w io.Writer, afterID int64) (int, error)
+}
+
+// Entry - изменяющий вызов: кто, что и над каким кошельком сделал и чем это кончилось
+type Entry struct {
+	Actor         string
+	Action        string
+	Route         string
+	WalletID      *uuid.UUID
+	PayloadDigest string
+	Outcome       string
+	StatusCode    *int32
+	ClientIP      string
+}
+
+type auditService struct {
+	repo     repository.Repository
+	logger   logger.Logger
+	pageSize int32
+}
+
+func New(repo repository.Repository, log logger.Logger) AuditService {
+	return &auditService{
+		repo:     repo,
+		logger:   log,
+		pageSize: DefaultPageSize,
+	}
+}
+
+// Record пишет запись даже после отмены ctx: запрос, который клиент бросил,
+// уже мог изменить данные.
+func (s *auditService) Record(ctx context.Context, e Entry) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if e.Actor == "" {
+		e.Actor = ActorAnonymous
+	}
+	row := repository.AuditLog{
+		// Postgres хранит микросекунды: хеш должен сойтись после чтения из базы
+		OccurredAt:    time.Now().UTC().Truncate(time.Microsecond),
+		Actor:         e.Actor,
+		Action:        e.Action,
+		Route:         e.Route,
+		WalletID:      e.WalletID,
+		PayloadDigest: e.PayloadDigest,
+		Outcome:       e.Outcome,
+		StatusCode:    e.StatusCode,
+		ClientIp:      e.ClientIP,
+	}
+
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		if err := q.LockAuditLog(ctx); err != nil {
+			return err
+		}
+		head, err := q.GetAuditLogHead(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			head, err = GenesisHash, nil
+		}
+		if err != nil {
+			return err
+		}
+		row.PrevHash = head
+		row.Hash = Hash(row)
+
+		_, err = q.CreateAuditEntry(ctx, repository.CreateAuditEntryParams{
+			OccurredAt:    row.OccurredAt,
+			Actor:         row.Actor,
+			Action:        row.Action,
+			Route:         row.Route,
+			WalletID:      row.WalletID,
+			PayloadDigest: row.PayloadDigest,
+			Outcome:       row.Outcome,
+			StatusCode:    row.StatusCode,
+			ClientIp:      row.ClientIp,
+			PrevHash:      row.PrevHash,
+			Hash:          row.Hash,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to write audit entry",
+			zap.String("actor", e.Actor),
+			zap.String("action", e.Action),
+			zap.String("route", e.Route),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *auditService) Verify(ctx context.Context, checkpoint *Checkpoint) (Verification, error) {
+	chain := NewChain(GenesisHash).Expect(checkpoint)
+	err := s.each(ctx, 0, func(row repository.AuditLog) error {
+		return chain.Append(row)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrChainBroken) {
+			s.logger.Error("failed to verify audit log", zap.Error(err))
+		}
+		return chain.Result(), err
+	}
+	return chain.Finish()
+}
+
+func (s *auditService) Export(ctx context.Context, w io.Writer, afterID int64) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	err := s.each(ctx, afterID, func(row repository.AuditLog) error {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// each читает журнал страницами по порядку id
+func (s *auditService) each(ctx context.Context, afterID int64, fn func(repository.AuditLog) error) error {
+	for {
+		rows, err := s.repo.ListAuditLog(ctx, repository.ListAuditLogParams{AfterID: afterID, RowLimit: s.pageSize})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err = fn(row); err != nil {
+				return err
+			}
+			afterID = row.ID
+		}
+		if len(rows) < int(s.pageSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+// fakeLog - журнал в памяти поверх мока репозитория
+type fakeLog struct {
+	rows []repository.AuditLog
+}
+
+func newRepo(t *testing.T, log *fakeLog) *mocks.MockRepository {
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error {
+			return fn(repo)
+		}).AnyTimes()
+	repo.EXPECT().LockAuditLog(gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().GetAuditLogHead(gomock.Any()).
+		DoAndReturn(func(context.Context) (string, error) {
+			if len(log.rows) == 0 {
+				return "", pgx.ErrNoRows
+			}
+			return log.rows[len(log.rows)-1].Hash, nil
+		}).AnyTimes()
+	repo.EXPECT().CreateAuditEntry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateAuditEntryParams) (repository.AuditLog, error) {
+			row := repository.AuditLog{
+				ID:            int64(len(log.rows) + 1),
+				OccurredAt:    arg.OccurredAt,
+				Actor:         arg.Actor,
+				Action:        arg.Action,
+				Route:         arg.Route,
+				WalletID:      arg.WalletID,
+				PayloadDigest: arg.PayloadDigest,
+				Outcome:       arg.Outcome,
+				StatusCode:    arg.StatusCode,
+				ClientIp:      arg.ClientIp,
+				PrevHash:      arg.PrevHash,
+				Hash:          arg.Hash,
+			}
+			log.rows = append(log.rows, row)
+			return row, nil
+		}).AnyTimes()
+	repo.EXPECT().ListAuditLog(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.ListAuditLogParams) ([]repository.AuditLog, error) {
+			var page []repository.AuditLog
+			for _, row := range log.rows {
+				if row.ID > arg.AfterID && len(page) < int(arg.RowLimit) {
+					page = append(page, row)
+				}
+			}
+			return page, nil
+		}).AnyTimes()
+	return repo
+}
+
+func record(t *testing.T, svc audit.AuditService, n int) {
+	t.Helper()
+	walletID := uuid.New()
+	for i := 0; i < n; i++ {
+		require.NoError(t, svc.Record(context.Background(), audit.Entry{
+			Actor:         "key-1",
+			Action:        "wallet.operation.deposit",
+			Route:         "POST /api/v1/wallet/",
+			WalletID:      &walletID,
+			PayloadDigest: audit.Digest([]byte(`{"amount":"10"}`)),
+			Outcome:       audit.OutcomeOK,
+			ClientIP:      "10.0.0.1",
+		}))
+	}
+}
+
+func TestRecord_ChainsEntries(t *testing.T) {
+	log := &fakeLog{}
+	svc := audit.New(newRepo(t, log), zap.NewNop())
+
+	record(t, svc, 3)
+	require.NoError(t, svc.Record(context.Background(), audit.Entry{Action: audit.ActionRequest, Outcome: "Unauthorized"}))
+
+	require.Len(t, log.rows, 4)
+	assert.Equal(t, audit.GenesisHash, log.rows[0].PrevHash)
+	for i := 1; i < len(log.rows); i++ {
+		assert.Equal(t, log.rows[i-1].Hash, log.rows[i].PrevHash)
+	}
+	assert.Equal(t, audit.ActorAnonymous, log.rows[3].Actor)
+
+	result, err := svc.Verify(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.Entries)
+	assert.Equal(t, log.rows[3].Hash, result.Head)
+	assert.True(t, result.Complete)
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	cases := []struct {
+		name   string
+		tamper func(rows []repository.AuditLog) []repository.AuditLog
+		valid  int64
+	}{
+		{"edited outcome", func(rows []repository.AuditLog) []repository.AuditLog {
+			rows[1].Outcome = "insufficient funds"
+			return rows
+		}, 1},
+		// Пересчитанный хеш не спасает: следующая запись ссылается на старый
+		{"edited and rehashed", func(rows []repository.AuditLog) []repository.AuditLog {
+			rows[1].Actor = "someone-else"
+			rows[1].Hash = audit.Hash(rows[1])
+			return rows
+		}, 2},
+		{"deleted entry", func(rows []repository.AuditLog) []repository.AuditLog {
+			return append(rows[:1], rows[2:]...)
+		}, 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &fakeLog{}
+			svc := audit.New(newRepo(t, log), zap.NewNop())
+			record(t, svc, 3)
+			log.rows = tt.tamper(log.rows)
+
+			result, err := svc.Verify(context.Background(), nil)
+
+			require.ErrorIs(t, err, audit.ErrChainBroken)
+			assert.Equal(t, tt.valid, result.Entries)
+		})
+	}
+}
+
+func TestVerify_Checkpoint(t *testing.T) {
+	cases := []struct {
+		name   string
+		tamper func(rows []repository.AuditLog) []repository.AuditLog
+	}{
+		{"truncated tail", func(rows []repository.AuditLog) []repository.AuditLog {
+			return rows[:2]
+		}},
+		// Цепочка без checkpoint сходится, но запись 3 теперь с другим хешем
+		{"rehashed chain", func(rows []repository.AuditLog) []repository.AuditLog {
+			rows[1].Actor = "someone-else"
+			for i := 1; i < len(rows); i++ {
+				rows[i].PrevHash = rows[i-1].Hash
+				rows[i].Hash = audit.Hash(rows[i])
+			}
+			return rows
+		}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &fakeLog{}
+			svc := audit.New(newRepo(t, log), zap.NewNop())
+			record(t, svc, 4)
+			cp := &audit.Checkpoint{ID: log.rows[2].ID, Hash: log.rows[2].Hash}
+
+			_, err := svc.Verify(context.Background(), cp)
+			require.NoError(t, err)
+
+			log.rows = tt.tamper(log.rows)
+			_, err = svc.Verify(context.Background(), nil)
+			require.NoError(t, err)
+			_, err = svc.Verify(context.Background(), cp)
+			require.ErrorIs(t, err, audit.ErrChainBroken)
+		})
+	}
+}
+
+func TestExport_VerifiesOffline(t *testing.T) {
+	log := &fakeLog{}
+	svc := audit.New(newRepo(t, log), zap.NewNop())
+	record(t, svc, 5)
+
+	var full bytes.Buffer
+	n, err := svc.Export(context.Background(), &full, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	result, err := audit.VerifyExport(bytes.NewReader(full.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Entries)
+	assert.True(t, result.Complete)
+
+	var tail bytes.Buffer
+	_, err = svc.Export(context.Background(), &tail, 2)
+	require.NoError(t, err)
+	result, err = audit.VerifyExport(&tail, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.FirstID)
+	assert.False(t, result.Complete)
+
+	tampered := strings.Replace(full.String(), `"actor":"key-1"`, `"actor":"key-2"`, 1)
+	_, err = audit.VerifyExport(strings.NewReader(tampered), nil)
+	assert.ErrorIs(t, err, audit.ErrChainBroken)
+}
+
+func TestScope_FirstDescriptionWins(t *testing.T) {
+	ctx, scope := audit.WithScope(auth.WithPrincipal(context.Background(), auth.Principal{ID: "ops"}))
+	walletID := uuid.New()
+
+	got, ok := audit.ScopeFrom(ctx)
+	require.True(t, ok)
+	got.Describe("wallet.create", &walletID, nil)
+	got.Describe("wallet.operation.deposit", nil, assert.AnError)
+
+	entry, described := scope.Entry()
+	require.True(t, described)
+	assert.Equal(t, "wallet.create", entry.Action)
+	assert.Equal(t, &walletID, entry.WalletID)
+	assert.Equal(t, audit.OutcomeOK, entry.Outcome)
+	assert.Equal(t, "ops", audit.Actor(ctx))
+}
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+// GenesisHash - prev_hash первой записи журнала
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Verification - итог проверки цепочки
+type Verification struct {
+	Entries int64  `json:"entries"`
+	FirstID int64  `json:"first_id"`
+	LastID  int64  `json:"last_id"`
+	Head    string `json:"head"`
+	// Complete - проверка начата с первой записи журнала
+	Complete bool `json:"complete"`
+}
+
+// Checkpoint - запись журнала, сохраненная вне базы (audit checkpoint). Цепочку
+// хешей можно пересчитать целиком или обрезать хвост, но так, чтобы запись ID
+// осталась с хешем Hash, - нельзя.
+type Checkpoint struct {
+	ID   int64  `json:"id"`
+	Hash string `json:"hash"`
+}
+
+// chained - поля записи, которые покрывает хеш, в фиксированном порядке.
+// id не входит: пропуски последовательности после отката транзакции не ошибка.
+type chained struct {
+	PrevHash      string     `json:"prev_hash"`
+	OccurredAt    string     `json:"occurred_at"`
+	Actor         string     `json:"actor"`
+	Action        string     `json:"action"`
+	Route         string     `json:"route"`
+	WalletID      *uuid.UUID `json:"wallet_id"`
+	PayloadDigest string     `json:"payload_digest"`
+	Outcome       string     `json:"outcome"`
+	StatusCode    *int32     `json:"status_code"`
+	ClientIP      string     `json:"client_ip"`
+}
+
+// Hash считает хеш записи вместе с хешем предыдущей
+func Hash(row repository.AuditLog) string {
+	data, _ := json.Marshal(chained{
+		PrevHash:      row.PrevHash,
+		OccurredAt:    row.OccurredAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		Actor:         row.Actor,
+		Action:        row.Action,
+		Route:         row.Route,
+		WalletID:      row.WalletID,
+		PayloadDigest: row.PayloadDigest,
+		Outcome:       row.Outcome,
+		StatusCode:    row.StatusCode,
+		ClientIP:      row.ClientIp,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain проверяет записи по порядку: каждая ссылается на хеш предыдущей,
+// а ее собственный хеш совпадает с содержимым.
+type Chain struct {
+	result     Verification
+	checkpoint *Checkpoint
+	reached    bool
+}
+
+// NewChain начинает проверку с головы head: GenesisHash для всего журнала или
+// prev_hash первой записи частичной выгрузки.
+func NewChain(head string) *Chain {
+	return &Chain{result: Verification{Head: head, Complete: head == GenesisHash}}
+}
+
+// Expect требует, чтобы цепочка дошла до записи checkpoint и совпала с ней. nil - без сверки
+func (c *Chain) Expect(checkpoint *Checkpoint) *Chain {
+	c.checkpoint = checkpoint
+	return c
+}
+
+func (c *Chain) Append(row repository.AuditLog) error {
+	switch {
+	case c.result.Entries > 0 && row.ID <= c.result.LastID:
+		return fmt.Errorf("%w: entry %d follows entry %d", ErrChainBroken, row.ID, c.result.LastID)
+	case row.PrevHash != c.result.Head:
+		return fmt.Errorf("%w: entry %d does not link to the previous entry", ErrChainBroken, row.ID)
+	case Hash(row) != row.Hash:
+		return fmt.Errorf("%w: entry %d content does not match its hash", ErrChainBroken, row.ID)
+	}
+	if cp := c.checkpoint; cp != nil && !c.reached {
+		switch {
+		case row.ID == cp.ID && row.Hash != cp.Hash:
+			return fmt.Errorf("%w: entry %d does not match the checkpoint", ErrChainBroken, row.ID)
+		case row.ID > cp.ID:
+			return fmt.Errorf("%w: checkpoint entry %d is missing", ErrChainBroken, cp.ID)
+		}
+		c.reached = row.ID == cp.ID
+	}
+	if c.result.Entries == 0 {
+		c.result.FirstID = row.ID
+	}
+	c.result.Entries++
+	c.result.LastID = row.ID
+	c.result.Head = row.Hash
+	return nil
+}
+
+func (c *Chain) Result() Verification {
+	return c.result
+}
+
+// Finish завершает проверку: журнал, обрезанный до записи checkpoint, тоже нарушен
+func (c *Chain) Finish() (Verification, error) {
+	if c.checkpoint != nil && !c.reached {
+		return c.result, fmt.Errorf("%w: log ends before checkpoint entry %d", ErrChainBroken, c.checkpoint.ID)
+	}
+	return c.result, nil
+}
+
+// VerifyExport проверяет выгрузку Export. Цепочка сверяется от prev_hash первой
+// записи; начинается ли выгрузка с начала журнала, показывает Complete.
+// checkpoint, если задан, должен попасть в выгрузку.
+func VerifyExport(r io.Reader, checkpoint *Checkpoint) (Verification, error) {
+	var chain *Chain
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var row repository.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return Verification{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		if chain == nil {
+			chain = NewChain(row.PrevHash).Expect(checkpoint)
+		}
+		if err := chain.Append(row); err != nil {
+			return chain.Result(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Verification{}, err
+	}
+	if chain == nil {
+		chain = NewChain(GenesisHash).Expect(checkpoint)
+	}
+	return chain.Finish()
+}
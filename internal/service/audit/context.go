@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/auth"
+)
+
+type scopeKey struct{}
+
+// Scope собирает запись о вызове по мере прохождения слоев: транспорт знает
+// маршрут, адрес клиента и тело запроса, сервис - действие, кошелек и исход.
+// Запись пишет транспорт, когда ответ уже известен.
+type Scope struct {
+	described bool
+	entry     Entry
+}
+
+// WithScope открывает Scope для вызова, который придет с ctx
+func WithScope(ctx context.Context) (context.Context, *Scope) {
+	s := &Scope{}
+	return context.WithValue(ctx, scopeKey{}, s), s
+}
+
+// ScopeFrom возвращает Scope транспорта, если он есть
+func ScopeFrom(ctx context.Context) (*Scope, bool) {
+	s, ok := ctx.Value(scopeKey{}).(*Scope)
+	return s, ok
+}
+
+// Describe отмечает вызов сервиса. Учитывается первый вызов: вложенные вызовы
+// того же запроса его не перезаписывают.
+func (s *Scope) Describe(action string, walletID *uuid.UUID, err error) {
+	if s.described {
+		return
+	}
+	s.described = true
+	s.entry.Action = action
+	s.entry.WalletID = walletID
+	s.entry.Outcome = Outcome(err)
+}
+
+// Entry возвращает то, что описал сервис; false - сервис не вызывался
+func (s *Scope) Entry() (Entry, bool) {
+	return s.entry, s.described
+}
+
+// Actor - клиент из контекста или ActorAnonymous
+func Actor(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok && p.ID != "" {
+		return p.ID
+	}
+	return ActorAnonymous
+}
+
+// Outcome - OutcomeOK или текст ошибки
+func Outcome(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return OutcomeOK
+}
+
+// Digest - sha256 тела запроса. Само тело в журнал не попадает
+func Digest(payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
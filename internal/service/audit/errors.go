@@ -0,0 +1,5 @@
+package audit
+
+import "errors"
+
+var ErrChainBroken = errors.New("audit log hash chain is broken")
@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var csvHeader = []string{"base", "quote", "rate", "effective_from"}
+
+// ParseCSV читает курсы в формате "base,quote,rate,effective_from" с заголовком.
+// effective_from в RFC3339, пустое значение означает "с текущего момента".
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty csv", ErrInvalidRate)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+	}
+	for i, col := range csvHeader {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), col) {
+			return nil, fmt.Errorf("%w: csv header must be %s", ErrInvalidRate, strings.Join(csvHeader, ","))
+		}
+	}
+
+	var rates []Rate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+		}
+
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[2]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRate, line, err)
+		}
+		var effectiveFrom time.Time
+		if v := strings.TrimSpace(record[3]); v != "" {
+			if effectiveFrom, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRate, line, err)
+			}
+		}
+
+		rates = append(rates, Rate{
+			Base:          record[0],
+			Quote:         record[1],
+			Rate:          rate,
+			EffectiveFrom: effectiveFrom,
+		})
+	}
+	return rates, nil
+}
@@ -0,0 +1,8 @@
+package fx
+
+import "errors"
+
+var (
+	ErrInvalidRate  = errors.New("invalid exchange rate")
+	ErrRateNotFound = errors.New("exchange rate not found")
+)
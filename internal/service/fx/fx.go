@@ -0,0 +1,155 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+// RateScale - максимальная точность курса, совпадает с NUMERIC(20, 10) в fx_rates
+const RateScale int32 = 10
+
+type FxService interface {
+	UploadRates(ctx context.Context, rates []Rate) (UploadResult, error)
+	GetRate(ctx context.Context, base, quote string, at time.Time) (repository.FxRate, error)
+}
+
+// Rate - курс base->quote: 1 единица base стоит Rate единиц quote начиная с EffectiveFrom
+type Rate struct {
+	Base          string          `json:"base"`
+	Quote         string          `json:"quote"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+}
+
+// RejectedRate - курс из загрузки, который не сохранен: на эту дату курс пары уже опубликован
+type RejectedRate struct {
+	// Row - номер курса в загрузке, начиная с 1
+	Row  int
+	Rate Rate
+}
+
+// UploadResult - итог загрузки: сохраненные курсы и отклоненные конфликтующие
+type UploadResult struct {
+	Saved    []repository.FxRate
+	Rejected []RejectedRate
+}
+
+type fxService struct {
+	repo   repository.Repository
+	logger logger.Logger
+}
+
+func New(repo repository.Repository, log logger.Logger) FxService {
+	return &fxService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// UploadRates проверяет весь набор курсов и сохраняет его одной транзакцией.
+// Опубликованные курсы неизменяемы: конвертации уже посчитаны по ним, поэтому курс
+// на занятую дату не перезаписывается, а попадает в Rejected.
+func (s *fxService) UploadRates(ctx context.Context, rates []Rate) (UploadResult, error) {
+	if len(rates) == 0 {
+		return UploadResult{}, fmt.Errorf("%w: empty rate list", ErrInvalidRate)
+	}
+	for i := range rates {
+		if err := normalizeRate(&rates[i]); err != nil {
+			return UploadResult{}, fmt.Errorf("%w: row %d: %w", ErrInvalidRate, i+1, err)
+		}
+	}
+
+	var result UploadResult
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		result = UploadResult{Saved: make([]repository.FxRate, 0, len(rates))}
+		for i, r := range rates {
+			row, err := q.CreateFxRate(ctx, repository.CreateFxRateParams{
+				ID:            uuid.New(),
+				BaseCurrency:  r.Base,
+				QuoteCurrency: r.Quote,
+				Rate:          r.Rate,
+				EffectiveFrom: r.EffectiveFrom,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				result.Rejected = append(result.Rejected, RejectedRate{Row: i + 1, Rate: r})
+				continue
+			}
+			if err != nil {
+				s.logger.Error("failed to create exchange rate", zap.String("base", r.Base), zap.String("quote", r.Quote), zap.Error(err))
+				return err
+			}
+			result.Saved = append(result.Saved, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return UploadResult{}, err
+	}
+
+	if len(result.Rejected) > 0 {
+		s.logger.Warn("exchange rates already published", zap.Int("rejected", len(result.Rejected)))
+	}
+	s.logger.Info("exchange rates uploaded", zap.Int("count", len(result.Saved)))
+	return result, nil
+}
+
+func (s *fxService) GetRate(ctx context.Context, base, quote string, at time.Time) (repository.FxRate, error) {
+	base, err := money.NormalizeCurrency(base)
+	if err != nil {
+		return repository.FxRate{}, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+	}
+	quote, err = money.NormalizeCurrency(quote)
+	if err != nil {
+		return repository.FxRate{}, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	rate, err := s.repo.GetFxRateAt(ctx, repository.GetFxRateAtParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		At:            at,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.FxRate{}, ErrRateNotFound
+		}
+		s.logger.Error("failed to get exchange rate", zap.Error(err))
+		return repository.FxRate{}, err
+	}
+	return rate, nil
+}
+
+func normalizeRate(r *Rate) error {
+	var err error
+	if r.Base, err = money.NormalizeCurrency(r.Base); err != nil {
+		return err
+	}
+	if r.Quote, err = money.NormalizeCurrency(r.Quote); err != nil {
+		return err
+	}
+	if r.Base == r.Quote {
+		return errors.New("base and quote currencies must differ")
+	}
+	if !r.Rate.IsPositive() {
+		return errors.New("rate must be positive")
+	}
+	if !r.Rate.Equal(r.Rate.Truncate(RateScale)) {
+		return fmt.Errorf("rate has more than %d decimal places", RateScale)
+	}
+	if r.EffectiveFrom.IsZero() {
+		r.EffectiveFrom = time.Now()
+	}
+	return nil
+}
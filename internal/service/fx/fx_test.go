@@ -0,0 +1,130 @@
+package fx_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/fx"
+)
+
+func newRepo(t *testing.T) *mocks.MockRepository {
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error {
+			return fn(repo)
+		}).AnyTimes()
+	return repo
+}
+
+func TestUploadRates_Success(t *testing.T) {
+	repo := newRepo(t)
+	repo.EXPECT().CreateFxRate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateFxRateParams) (repository.FxRate, error) {
+			assert.Equal(t, "USD", arg.BaseCurrency)
+			assert.Equal(t, "EUR", arg.QuoteCurrency)
+			assert.False(t, arg.EffectiveFrom.IsZero())
+			return repository.FxRate{ID: arg.ID, BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency, Rate: arg.Rate}, nil
+		})
+
+	svc := fx.New(repo, zap.NewNop())
+	result, err := svc.UploadRates(context.Background(), []fx.Rate{
+		{Base: "usd", Quote: "eur", Rate: decimal.RequireFromString("0.9213")},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, result.Saved, 1)
+	assert.Equal(t, "0.9213", result.Saved[0].Rate.String())
+	assert.Empty(t, result.Rejected)
+}
+
+func TestUploadRates_PublishedRateIsNotOverwritten(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := newRepo(t)
+	repo.EXPECT().CreateFxRate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateFxRateParams) (repository.FxRate, error) {
+			if arg.QuoteCurrency == "EUR" {
+				return repository.FxRate{}, pgx.ErrNoRows
+			}
+			return repository.FxRate{ID: arg.ID, BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency, Rate: arg.Rate}, nil
+		}).Times(2)
+
+	svc := fx.New(repo, zap.NewNop())
+	result, err := svc.UploadRates(context.Background(), []fx.Rate{
+		{Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("0.95"), EffectiveFrom: at},
+		{Base: "USD", Quote: "JPY", Rate: decimal.RequireFromString("151.2"), EffectiveFrom: at},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, result.Saved, 1)
+	assert.Equal(t, "JPY", result.Saved[0].QuoteCurrency)
+	require.Len(t, result.Rejected, 1)
+	assert.Equal(t, 1, result.Rejected[0].Row)
+	assert.Equal(t, "0.95", result.Rejected[0].Rate.Rate.String())
+}
+
+func TestUploadRates_Invalid(t *testing.T) {
+	cases := map[string]fx.Rate{
+		"same currency":  {Base: "USD", Quote: "USD", Rate: decimal.NewFromInt(1)},
+		"unsupported":    {Base: "USD", Quote: "XXX", Rate: decimal.NewFromInt(1)},
+		"non positive":   {Base: "USD", Quote: "EUR", Rate: decimal.Zero},
+		"scale exceeded": {Base: "USD", Quote: "EUR", Rate: decimal.RequireFromString("0.12345678901")},
+		"negative rate":  {Base: "USD", Quote: "EUR", Rate: decimal.NewFromInt(-2)},
+	}
+	for name, rate := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := fx.New(newRepo(t), zap.NewNop())
+			_, err := svc.UploadRates(context.Background(), []fx.Rate{rate})
+			require.ErrorIs(t, err, fx.ErrInvalidRate)
+		})
+	}
+}
+
+func TestGetRate_NotFound(t *testing.T) {
+	repo := newRepo(t)
+	repo.EXPECT().GetFxRateAt(gomock.Any(), gomock.Any()).Return(repository.FxRate{}, pgx.ErrNoRows)
+
+	svc := fx.New(repo, zap.NewNop())
+	_, err := svc.GetRate(context.Background(), "USD", "EUR", time.Time{})
+
+	require.ErrorIs(t, err, fx.ErrRateNotFound)
+}
+
+func TestParseCSV(t *testing.T) {
+	input := "base,quote,rate,effective_from\n" +
+		"USD,EUR,0.9213,2026-01-01T00:00:00Z\n" +
+		"EUR,JPY,162.5,\n"
+
+	rates, err := fx.ParseCSV(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "0.9213", rates[0].Rate.String())
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), rates[0].EffectiveFrom)
+	assert.True(t, rates[1].EffectiveFrom.IsZero())
+}
+
+func TestParseCSV_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":        "",
+		"bad header":   "from,to,rate,at\nUSD,EUR,1,\n",
+		"bad rate":     "base,quote,rate,effective_from\nUSD,EUR,abc,\n",
+		"bad date":     "base,quote,rate,effective_from\nUSD,EUR,1,yesterday\n",
+		"wrong fields": "base,quote,rate,effective_from\nUSD,EUR\n",
+	}
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := fx.ParseCSV(strings.NewReader(input))
+			require.ErrorIs(t, err, fx.ErrInvalidRate)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+// ExpectedSchemaVersion - последняя миграция из sql/schema, с которой собран сервис
+const ExpectedSchemaVersion int32 = 24
+
+const DefaultCheckTimeout = 2 * time.Second
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Имена зависимостей в отчете готовности
+const (
+	CheckDatabase   = "database"
+	CheckMigrations = "migrations"
+	CheckDraining   = "draining"
+)
+
+type HealthService interface {
+	// Live - процесс жив и отвечает; зависимости не проверяются
+	Live() Report
+	// Ready проверяет, можно ли направлять на экземпляр трафик
+	Ready(ctx context.Context) Report
+	// Drain переводит готовность в down до конца жизни процесса
+	Drain()
+}
+
+// Pinger - проверка соединения с базой, ее реализует *pgxpool.Pool
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks,omitempty"`
+}
+
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type Check struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	// Только для migrations
+	Version  *int32 `json:"version,omitempty"`
+	Expected *int32 `json:"expected,omitempty"`
+}
+
+type healthService struct {
+	db       Pinger
+	repo     repository.Querier
+	logger   logger.Logger
+	timeout  time.Duration
+	expected int32
+	draining atomic.Bool
+}
+
+func New(db Pinger, repo repository.Querier, log logger.Logger, opts ...Option) HealthService {
+	s := &healthService{
+		db:       db,
+		repo:     repo,
+		logger:   log,
+		timeout:  DefaultCheckTimeout,
+		expected: ExpectedSchemaVersion,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *healthService) Live() Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready запускает проверки параллельно, каждую со своим таймаутом,
+// поэтому зависшая база отвечает не дольше timeout
+func (s *healthService) Ready(ctx context.Context) Report {
+	checks := map[string]func(context.Context) Check{
+		CheckDatabase:   s.checkDatabase,
+		CheckMigrations: s.checkMigrations,
+	}
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Check, len(checks)+1)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			start := time.Now()
+			result := check(checkCtx)
+			result.Duration = time.Since(start).Round(time.Microsecond).String()
+
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	report.Checks[CheckDraining] = s.checkDraining()
+	for name, check := range report.Checks {
+		if check.Status != StatusUp {
+			report.Status = StatusDown
+			if name != CheckDraining {
+				s.logger.Warn("readiness check failed", zap.String("check", name), zap.String("error", check.Error))
+			}
+		}
+	}
+	return report
+}
+
+func (s *healthService) Drain() {
+	if s.draining.CompareAndSwap(false, true) {
+		s.logger.Info("draining: readiness is down")
+	}
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) Check {
+	if err := s.db.Ping(ctx); err != nil {
+		return down(err)
+	}
+	return Check{Status: StatusUp}
+}
+
+// checkMigrations пропускает схему новее ожидаемой: миграции обратно совместимы,
+// и при раскатке старые экземпляры должны обслуживать трафик до замены
+func (s *healthService) checkMigrations(ctx context.Context) Check {
+	version, err := s.repo.GetSchemaVersion(ctx)
+	if err != nil {
+		return down(err)
+	}
+	expected := s.expected
+	check := Check{Status: StatusUp, Version: &version, Expected: &expected}
+	if version < expected {
+		check.Status = StatusDown
+		check.Error = fmt.Sprintf("schema version %d is behind expected %d", version, expected)
+	}
+	return check
+}
+
+func (s *healthService) checkDraining() Check {
+	if s.draining.Load() {
+		return Check{Status: StatusDown, Error: "shutting down"}
+	}
+	return Check{Status: StatusUp}
+}
+
+func down(err error) Check {
+	return Check{Status: StatusDown, Error: err.Error()}
+}
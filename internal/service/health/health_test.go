@@ -0,0 +1,107 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/service/health"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+var pingOK = pingerFunc(func(context.Context) error { return nil })
+
+func TestReady_AllUp(t *testing.T) {
+	repo := mocks.NewMockQuerier(gomock.NewController(t))
+	repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(health.ExpectedSchemaVersion, nil)
+	svc := health.New(pingOK, repo, zap.NewNop())
+
+	report := svc.Ready(context.Background())
+
+	assert.True(t, report.Up())
+	require.Len(t, report.Checks, 3)
+	for name, check := range report.Checks {
+		assert.Equal(t, health.StatusUp, check.Status, name)
+	}
+	assert.Equal(t, health.ExpectedSchemaVersion, *report.Checks[health.CheckMigrations].Version)
+	assert.NotEmpty(t, report.Checks[health.CheckDatabase].Duration)
+}
+
+func TestReady_DatabaseTimeout(t *testing.T) {
+	repo := mocks.NewMockQuerier(gomock.NewController(t))
+	repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(health.ExpectedSchemaVersion, nil)
+	hung := pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	svc := health.New(hung, repo, zap.NewNop(), health.WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	report := svc.Ready(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second, "зависшая база не держит проверку дольше таймаута")
+	assert.False(t, report.Up())
+	db := report.Checks[health.CheckDatabase]
+	assert.Equal(t, health.StatusDown, db.Status)
+	assert.Contains(t, db.Error, context.DeadlineExceeded.Error())
+	assert.Equal(t, health.StatusUp, report.Checks[health.CheckMigrations].Status)
+}
+
+func TestReady_SchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int32
+		err     error
+		up      bool
+	}{
+		{"expected", 17, nil, true},
+		{"newer schema during rollout", 18, nil, true},
+		{"behind", 16, nil, false},
+		{"no migrations table", 0, errors.New(`relation "schema_migrations" does not exist`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockQuerier(gomock.NewController(t))
+			repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(tt.version, tt.err)
+			svc := health.New(pingOK, repo, zap.NewNop(), health.WithExpectedSchemaVersion(17))
+
+			report := svc.Ready(context.Background())
+
+			assert.Equal(t, tt.up, report.Up())
+			check := report.Checks[health.CheckMigrations]
+			if tt.up {
+				assert.Equal(t, health.StatusUp, check.Status)
+			} else {
+				assert.Equal(t, health.StatusDown, check.Status)
+				assert.NotEmpty(t, check.Error)
+			}
+		})
+	}
+}
+
+func TestDrain_FailsReadinessButNotLiveness(t *testing.T) {
+	repo := mocks.NewMockQuerier(gomock.NewController(t))
+	repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(health.ExpectedSchemaVersion, nil).Times(2)
+	svc := health.New(pingOK, repo, zap.NewNop())
+	require.True(t, svc.Ready(context.Background()).Up())
+
+	svc.Drain()
+	svc.Drain()
+
+	report := svc.Ready(context.Background())
+	assert.False(t, report.Up())
+	assert.Equal(t, health.StatusDown, report.Checks[health.CheckDraining].Status)
+	assert.Equal(t, health.StatusUp, report.Checks[health.CheckDatabase].Status)
+	assert.True(t, svc.Live().Up())
+}
@@ -0,0 +1,21 @@
+package health
+
+import "time"
+
+type Option func(*healthService)
+
+// WithTimeout ограничивает каждую проверку готовности; ноль оставляет значение по умолчанию
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *healthService) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// WithExpectedSchemaVersion задает ожидаемую версию схемы вместо ExpectedSchemaVersion
+func WithExpectedSchemaVersion(version int32) Option {
+	return func(s *healthService) {
+		s.expected = version
+	}
+}
@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+const (
+	EventWalletCreated = "wallet.created"
+	// Кошелек заморожен, разморожен или закрыт
+	EventStatusChanged = "wallet.status_changed"
+	// Операция отклонена из-за нехватки средств. Журнал при этом не меняется.
+	EventInsufficientFunds = "wallet.insufficient_funds"
+	// Тип события операции - "wallet." + тип операции в нижнем регистре: wallet.deposit, wallet.withdraw, ...
+	eventTypePrefix = "wallet."
+)
+
+// Event - событие об изменении кошелька, как его получают внешние потребители
+type Event struct {
+	ID            uuid.UUID        `json:"id"`
+	Type          string           `json:"type"`
+	WalletID      uuid.UUID        `json:"wallet_id"`
+	TransactionID *uuid.UUID       `json:"transaction_id,omitempty"`
+	Operation     string           `json:"operation,omitempty"`
+	Amount        *decimal.Decimal `json:"amount,omitempty"`
+	Balance       decimal.Decimal  `json:"balance"`
+	Currency      string           `json:"currency"`
+	OccurredAt    time.Time        `json:"occurred_at"`
+	// Status и PreviousStatus заполнены только у wallet.status_changed
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+}
+
+// OperationEventType возвращает тип события для операции журнала
+func OperationEventType(opType string) string {
+	return eventTypePrefix + strings.ToLower(opType)
+}
+
+// WalletCreated строит событие создания кошелька
+func WalletCreated(w repository.Wallet) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       EventWalletCreated,
+		WalletID:   w.ID,
+		Balance:    w.Balance,
+		Currency:   w.Currency,
+		OccurredAt: w.CreatedAt,
+	}
+}
+
+// BalanceChanged строит событие по записи журнала и состоянию кошелька после нее
+func BalanceChanged(w repository.Wallet, t repository.WalletTransaction) Event {
+	return Event{
+		ID:            uuid.New(),
+		Type:          OperationEventType(t.Type),
+		WalletID:      w.ID,
+		TransactionID: &t.ID,
+		Amount:        &t.Amount,
+		Balance:       w.Balance,
+		Currency:      w.Currency,
+		OccurredAt:    t.CreatedAt,
+	}
+}
+
+// StatusChanged строит событие смены статуса по кошельку после обновления
+func StatusChanged(w repository.Wallet, from string) Event {
+	return Event{
+		ID:             uuid.New(),
+		Type:           EventStatusChanged,
+		WalletID:       w.ID,
+		Balance:        w.Balance,
+		Currency:       w.Currency,
+		Status:         w.Status,
+		PreviousStatus: from,
+		OccurredAt:     w.UpdatedAt,
+	}
+}
+
+// InsufficientFunds строит событие отклоненной операции. Amount - запрошенная сумма,
+// Balance - баланс кошелька на момент отказа.
+func InsufficientFunds(w repository.Wallet, opType string, amount decimal.Decimal) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       EventInsufficientFunds,
+		WalletID:   w.ID,
+		Operation:  opType,
+		Amount:     &amount,
+		Balance:    w.Balance,
+		Currency:   w.Currency,
+		OccurredAt: time.Now(),
+	}
+}
+
+// Params готовит строку outbox для записи в той же транзакции, что и изменение кошелька
+func (e Event) Params() (repository.CreateWalletEventParams, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return repository.CreateWalletEventParams{}, err
+	}
+	return repository.CreateWalletEventParams{
+		ID:        e.ID,
+		WalletID:  e.WalletID,
+		EventType: e.Type,
+		Payload:   payload,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	DefaultBatchSize = 100
+	// Пауза перед повторной отправкой растет как 2^attempts секунд, но не больше maxRetryDelay
+	maxRetryDelay = 5 * time.Minute
+	// Аренда пачки должна пережить отправку всех ее событий, иначе их возьмет другой экземпляр
+	leaseDuration = 5 * time.Minute
+)
+
+type OutboxService interface {
+	// RelayPending отправляет готовые к отправке события и возвращает число доставленных
+	RelayPending(ctx context.Context, pub Publisher) (int, error)
+}
+
+type outboxService struct {
+	repo      repository.Repository
+	logger    logger.Logger
+	batchSize int32
+}
+
+func New(repo repository.Repository, log logger.Logger) OutboxService {
+	return &outboxService{
+		repo:      repo,
+		logger:    log,
+		batchSize: DefaultBatchSize,
+	}
+}
+
+// RelayPending берет пачку событий в аренду (locked_until) короткой транзакцией с
+// FOR UPDATE SKIP LOCKED, поэтому несколько экземпляров релея не делят одно событие.
+// Отправка идет уже без транзакции и блокировок строк, а результаты фиксируются
+// второй короткой транзакцией. Если релей упал посреди пачки, аренда истечет и
+// события уйдут повторно - доставка "хотя бы один раз".
+func (s *outboxService) RelayPending(ctx context.Context, pub Publisher) (int, error) {
+	var events []repository.WalletEvent
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		var err error
+		events, err = q.ClaimPendingWalletEvents(ctx, repository.ClaimPendingWalletEventsParams{
+			LockedUntil: time.Now().Add(leaseDuration),
+			BatchSize:   s.batchSize,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to claim pending wallet events", zap.Error(err))
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+	// UPDATE ... RETURNING не гарантирует порядок, а потребителям важен порядок создания
+	slices.SortFunc(events, func(a, b repository.WalletEvent) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	results := make([]error, len(events))
+	for i, row := range events {
+		results[i] = s.publish(ctx, pub, row)
+	}
+
+	published := 0
+	err = s.repo.WithTx(ctx, func(q repository.Querier) error {
+		published = 0
+		for i, row := range events {
+			if err := s.mark(ctx, q, row, results[i]); err != nil {
+				return err
+			}
+			if results[i] == nil {
+				published++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+func (s *outboxService) publish(ctx context.Context, pub Publisher, row repository.WalletEvent) error {
+	var event Event
+	if err := json.Unmarshal(row.Payload, &event); err != nil {
+		return err
+	}
+	return pub.Publish(ctx, event)
+}
+
+func (s *outboxService) mark(ctx context.Context, q repository.Querier, row repository.WalletEvent, pubErr error) error {
+	if pubErr == nil {
+		if err := q.MarkWalletEventPublished(ctx, row.ID); err != nil {
+			s.logger.Error("failed to mark wallet event published", zap.String("eventId", row.ID.String()), zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	attempts := row.Attempts + 1
+	lastError := pubErr.Error()
+	s.logger.Warn("failed to publish wallet event",
+		zap.String("eventId", row.ID.String()),
+		zap.Int32("attempts", attempts),
+		zap.Error(pubErr),
+	)
+	err := q.MarkWalletEventFailed(ctx, repository.MarkWalletEventFailedParams{
+		ID:            row.ID,
+		NextAttemptAt: time.Now().Add(RetryDelay(attempts)),
+		LastError:     &lastError,
+	})
+	if err != nil {
+		s.logger.Error("failed to mark wallet event failed", zap.String("eventId", row.ID.String()), zap.Error(err))
+	}
+	return err
+}
+
+// RetryDelay - пауза перед следующей попыткой после attempts неудачных
+func RetryDelay(attempts int32) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 16 {
+		return maxRetryDelay
+	}
+	delay := time.Duration(1<<attempts) * time.Second
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,166 @@
+package outbox_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+)
+
+type publisherFunc func(ctx context.Context, event outbox.Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, event outbox.Event) error {
+	return f(ctx, event)
+}
+
+// newRepo возвращает мок репозитория; inTx показывает, идет ли сейчас транзакция
+func newRepo(t *testing.T) (*mocks.MockRepository, *bool) {
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	inTx := new(bool)
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error {
+			*inTx = true
+			defer func() { *inTx = false }()
+			return fn(repo)
+		}).AnyTimes()
+	return repo, inTx
+}
+
+func pendingRow(t *testing.T, attempts int32) repository.WalletEvent {
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(10), Currency: "USD", CreatedAt: time.Now()}
+	params, err := outbox.WalletCreated(w).Params()
+	require.NoError(t, err)
+	return repository.WalletEvent{
+		ID:        params.ID,
+		WalletID:  params.WalletID,
+		EventType: params.EventType,
+		Payload:   params.Payload,
+		Attempts:  attempts,
+	}
+}
+
+func TestRelayPending_MarksPublished(t *testing.T) {
+	row := pendingRow(t, 0)
+	repo, inTx := newRepo(t)
+	repo.EXPECT().ClaimPendingWalletEvents(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.ClaimPendingWalletEventsParams) ([]repository.WalletEvent, error) {
+			assert.Equal(t, int32(outbox.DefaultBatchSize), arg.BatchSize)
+			assert.True(t, arg.LockedUntil.After(time.Now()))
+			return []repository.WalletEvent{row}, nil
+		})
+	repo.EXPECT().MarkWalletEventPublished(gomock.Any(), row.ID).Return(nil)
+
+	var got outbox.Event
+	pub := publisherFunc(func(_ context.Context, e outbox.Event) error {
+		// Отправка не должна держать транзакцию и блокировки строк
+		assert.False(t, *inTx)
+		got = e
+		return nil
+	})
+
+	n, err := outbox.New(repo, zap.NewNop()).RelayPending(context.Background(), pub)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, row.ID, got.ID)
+	assert.Equal(t, outbox.EventWalletCreated, got.Type)
+}
+
+func TestRelayPending_SchedulesRetryOnFailure(t *testing.T) {
+	row := pendingRow(t, 2)
+	repo, _ := newRepo(t)
+	repo.EXPECT().ClaimPendingWalletEvents(gomock.Any(), gomock.Any()).Return([]repository.WalletEvent{row}, nil)
+	repo.EXPECT().MarkWalletEventFailed(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.MarkWalletEventFailedParams) error {
+			assert.Equal(t, row.ID, arg.ID)
+			require.NotNil(t, arg.LastError)
+			assert.Equal(t, "broker unavailable", *arg.LastError)
+			assert.WithinDuration(t, time.Now().Add(8*time.Second), arg.NextAttemptAt, time.Second)
+			return nil
+		})
+
+	pub := publisherFunc(func(context.Context, outbox.Event) error {
+		return errors.New("broker unavailable")
+	})
+
+	n, err := outbox.New(repo, zap.NewNop()).RelayPending(context.Background(), pub)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestRelayPending_NothingClaimed(t *testing.T) {
+	repo, _ := newRepo(t)
+	repo.EXPECT().ClaimPendingWalletEvents(gomock.Any(), gomock.Any()).Return([]repository.WalletEvent{}, nil)
+
+	pub := publisherFunc(func(context.Context, outbox.Event) error {
+		t.Fatal("nothing to publish")
+		return nil
+	})
+
+	n, err := outbox.New(repo, zap.NewNop()).RelayPending(context.Background(), pub)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, outbox.RetryDelay(1))
+	assert.Equal(t, 64*time.Second, outbox.RetryDelay(6))
+	assert.Equal(t, 5*time.Minute, outbox.RetryDelay(12))
+	assert.Equal(t, 5*time.Minute, outbox.RetryDelay(100))
+	assert.Equal(t, 2*time.Second, outbox.RetryDelay(0))
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	event := outbox.Event{ID: uuid.New(), Type: "wallet.deposit", WalletID: uuid.New()}
+
+	require.NoError(t, outbox.NewWriterPublisher(&buf).Publish(context.Background(), event))
+
+	var got outbox.Event
+	require.NoError(t, json.Unmarshal(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), &got))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	event := outbox.Event{ID: uuid.New(), Type: "wallet.withdraw", WalletID: uuid.New()}
+
+	t.Run("success", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, event.ID.String(), r.Header.Get("X-Event-Id"))
+			assert.Equal(t, "wallet.withdraw", r.Header.Get("X-Event-Type"))
+			var got outbox.Event
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			assert.Equal(t, event.WalletID, got.WalletID)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, outbox.NewHTTPPublisher(srv.URL, time.Second).Publish(context.Background(), event))
+	})
+
+	t.Run("non 2xx is an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		require.Error(t, outbox.NewHTTPPublisher(srv.URL, time.Second).Publish(context.Background(), event))
+	})
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Publisher доставляет событие потребителям. Ошибка означает, что событие
+// будет отправлено повторно, поэтому потребители должны быть идемпотентны по Event.ID.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// MultiPublisher отправляет событие всем публикаторам по очереди. Ошибка любого из них
+// приводит к повторной отправке всем, поэтому каждый из них должен переносить дубли.
+type MultiPublisher []Publisher
+
+func (m MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriterPublisher пишет события построчно в JSON, например в stdout или файл
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+// HTTPPublisher отправляет событие POST-запросом с JSON-телом, успех - любой 2xx
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
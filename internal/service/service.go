@@ -1,17 +1,63 @@
 package service
 
 import (
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
 	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/apikey"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+	"tryingMicro/OrderAccepter/internal/service/fx"
+	"tryingMicro/OrderAccepter/internal/service/health"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/internal/service/stream"
 	"tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/internal/service/webhook"
 	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/util/config"
 )
 
 type Services struct {
-	Wallet wallet.WalletService
+	Wallet  wallet.WalletService
+	Fx      fx.FxService
+	Outbox  outbox.OutboxService
+	Webhook webhook.WebhookService
+	Stream  stream.StreamService
+	ApiKey  apikey.ApiKeyService
+	Audit   audit.AuditService
+	Health  health.HealthService
 }
 
-func NewServices(repo repository.Repository, log logger.Logger) *Services {
+func NewServices(repo repository.Repository, notifier repository.Notifier, db health.Pinger, log logger.Logger, cfg config.Config) *Services {
+	threshold, err := decimal.NewFromString(cfg.AdminAdjustmentApprovalThreshold)
+	if err != nil {
+		log.Warn("invalid adjustment approval threshold, using default",
+			zap.String("value", cfg.AdminAdjustmentApprovalThreshold),
+			zap.Stringer("default", wallet.DefaultAdjustmentApprovalThreshold),
+		)
+		threshold = wallet.DefaultAdjustmentApprovalThreshold
+	}
+
+	auditService := audit.New(repo, log)
+
 	return &Services{
-		Wallet: wallet.New(repo, log),
+		Wallet: wallet.New(repo, log,
+			wallet.WithIdempotencyKeyTTL(cfg.IdempotencyKeyTTL),
+			wallet.WithHoldTTL(cfg.HoldTTL),
+			wallet.WithFrozenCredits(cfg.FrozenWalletAllowCredits),
+			wallet.WithAdjustmentApprovalThreshold(threshold),
+			wallet.WithAuditRecorder(auditService),
+		),
+		Fx:     fx.New(repo, log),
+		Outbox: outbox.New(repo, log),
+		Webhook: webhook.New(repo, log,
+			webhook.WithMaxAttempts(cfg.WebhookMaxAttempts),
+			webhook.WithRetryBaseDelay(cfg.WebhookRetryBaseDelay),
+			webhook.WithTimeout(cfg.WebhookTimeout),
+			webhook.WithAllowPrivateNetworks(cfg.WebhookAllowPrivateNetworks),
+		),
+		Stream: stream.New(repo, notifier, log),
+		ApiKey: apikey.New(repo, log, apikey.WithBootstrapKey(cfg.AuthBootstrapKey)),
+		Audit:  auditService,
+		Health: health.New(db, repo, log, health.WithTimeout(cfg.HealthCheckTimeout)),
 	}
 }
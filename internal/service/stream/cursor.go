@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+// cursor - позиция подписчика в журнале кошелька.
+//
+// created_at записи журнала - это NOW() начала транзакции, а видна запись становится только
+// после COMMIT, поэтому запись с меньшим created_at может появиться позже уже отданной.
+// Каждый проход перечитывает журнал с запасом reorderWindow и пропускает уже отданные id.
+type cursor struct {
+	latest time.Time
+	seen   map[uuid.UUID]time.Time
+
+	// Позиция Last-Event-ID: при первом проходе записи не позже нее считаются отданными
+	resumeAt *repository.WalletTransaction
+}
+
+func newCursor(resumeAt *repository.WalletTransaction) *cursor {
+	c := &cursor{seen: make(map[uuid.UUID]time.Time)}
+	if resumeAt != nil {
+		c.latest = resumeAt.CreatedAt
+		c.resumeAt = resumeAt
+	}
+	return c
+}
+
+// start - позиция, с которой начинается очередной проход
+func (c *cursor) start() (time.Time, uuid.UUID) {
+	if c.latest.IsZero() {
+		return time.Time{}, uuid.Nil
+	}
+	return c.latest.Add(-reorderWindow), uuid.Nil
+}
+
+// advance отмечает запись прочитанной и сообщает, нужно ли отдать ее подписчику
+func (c *cursor) advance(t repository.WalletTransaction) bool {
+	if _, ok := c.seen[t.ID]; ok {
+		return false
+	}
+	c.seen[t.ID] = t.CreatedAt
+	if t.CreatedAt.After(c.latest) {
+		c.latest = t.CreatedAt
+	}
+	if c.resumeAt != nil && !after(t, *c.resumeAt) {
+		return false
+	}
+	return true
+}
+
+// finishPass завершает проход и забывает id, которые уже не попадут в окно перечитывания
+func (c *cursor) finishPass() {
+	c.resumeAt = nil
+	horizon := c.latest.Add(-reorderWindow)
+	for id, createdAt := range c.seen {
+		if createdAt.Before(horizon) {
+			delete(c.seen, id)
+		}
+	}
+}
+
+// after сравнивает записи в порядке журнала (created_at, id)
+func after(a, b repository.WalletTransaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID.String() > b.ID.String()
+}
@@ -0,0 +1,8 @@
+package stream
+
+import "errors"
+
+var (
+	ErrWalletNotFound     = errors.New("wallet not found")
+	ErrInvalidLastEventID = errors.New("invalid Last-Event-ID")
+)
@@ -0,0 +1,258 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	// WalletChangesChannel - канал NOTIFY, в который триггер журнала пишет id кошелька
+	WalletChangesChannel = "wallet_changes"
+
+	// Транзакции операций ограничены 5 секундами, запись журнала не может стать видимой
+	// позже, чем через это время после своего created_at
+	reorderWindow = 10 * time.Second
+	catchUpBatch  = 500
+	// Страховочный опрос журнала на случай уведомлений, потерянных при обрыве LISTEN
+	pollInterval      = 15 * time.Second
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+type StreamService interface {
+	// Subscribe возвращает записи журнала кошелька по мере их фиксации. lastEventID - id
+	// последней полученной записи, с пустым значением поток начинается с новых записей.
+	// Канал закрывается при отмене ctx или остановке Run.
+	Subscribe(ctx context.Context, walletID uuid.UUID, lastEventID string) (<-chan wallet.Transaction, error)
+	// Run слушает уведомления Postgres и будит подписчиков до отмены ctx
+	Run(ctx context.Context) error
+}
+
+type streamService struct {
+	repo     repository.Repository
+	notifier repository.Notifier
+	logger   logger.Logger
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan struct{}]struct{}
+	done        chan struct{}
+	stopOnce    sync.Once
+}
+
+func New(repo repository.Repository, notifier repository.Notifier, log logger.Logger) StreamService {
+	return &streamService{
+		repo:        repo,
+		notifier:    notifier,
+		logger:      log,
+		subscribers: make(map[uuid.UUID]map[chan struct{}]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (s *streamService) Subscribe(ctx context.Context, walletID uuid.UUID, lastEventID string) (<-chan wallet.Transaction, error) {
+	w, err := s.repo.GetWallet(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		s.logger.Error("failed to get wallet for stream", zap.String("walletId", walletID.String()), zap.Error(err))
+		return nil, err
+	}
+	// Чужой кошелек неотличим от несуществующего, как и в сервисе кошельков
+	if !auth.CanRead(ctx, w.OwnerID) {
+		return nil, ErrWalletNotFound
+	}
+
+	resumeAt, err := s.resumePosition(ctx, walletID, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	wake := make(chan struct{}, 1)
+	s.subscribe(walletID, wake)
+
+	out := make(chan wallet.Transaction)
+	go s.serve(ctx, walletID, newCursor(resumeAt), wake, out)
+	return out, nil
+}
+
+// resumePosition находит запись, после которой начинается поток
+func (s *streamService) resumePosition(ctx context.Context, walletID uuid.UUID, lastEventID string) (*repository.WalletTransaction, error) {
+	if lastEventID != "" {
+		id, err := uuid.Parse(lastEventID)
+		if err != nil {
+			return nil, ErrInvalidLastEventID
+		}
+		t, err := s.repo.GetWalletTransaction(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrInvalidLastEventID
+			}
+			s.logger.Error("failed to get last stream event", zap.String("walletId", walletID.String()), zap.Error(err))
+			return nil, err
+		}
+		if t.WalletID != walletID {
+			return nil, ErrInvalidLastEventID
+		}
+		return &t, nil
+	}
+
+	latest, err := s.repo.ListWalletTransactions(ctx, repository.ListWalletTransactionsParams{
+		WalletID:  walletID,
+		PageLimit: 1,
+	})
+	if err != nil {
+		s.logger.Error("failed to get latest wallet transaction", zap.String("walletId", walletID.String()), zap.Error(err))
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	return &latest[0], nil
+}
+
+func (s *streamService) serve(ctx context.Context, walletID uuid.UUID, c *cursor, wake chan struct{}, out chan<- wallet.Transaction) {
+	defer close(out)
+	defer s.unsubscribe(walletID, wake)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.catchUp(ctx, walletID, c, out); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("failed to read wallet stream", zap.String("walletId", walletID.String()), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUp отдает подписчику записи журнала, которых он еще не видел
+func (s *streamService) catchUp(ctx context.Context, walletID uuid.UUID, c *cursor, out chan<- wallet.Transaction) error {
+	afterAt, afterID := c.start()
+	for {
+		rows, err := s.repo.ListWalletTransactionsAfter(ctx, repository.ListWalletTransactionsAfterParams{
+			WalletID:       walletID,
+			AfterCreatedAt: afterAt,
+			AfterID:        afterID,
+			PageLimit:      catchUpBatch,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if !c.advance(row) {
+				continue
+			}
+			select {
+			case out <- wallet.NewTransaction(row):
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.done:
+				return nil
+			}
+		}
+
+		if len(rows) < catchUpBatch {
+			break
+		}
+		last := rows[len(rows)-1]
+		afterAt, afterID = last.CreatedAt, last.ID
+	}
+	c.finishPass()
+	return nil
+}
+
+func (s *streamService) Run(ctx context.Context) error {
+	defer s.stopOnce.Do(func() { close(s.done) })
+
+	delay := minReconnectDelay
+	for {
+		started := time.Now()
+		err := s.notifier.Listen(ctx, WalletChangesChannel, s.notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		s.logger.Warn("wallet changes listener disconnected", zap.Duration("retryIn", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+		// Изменения за время обрыва подписчики дочитают из журнала
+		s.wakeAll()
+	}
+}
+
+func (s *streamService) notify(payload string) {
+	walletID, err := uuid.Parse(payload)
+	if err != nil {
+		s.logger.Warn("unexpected wallet change notification", zap.String("payload", payload))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for wake := range s.subscribers[walletID] {
+		signal(wake)
+	}
+}
+
+func (s *streamService) wakeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, subs := range s.subscribers {
+		for wake := range subs {
+			signal(wake)
+		}
+	}
+}
+
+func (s *streamService) subscribe(walletID uuid.UUID, wake chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[walletID] == nil {
+		s.subscribers[walletID] = make(map[chan struct{}]struct{})
+	}
+	s.subscribers[walletID][wake] = struct{}{}
+}
+
+func (s *streamService) unsubscribe(walletID uuid.UUID, wake chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers[walletID], wake)
+	if len(s.subscribers[walletID]) == 0 {
+		delete(s.subscribers, walletID)
+	}
+}
+
+// signal не блокируется: подписчик, который еще не дочитал журнал, и так увидит новые записи
+func signal(wake chan struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,180 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/stream"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+// fakeNotifier отдает тесту колбэк уведомлений и держит "соединение" до отмены ctx
+type fakeNotifier struct {
+	listening chan func(payload string)
+}
+
+func newFakeNotifier() *fakeNotifier {
+	return &fakeNotifier{listening: make(chan func(string), 1)}
+}
+
+func (n *fakeNotifier) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	n.listening <- fn
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func ledgerRow(walletID uuid.UUID, at time.Time, balance int64) repository.WalletTransaction {
+	return repository.WalletTransaction{
+		ID:           uuid.New(),
+		WalletID:     walletID,
+		Type:         wallet.OperationDeposit,
+		Amount:       decimal.NewFromInt(10),
+		BalanceAfter: decimal.NewFromInt(balance),
+		CreatedAt:    at,
+	}
+}
+
+func receive(t *testing.T, events <-chan wallet.Transaction) wallet.Transaction {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		require.True(t, ok, "stream closed")
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no stream event")
+		return wallet.Transaction{}
+	}
+}
+
+func TestSubscribe_ResumesAfterLastEventID(t *testing.T) {
+	walletID := uuid.New()
+	now := time.Now()
+	t0 := ledgerRow(walletID, now.Add(-2*time.Second), 10)
+	t1 := ledgerRow(walletID, now.Add(-time.Second), 20)
+	t2 := ledgerRow(walletID, now, 30)
+
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetWallet(gomock.Any(), walletID).Return(repository.Wallet{ID: walletID}, nil)
+	repo.EXPECT().GetWalletTransaction(gomock.Any(), t1.ID).Return(t1, nil)
+	repo.EXPECT().ListWalletTransactionsAfter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.ListWalletTransactionsAfterParams) ([]repository.WalletTransaction, error) {
+			// Журнал перечитывается с запасом до позиции Last-Event-ID
+			assert.True(t, arg.AfterCreatedAt.Before(t0.CreatedAt))
+			return []repository.WalletTransaction{t0, t1, t2}, nil
+		}).AnyTimes()
+
+	ctx, cancel := context.WithCancel(auth.WithInternal(context.Background()))
+	defer cancel()
+	events, err := stream.New(repo, newFakeNotifier(), zap.NewNop()).Subscribe(ctx, walletID, t1.ID.String())
+	require.NoError(t, err)
+
+	got := receive(t, events)
+	assert.Equal(t, t2.ID, got.ID)
+	assert.Equal(t, "30", got.BalanceAfter.String())
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestSubscribe_NotificationWakesSubscriber(t *testing.T) {
+	walletID := uuid.New()
+	now := time.Now()
+	existing := ledgerRow(walletID, now.Add(-time.Second), 10)
+	fresh := ledgerRow(walletID, now, 20)
+
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetWallet(gomock.Any(), walletID).Return(repository.Wallet{ID: walletID}, nil)
+	repo.EXPECT().ListWalletTransactions(gomock.Any(), gomock.Any()).Return([]repository.WalletTransaction{existing}, nil)
+	first := repo.EXPECT().ListWalletTransactionsAfter(gomock.Any(), gomock.Any()).
+		Return([]repository.WalletTransaction{existing}, nil)
+	repo.EXPECT().ListWalletTransactionsAfter(gomock.Any(), gomock.Any()).
+		Return([]repository.WalletTransaction{existing, fresh}, nil).After(first).AnyTimes()
+
+	notifier := newFakeNotifier()
+	svc := stream.New(repo, notifier, zap.NewNop())
+	ctx, cancel := context.WithCancel(auth.WithInternal(context.Background()))
+	defer cancel()
+	go func() { _ = svc.Run(ctx) }()
+	notify := <-notifier.listening
+
+	events, err := svc.Subscribe(ctx, walletID, "")
+	require.NoError(t, err)
+	// Уведомления о других кошельках подписчика не касаются
+	notify(uuid.New().String())
+	notify(walletID.String())
+
+	got := receive(t, events)
+	assert.Equal(t, fresh.ID, got.ID)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestSubscribe_Errors(t *testing.T) {
+	walletID := uuid.New()
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetWallet(gomock.Any(), walletID).Return(repository.Wallet{ID: walletID}, nil).Times(4)
+	repo.EXPECT().GetWallet(gomock.Any(), gomock.Not(walletID)).Return(repository.Wallet{}, pgx.ErrNoRows)
+	foreign := ledgerRow(uuid.New(), time.Now(), 10)
+	repo.EXPECT().GetWalletTransaction(gomock.Any(), foreign.ID).Return(foreign, nil)
+
+	svc := stream.New(repo, newFakeNotifier(), zap.NewNop())
+	internal := auth.WithInternal(context.Background())
+
+	_, err := svc.Subscribe(internal, uuid.New(), "")
+	assert.ErrorIs(t, err, stream.ErrWalletNotFound)
+	_, err = svc.Subscribe(internal, walletID, "not-a-uuid")
+	assert.ErrorIs(t, err, stream.ErrInvalidLastEventID)
+	_, err = svc.Subscribe(internal, walletID, foreign.ID.String())
+	assert.ErrorIs(t, err, stream.ErrInvalidLastEventID)
+	// Кошелек без владельца недоступен ключу клиента
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{OwnerID: "merchant-1", Scopes: []string{auth.ScopeWalletRead}})
+	_, err = svc.Subscribe(ctx, walletID, "")
+	assert.ErrorIs(t, err, stream.ErrWalletNotFound)
+	// Вызов без клиента и без пометки внутреннего запрещен
+	_, err = svc.Subscribe(context.Background(), walletID, "")
+	assert.ErrorIs(t, err, stream.ErrWalletNotFound)
+}
+
+func TestRun_StopClosesStreams(t *testing.T) {
+	walletID := uuid.New()
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetWallet(gomock.Any(), walletID).Return(repository.Wallet{ID: walletID}, nil)
+	repo.EXPECT().ListWalletTransactions(gomock.Any(), gomock.Any()).Return([]repository.WalletTransaction{}, nil)
+	repo.EXPECT().ListWalletTransactionsAfter(gomock.Any(), gomock.Any()).Return([]repository.WalletTransaction{}, nil).AnyTimes()
+
+	notifier := newFakeNotifier()
+	svc := stream.New(repo, notifier, zap.NewNop())
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = svc.Run(runCtx)
+		close(done)
+	}()
+	<-notifier.listening
+
+	events, err := svc.Subscribe(auth.WithInternal(context.Background()), walletID, "")
+	require.NoError(t, err)
+
+	stop()
+	<-done
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed after Run stopped")
+	}
+}
@@ -0,0 +1,266 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+const (
+	OperationManualAdjustment = "MANUAL_ADJUSTMENT"
+
+	AdjustmentCredit = "CREDIT"
+	AdjustmentDebit  = "DEBIT"
+
+	AdjustmentStatusPending  = "PENDING"
+	AdjustmentStatusApplied  = "APPLIED"
+	AdjustmentStatusRejected = "REJECTED"
+
+	maxAdjustmentsPage = 100
+)
+
+// DefaultAdjustmentApprovalThreshold - корректировки больше этой суммы ждут второго сотрудника
+var DefaultAdjustmentApprovalThreshold = decimal.NewFromInt(1000)
+
+// AdjustmentRequest - ручная корректировка баланса сотрудником поддержки
+type AdjustmentRequest struct {
+	Direction string
+	Amount    decimal.Decimal
+	Reason    string
+	Actor     string
+}
+
+// AdjustmentResult - корректировка и, если она уже проведена, кошелек после нее
+type AdjustmentResult struct {
+	Adjustment repository.ManualAdjustment
+	Wallet     *repository.Wallet
+}
+
+// AdjustmentFilter - отбор корректировок; пустые поля не ограничивают выборку
+type AdjustmentFilter struct {
+	Status   string
+	WalletID *uuid.UUID
+}
+
+// RequestAdjustment проводит корректировку сразу или, если сумма выше порога,
+// сохраняет ее в PENDING до подтверждения другим сотрудником.
+func (s *walletService) RequestAdjustment(ctx context.Context, walletID uuid.UUID, r AdjustmentRequest) (AdjustmentResult, error) {
+	r.Reason = strings.TrimSpace(r.Reason)
+	r.Actor = strings.TrimSpace(r.Actor)
+	if r.Reason == "" || r.Actor == "" {
+		return AdjustmentResult{}, fmt.Errorf("%w: reason and actor are required", ErrInvalidAdjustment)
+	}
+	if r.Direction != AdjustmentCredit && r.Direction != AdjustmentDebit {
+		return AdjustmentResult{}, fmt.Errorf("%w: direction must be %s or %s", ErrInvalidAdjustment, AdjustmentCredit, AdjustmentDebit)
+	}
+	if err := money.Validate(r.Amount); err != nil {
+		return AdjustmentResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+
+	params := repository.CreateManualAdjustmentParams{
+		ID:          uuid.New(),
+		WalletID:    walletID,
+		Direction:   r.Direction,
+		Amount:      r.Amount,
+		Reason:      r.Reason,
+		RequestedBy: r.Actor,
+	}
+	pending := r.Amount.GreaterThan(s.adjustmentApprovalThreshold)
+
+	var result AdjustmentResult
+	err := s.inWalletTx(ctx, walletID, func(ctx context.Context, q repository.Querier) error {
+		w, err := s.getWalletForUpdate(ctx, q, walletID)
+		if err != nil {
+			return err
+		}
+		if err = money.ValidateForCurrency(r.Amount, w.Currency); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+		}
+		if w.Status == WalletStatusClosed {
+			return ErrWalletClosed
+		}
+
+		params.Status = AdjustmentStatusPending
+		if !pending {
+			updated, entry, err := s.applyAdjustment(ctx, q, w, r.Direction, r.Amount)
+			if err != nil {
+				return err
+			}
+			params.Status = AdjustmentStatusApplied
+			params.TransactionID = &entry.ID
+			result.Wallet = &updated
+		}
+		result.Adjustment, err = q.CreateManualAdjustment(ctx, params)
+		if err != nil {
+			s.logger.Error("failed to record manual adjustment", zap.String("walletId", walletID.String()), zap.Error(err))
+		}
+		return err
+	})
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+
+	s.logger.Info("manual adjustment requested",
+		zap.String("adjustmentId", result.Adjustment.ID.String()),
+		zap.String("walletId", walletID.String()),
+		zap.String("direction", r.Direction),
+		zap.Stringer("amount", r.Amount),
+		zap.String("status", result.Adjustment.Status),
+		zap.String("actor", r.Actor),
+	)
+	return result, nil
+}
+
+// ApproveAdjustment проводит отложенную корректировку. Подтвердить может только
+// не тот сотрудник, что ее запросил.
+func (s *walletService) ApproveAdjustment(ctx context.Context, id uuid.UUID, actor string) (AdjustmentResult, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return AdjustmentResult{}, fmt.Errorf("%w: actor is required", ErrInvalidAdjustment)
+	}
+	// Кошелек нужен до транзакции, чтобы взять блокировку в том же порядке, что и операции
+	a, err := s.getAdjustment(ctx, s.repo, id, false)
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+
+	var result AdjustmentResult
+	err = s.inWalletTx(ctx, a.WalletID, func(ctx context.Context, q repository.Querier) error {
+		a, err := s.getPendingAdjustment(ctx, q, id, actor)
+		if err != nil {
+			return err
+		}
+		w, err := s.getWalletForUpdate(ctx, q, a.WalletID)
+		if err != nil {
+			return err
+		}
+		if w.Status == WalletStatusClosed {
+			return ErrWalletClosed
+		}
+		updated, entry, err := s.applyAdjustment(ctx, q, w, a.Direction, a.Amount)
+		if err != nil {
+			return err
+		}
+		result.Wallet = &updated
+		result.Adjustment, err = q.DecideManualAdjustment(ctx, repository.DecideManualAdjustmentParams{
+			ID:            id,
+			Status:        AdjustmentStatusApplied,
+			DecidedBy:     &actor,
+			TransactionID: &entry.ID,
+		})
+		if err != nil {
+			s.logger.Error("failed to approve manual adjustment", zap.String("adjustmentId", id.String()), zap.Error(err))
+		}
+		return err
+	})
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+
+	s.logger.Info("manual adjustment approved", zap.String("adjustmentId", id.String()), zap.String("actor", actor))
+	return result, nil
+}
+
+// RejectAdjustment отклоняет отложенную корректировку, баланс не меняется
+func (s *walletService) RejectAdjustment(ctx context.Context, id uuid.UUID, actor, reason string) (repository.ManualAdjustment, error) {
+	actor = strings.TrimSpace(actor)
+	reason = strings.TrimSpace(reason)
+	if actor == "" || reason == "" {
+		return repository.ManualAdjustment{}, fmt.Errorf("%w: reason and actor are required", ErrInvalidAdjustment)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result repository.ManualAdjustment
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		if _, err := s.getPendingAdjustment(ctx, q, id, actor); err != nil {
+			return err
+		}
+		var err error
+		result, err = q.DecideManualAdjustment(ctx, repository.DecideManualAdjustmentParams{
+			ID:             id,
+			Status:         AdjustmentStatusRejected,
+			DecidedBy:      &actor,
+			DecisionReason: &reason,
+		})
+		if err != nil {
+			s.logger.Error("failed to reject manual adjustment", zap.String("adjustmentId", id.String()), zap.Error(err))
+		}
+		return err
+	})
+	if err != nil {
+		return repository.ManualAdjustment{}, err
+	}
+
+	s.logger.Info("manual adjustment rejected", zap.String("adjustmentId", id.String()), zap.String("actor", actor))
+	return result, nil
+}
+
+func (s *walletService) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]repository.ManualAdjustment, error) {
+	params := repository.ListManualAdjustmentsParams{WalletID: filter.WalletID, RowLimit: maxAdjustmentsPage}
+	if filter.Status != "" {
+		switch filter.Status {
+		case AdjustmentStatusPending, AdjustmentStatusApplied, AdjustmentStatusRejected:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidAdjustment, filter.Status)
+		}
+		params.Status = &filter.Status
+	}
+	rows, err := s.repo.ListManualAdjustments(ctx, params)
+	if err != nil {
+		s.logger.Error("failed to list manual adjustments", zap.Error(err))
+		return nil, err
+	}
+	return rows, nil
+}
+
+// applyAdjustment меняет баланс заблокированного кошелька. В отличие от операций
+// клиента, корректировка допустима и на замороженном кошельке.
+func (s *walletService) applyAdjustment(ctx context.Context, q repository.Querier, w repository.Wallet, direction string, amount decimal.Decimal) (repository.Wallet, repository.WalletTransaction, error) {
+	if direction == AdjustmentDebit {
+		return s.debit(ctx, q, w, OperationManualAdjustment, amount)
+	}
+	return s.credit(ctx, q, w, OperationManualAdjustment, amount)
+}
+
+// getPendingAdjustment блокирует корректировку и проверяет, что ее еще можно решить и решает не автор
+func (s *walletService) getPendingAdjustment(ctx context.Context, q repository.Querier, id uuid.UUID, actor string) (repository.ManualAdjustment, error) {
+	a, err := s.getAdjustment(ctx, q, id, true)
+	if err != nil {
+		return repository.ManualAdjustment{}, err
+	}
+	if a.Status != AdjustmentStatusPending {
+		return repository.ManualAdjustment{}, ErrAdjustmentNotPending
+	}
+	if a.RequestedBy == actor {
+		s.logger.Warn("manual adjustment self-approval refused", zap.String("adjustmentId", id.String()), zap.String("actor", actor))
+		return repository.ManualAdjustment{}, ErrSelfApproval
+	}
+	return a, nil
+}
+
+func (s *walletService) getAdjustment(ctx context.Context, q repository.Querier, id uuid.UUID, forUpdate bool) (repository.ManualAdjustment, error) {
+	get := q.GetManualAdjustment
+	if forUpdate {
+		get = q.GetManualAdjustmentForUpdate
+	}
+	a, err := get(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ManualAdjustment{}, ErrAdjustmentNotFound
+		}
+		s.logger.Error("failed to get manual adjustment", zap.String("adjustmentId", id.String()), zap.Error(err))
+		return repository.ManualAdjustment{}, err
+	}
+	return a, nil
+}
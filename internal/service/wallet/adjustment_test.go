@@ -0,0 +1,214 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func pendingAdjustment(walletID uuid.UUID, direction string, amount int64) repository.ManualAdjustment {
+	return repository.ManualAdjustment{
+		ID:          uuid.New(),
+		WalletID:    walletID,
+		Direction:   direction,
+		Amount:      dec(amount),
+		Reason:      "goodwill",
+		Status:      wallet.AdjustmentStatusPending,
+		RequestedBy: "alice",
+	}
+}
+
+func TestRequestAdjustment_AppliedBelowThreshold(t *testing.T) {
+	w := makeWallet(100)
+	updated := w
+	updated.Balance = dec(150)
+	entryID := uuid.New()
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 150)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.Type == wallet.OperationManualAdjustment && arg.Amount.Equal(dec(50))
+	})).Return(repository.WalletTransaction{ID: entryID, Type: wallet.OperationManualAdjustment}, nil)
+	mockRepo.On("CreateManualAdjustment", mock.Anything, mock.MatchedBy(func(arg repository.CreateManualAdjustmentParams) bool {
+		return arg.Status == wallet.AdjustmentStatusApplied && arg.TransactionID != nil && *arg.TransactionID == entryID &&
+			arg.Reason == "refund of duplicate fee" && arg.RequestedBy == "alice"
+	})).Return(repository.ManualAdjustment{Status: wallet.AdjustmentStatusApplied}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithAdjustmentApprovalThreshold(dec(100)))
+	result, err := svc.RequestAdjustment(internalCtx(), w.ID, wallet.AdjustmentRequest{
+		Direction: wallet.AdjustmentCredit,
+		Amount:    dec(50),
+		Reason:    " refund of duplicate fee ",
+		Actor:     "alice",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Wallet)
+	assert.Equal(t, "150", result.Wallet.Balance.String())
+	assert.Equal(t, wallet.AdjustmentStatusApplied, result.Adjustment.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequestAdjustment_PendingAboveThreshold(t *testing.T) {
+	w := makeWallet(100)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("CreateManualAdjustment", mock.Anything, mock.MatchedBy(func(arg repository.CreateManualAdjustmentParams) bool {
+		return arg.Status == wallet.AdjustmentStatusPending && arg.TransactionID == nil
+	})).Return(repository.ManualAdjustment{Status: wallet.AdjustmentStatusPending}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithAdjustmentApprovalThreshold(dec(100)))
+	result, err := svc.RequestAdjustment(internalCtx(), w.ID, wallet.AdjustmentRequest{
+		Direction: wallet.AdjustmentDebit,
+		Amount:    dec(500),
+		Reason:    "chargeback",
+		Actor:     "alice",
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Wallet)
+	assert.Equal(t, wallet.AdjustmentStatusPending, result.Adjustment.Status)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
+
+func TestRequestAdjustment_Validation(t *testing.T) {
+	cases := map[string]wallet.AdjustmentRequest{
+		"no reason":      {Direction: wallet.AdjustmentCredit, Amount: dec(10), Actor: "alice"},
+		"no actor":       {Direction: wallet.AdjustmentCredit, Amount: dec(10), Reason: "fix"},
+		"bad direction":  {Direction: "WITHDRAW", Amount: dec(10), Reason: "fix", Actor: "alice"},
+		"blank reason":   {Direction: wallet.AdjustmentDebit, Amount: dec(10), Reason: "   ", Actor: "alice"},
+		"negative value": {Direction: wallet.AdjustmentDebit, Amount: dec(-10), Reason: "fix", Actor: "alice"},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			svc := wallet.New(mockRepo, zap.NewNop())
+
+			_, err := svc.RequestAdjustment(internalCtx(), uuid.New(), req)
+
+			require.Error(t, err)
+			mockRepo.AssertNotCalled(t, "WithTx")
+		})
+	}
+}
+
+func TestRequestAdjustment_DebitInsufficientFunds(t *testing.T) {
+	w := makeWallet(10)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.RequestAdjustment(internalCtx(), w.ID, wallet.AdjustmentRequest{
+		Direction: wallet.AdjustmentDebit,
+		Amount:    dec(50),
+		Reason:    "clawback",
+		Actor:     "alice",
+	})
+
+	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	mockRepo.AssertNotCalled(t, "CreateManualAdjustment")
+}
+
+func TestApproveAdjustment_Success(t *testing.T) {
+	w := makeWallet(100)
+	w.Status = wallet.WalletStatusFrozen
+	updated := w
+	updated.Balance = dec(1600)
+	a := pendingAdjustment(w.ID, wallet.AdjustmentCredit, 1500)
+	entryID := uuid.New()
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetManualAdjustment", mock.Anything, a.ID).Return(a, nil)
+	mockRepo.On("GetManualAdjustmentForUpdate", mock.Anything, a.ID).Return(a, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 1600)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).
+		Return(repository.WalletTransaction{ID: entryID, Type: wallet.OperationManualAdjustment}, nil)
+	mockRepo.On("DecideManualAdjustment", mock.Anything, mock.MatchedBy(func(arg repository.DecideManualAdjustmentParams) bool {
+		return arg.ID == a.ID && arg.Status == wallet.AdjustmentStatusApplied &&
+			*arg.DecidedBy == "bob" && *arg.TransactionID == entryID
+	})).Return(repository.ManualAdjustment{ID: a.ID, Status: wallet.AdjustmentStatusApplied}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ApproveAdjustment(internalCtx(), a.ID, "bob")
+
+	require.NoError(t, err)
+	assert.Equal(t, wallet.AdjustmentStatusApplied, result.Adjustment.Status)
+	assert.Equal(t, "1600", result.Wallet.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestApproveAdjustment_SelfApproval(t *testing.T) {
+	a := pendingAdjustment(uuid.New(), wallet.AdjustmentCredit, 1500)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrSelfApproval)
+	mockRepo.On("GetManualAdjustment", mock.Anything, a.ID).Return(a, nil)
+	mockRepo.On("GetManualAdjustmentForUpdate", mock.Anything, a.ID).Return(a, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ApproveAdjustment(internalCtx(), a.ID, "alice")
+
+	require.ErrorIs(t, err, wallet.ErrSelfApproval)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertNotCalled(t, "DecideManualAdjustment")
+}
+
+func TestApproveAdjustment_NotPending(t *testing.T) {
+	a := pendingAdjustment(uuid.New(), wallet.AdjustmentCredit, 1500)
+	a.Status = wallet.AdjustmentStatusRejected
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrAdjustmentNotPending)
+	mockRepo.On("GetManualAdjustment", mock.Anything, a.ID).Return(a, nil)
+	mockRepo.On("GetManualAdjustmentForUpdate", mock.Anything, a.ID).Return(a, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ApproveAdjustment(internalCtx(), a.ID, "bob")
+
+	require.ErrorIs(t, err, wallet.ErrAdjustmentNotPending)
+}
+
+func TestApproveAdjustment_NotFound(t *testing.T) {
+	id := uuid.New()
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetManualAdjustment", mock.Anything, id).Return(repository.ManualAdjustment{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ApproveAdjustment(internalCtx(), id, "bob")
+
+	require.ErrorIs(t, err, wallet.ErrAdjustmentNotFound)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
+
+func TestRejectAdjustment_Success(t *testing.T) {
+	a := pendingAdjustment(uuid.New(), wallet.AdjustmentDebit, 1500)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetManualAdjustmentForUpdate", mock.Anything, a.ID).Return(a, nil)
+	mockRepo.On("DecideManualAdjustment", mock.Anything, mock.MatchedBy(func(arg repository.DecideManualAdjustmentParams) bool {
+		return arg.Status == wallet.AdjustmentStatusRejected && *arg.DecisionReason == "no evidence" && arg.TransactionID == nil
+	})).Return(repository.ManualAdjustment{ID: a.ID, Status: wallet.AdjustmentStatusRejected}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.RejectAdjustment(internalCtx(), a.ID, "bob", "no evidence")
+
+	require.NoError(t, err)
+	assert.Equal(t, wallet.AdjustmentStatusRejected, result.Status)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
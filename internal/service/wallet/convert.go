@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+// ConversionResult - состояние кошельков после конвертации и примененный курс
+type ConversionResult struct {
+	From      repository.Wallet
+	To        repository.Wallet
+	Rate      decimal.Decimal
+	Credited  decimal.Decimal
+	Remainder decimal.Decimal
+}
+
+// Convert списывает amount в валюте кошелька fromWalletID и зачисляет на toWalletID
+// по курсу, действующему на момент операции. Зачисление округляется вниз до минорной
+// единицы валюты получателя, остаток от округления сохраняется в журнале.
+func (s *walletService) Convert(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (ConversionResult, error) {
+	if fromWalletID == toWalletID {
+		return ConversionResult{}, ErrSameWallet
+	}
+	if err := money.Validate(amount); err != nil {
+		return ConversionResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+
+	unlock := s.locker.LockMany(ctx, fromWalletID, toWalletID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result ConversionResult
+
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		from, to, err := s.getWalletPairForUpdate(ctx, q, fromWalletID, toWalletID)
+		if err != nil {
+			return err
+		}
+		if err = s.authorize(ctx, from); err != nil {
+			return err
+		}
+		if err = s.checkDebit(from); err != nil {
+			return err
+		}
+		if err = s.checkCredit(to); err != nil {
+			return err
+		}
+		if from.Currency == to.Currency {
+			return ErrSameCurrency
+		}
+		if err = money.ValidateForCurrency(amount, from.Currency); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+		}
+		if AvailableBalance(from).LessThan(amount) {
+			s.logger.Warn("insufficient funds", zap.String("walletId", fromWalletID.String()), zap.Stringer("available", AvailableBalance(from)), zap.Stringer("amount", amount))
+			return ErrInsufficientFunds
+		}
+
+		rate, err := q.GetFxRateAt(ctx, repository.GetFxRateAtParams{
+			BaseCurrency:  from.Currency,
+			QuoteCurrency: to.Currency,
+			At:            time.Now(),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Warn("exchange rate not found", zap.String("base", from.Currency), zap.String("quote", to.Currency))
+				return ErrRateNotFound
+			}
+			s.logger.Error("failed to get exchange rate", zap.Error(err))
+			return err
+		}
+
+		exp, err := money.Exponent(to.Currency)
+		if err != nil {
+			return err
+		}
+		gross := amount.Mul(rate.Rate)
+		credited := gross.Truncate(exp)
+		remainder := gross.Sub(credited)
+		if !credited.IsPositive() {
+			return fmt.Errorf("%w: %w", ErrInvalidAmount, money.ErrNotPositive)
+		}
+
+		result.From, err = s.applyBalanceChange(ctx, q, from, ledgerEntry{
+			opType:       OperationConvertOut,
+			amount:       amount,
+			newBalance:   from.Balance.Sub(amount),
+			counterparty: &toWalletID,
+			fxRate:       &rate.Rate,
+		})
+		if err != nil {
+			return err
+		}
+		result.To, err = s.applyBalanceChange(ctx, q, to, ledgerEntry{
+			opType:       OperationConvertIn,
+			amount:       credited,
+			newBalance:   to.Balance.Add(credited),
+			counterparty: &fromWalletID,
+			fxRate:       &rate.Rate,
+			fxRemainder:  &remainder,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Rate = rate.Rate
+		result.Credited = credited
+		result.Remainder = remainder
+		return nil
+	})
+
+	if errors.Is(err, ErrInsufficientFunds) {
+		s.recordInsufficientFunds(ctx, fromWalletID, OperationConvertOut, amount)
+	}
+	if err == nil {
+		s.logger.Info("wallet conversion completed",
+			zap.String("fromWalletId", fromWalletID.String()),
+			zap.String("toWalletId", toWalletID.String()),
+			zap.Stringer("amount", amount),
+			zap.Stringer("rate", result.Rate),
+			zap.Stringer("credited", result.Credited),
+		)
+	}
+
+	return result, err
+}
@@ -0,0 +1,119 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func fxRate(base, quote, rate string) repository.FxRate {
+	return repository.FxRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          decimal.RequireFromString(rate),
+	}
+}
+
+func TestConvert_Success_TruncatesToTargetPrecision(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+	to.Currency = "JPY"
+	fromUpdated, toUpdated := from, to
+	fromUpdated.Balance = decimal.RequireFromString("89.45")
+	toUpdated.Balance = dec(1595)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+	mockRepo.On("GetFxRateAt", mock.Anything, mock.MatchedBy(func(arg repository.GetFxRateAtParams) bool {
+		return arg.BaseCurrency == "USD" && arg.QuoteCurrency == "JPY"
+	})).Return(fxRate("USD", "JPY", "151.2345"), nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletBalanceParams) bool {
+		return arg.ID == from.ID && arg.Balance.Equal(decimal.RequireFromString("89.45"))
+	})).Return(fromUpdated, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(to.ID, 1595)).Return(toUpdated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == from.ID && arg.Type == wallet.OperationConvertOut &&
+			arg.Amount.Equal(decimal.RequireFromString("10.55")) &&
+			arg.FxRate != nil && arg.FxRate.Equal(decimal.RequireFromString("151.2345")) &&
+			arg.FxRemainder == nil
+	})).Return(repository.WalletTransaction{}, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == to.ID && arg.Type == wallet.OperationConvertIn &&
+			arg.Amount.Equal(dec(1595)) &&
+			arg.CounterpartyWalletID != nil && *arg.CounterpartyWalletID == from.ID &&
+			arg.FxRemainder != nil && arg.FxRemainder.Equal(decimal.RequireFromString("0.523975"))
+	})).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.Convert(internalCtx(), from.ID, to.ID, decimal.RequireFromString("10.55"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1595", result.Credited.String())
+	assert.Equal(t, "0.523975", result.Remainder.String())
+	assert.Equal(t, "89.45", result.From.Balance.String())
+	assert.Equal(t, "1595", result.To.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvert_RateNotFound(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+	to.Currency = "EUR"
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrRateNotFound)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+	mockRepo.On("GetFxRateAt", mock.Anything, mock.Anything).Return(repository.FxRate{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Convert(internalCtx(), from.ID, to.ID, dec(10))
+
+	require.ErrorIs(t, err, wallet.ErrRateNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvert_SameCurrency(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrSameCurrency)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Convert(internalCtx(), from.ID, to.ID, dec(10))
+
+	require.ErrorIs(t, err, wallet.ErrSameCurrency)
+	mockRepo.AssertNotCalled(t, "GetFxRateAt")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvert_CreditedAmountRoundsToZero(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+	to.Currency = "JPY"
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInvalidAmount)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+	mockRepo.On("GetFxRateAt", mock.Anything, mock.Anything).Return(fxRate("USD", "JPY", "50"), nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Convert(internalCtx(), from.ID, to.ID, decimal.RequireFromString("0.01"))
+
+	require.ErrorIs(t, err, wallet.ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
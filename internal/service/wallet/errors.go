@@ -4,6 +4,42 @@ import "errors"
 
 var (
 	ErrWalletNotFound    = errors.New("wallet not found")
+	ErrWalletFrozen      = errors.New("wallet is frozen")
+	ErrWalletClosed      = errors.New("wallet is closed")
+	ErrWalletNotEmpty    = errors.New("wallet balance must be zero to close")
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrInvalidOperation  = errors.New("invalid operation type")
+	ErrInvalidAmount     = errors.New("invalid amount")
+	ErrSameWallet        = errors.New("source and destination wallets must differ")
+
+	ErrInvalidStatusTransition = errors.New("invalid wallet status transition")
+
+	ErrInvalidOwner       = errors.New("invalid wallet owner")
+	ErrExternalRefExists  = errors.New("wallet with this external reference already exists")
+	ErrInvalidExternalRef = errors.New("invalid wallet external reference")
+
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("wallets have different currencies")
+	ErrSameCurrency        = errors.New("wallets have the same currency")
+	ErrRateNotFound        = errors.New("exchange rate not found")
+
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrInvalidFilter       = errors.New("invalid transaction filter")
+
+	ErrTransactionNotReversible   = errors.New("transaction cannot be reversed")
+	ErrTransactionAlreadyReversed = errors.New("transaction is already fully reversed")
+	ErrReversalExceedsBalance     = errors.New("reversal would drive the balance below zero")
+
+	ErrHoldNotFound   = errors.New("hold not found")
+	ErrHoldNotActive  = errors.New("hold is not active")
+	ErrHoldIDRequired = errors.New("hold id is required")
+
+	ErrInvalidAdjustment    = errors.New("invalid manual adjustment")
+	ErrAdjustmentNotFound   = errors.New("manual adjustment not found")
+	ErrAdjustmentNotPending = errors.New("manual adjustment is already decided")
+	ErrSelfApproval         = errors.New("manual adjustment must be approved by another operator")
+
+	ErrIdempotencyKeyReused   = errors.New("idempotency key already used with a different request")
+	ErrIdempotencyKeyConflict = errors.New("request with this idempotency key is already in progress")
 )
@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+const (
+	OperationHold       = "HOLD"
+	OperationCapture    = "CAPTURE"
+	OperationVoid       = "VOID"
+	OperationHoldExpire = "HOLD_EXPIRE"
+)
+
+const (
+	HoldStatusActive   = "ACTIVE"
+	HoldStatusCaptured = "CAPTURED"
+	HoldStatusVoided   = "VOIDED"
+	HoldStatusExpired  = "EXPIRED"
+)
+
+const (
+	DefaultHoldTTL = 7 * 24 * time.Hour
+	// expireHoldsBatch - сколько холдов снимается за один проход воркера
+	expireHoldsBatch = 100
+)
+
+// Hold - резерв средств на кошельке до списания (CAPTURE) или отмены (VOID)
+type Hold struct {
+	ID             uuid.UUID        `json:"id"`
+	WalletID       uuid.UUID        `json:"wallet_id"`
+	Amount         decimal.Decimal  `json:"amount"`
+	CapturedAmount *decimal.Decimal `json:"captured_amount,omitempty"`
+	Status         string           `json:"status"`
+	CreatedAt      time.Time        `json:"created_at"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+}
+
+func newHold(h repository.WalletHold) Hold {
+	return Hold{
+		ID:             h.ID,
+		WalletID:       h.WalletID,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		Status:         h.Status,
+		CreatedAt:      h.CreatedAt,
+		ExpiresAt:      h.ExpiresAt,
+	}
+}
+
+func (s *walletService) createHold(ctx context.Context, q repository.Querier, w repository.Wallet, amount decimal.Decimal) (OperationResult, error) {
+	if err := s.checkDebit(w); err != nil {
+		return OperationResult{}, err
+	}
+	if err := money.ValidateForCurrency(amount, w.Currency); err != nil {
+		return OperationResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	if AvailableBalance(w).LessThan(amount) {
+		s.logger.Warn("insufficient funds", zap.String("walletId", w.ID.String()), zap.Stringer("available", AvailableBalance(w)), zap.Stringer("amount", amount))
+		return OperationResult{}, ErrInsufficientFunds
+	}
+
+	hold, err := q.CreateWalletHold(ctx, repository.CreateWalletHoldParams{
+		ID:        uuid.New(),
+		WalletID:  w.ID,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(s.holdTTL),
+	})
+	if err != nil {
+		s.logger.Error("failed to create wallet hold", zap.String("walletId", w.ID.String()), zap.Error(err))
+		return OperationResult{}, err
+	}
+
+	updated, err := s.applyBalanceChange(ctx, q, w, ledgerEntry{
+		opType:     OperationHold,
+		amount:     amount,
+		newBalance: w.Balance,
+		heldDelta:  amount,
+		holdID:     &hold.ID,
+	})
+	if err != nil {
+		return OperationResult{}, err
+	}
+	h := newHold(hold)
+	return OperationResult{Wallet: updated, Hold: &h}, nil
+}
+
+func (s *walletService) captureHold(ctx context.Context, q repository.Querier, w repository.Wallet, hold repository.WalletHold, amount decimal.Decimal) (OperationResult, error) {
+	if err := s.checkDebit(w); err != nil {
+		return OperationResult{}, err
+	}
+	if amount.IsZero() {
+		amount = hold.Amount
+	}
+	if err := money.ValidateForCurrency(amount, w.Currency); err != nil {
+		return OperationResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	if amount.GreaterThan(hold.Amount) {
+		return OperationResult{}, fmt.Errorf("%w: capture exceeds hold amount", ErrInvalidAmount)
+	}
+
+	updatedHold, err := q.UpdateWalletHoldStatus(ctx, repository.UpdateWalletHoldStatusParams{
+		ID:             hold.ID,
+		Status:         HoldStatusCaptured,
+		CapturedAmount: &amount,
+	})
+	if err != nil {
+		s.logger.Error("failed to capture wallet hold", zap.String("holdId", hold.ID.String()), zap.Error(err))
+		return OperationResult{}, err
+	}
+
+	// Списывается захваченная сумма, резерв снимается целиком
+	updated, err := s.applyBalanceChange(ctx, q, w, ledgerEntry{
+		opType:     OperationCapture,
+		amount:     amount,
+		newBalance: w.Balance.Sub(amount),
+		heldDelta:  hold.Amount.Neg(),
+		holdID:     &hold.ID,
+	})
+	if err != nil {
+		return OperationResult{}, err
+	}
+	h := newHold(updatedHold)
+	return OperationResult{Wallet: updated, Hold: &h}, nil
+}
+
+// releaseHold снимает резерв без списания: VOID по запросу клиента или истечение TTL
+func (s *walletService) releaseHold(ctx context.Context, q repository.Querier, w repository.Wallet, hold repository.WalletHold, opType, status string) (OperationResult, error) {
+	updatedHold, err := q.UpdateWalletHoldStatus(ctx, repository.UpdateWalletHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	if err != nil {
+		s.logger.Error("failed to release wallet hold", zap.String("holdId", hold.ID.String()), zap.Error(err))
+		return OperationResult{}, err
+	}
+
+	updated, err := s.applyBalanceChange(ctx, q, w, ledgerEntry{
+		opType:     opType,
+		amount:     hold.Amount,
+		newBalance: w.Balance,
+		heldDelta:  hold.Amount.Neg(),
+		holdID:     &hold.ID,
+	})
+	if err != nil {
+		return OperationResult{}, err
+	}
+	h := newHold(updatedHold)
+	return OperationResult{Wallet: updated, Hold: &h}, nil
+}
+
+// getActiveHoldForUpdate блокирует холд кошелька и проверяет, что он еще действует.
+// Строка кошелька к этому моменту уже заблокирована.
+func (s *walletService) getActiveHoldForUpdate(ctx context.Context, q repository.Querier, walletID, holdID uuid.UUID) (repository.WalletHold, error) {
+	hold, err := q.GetWalletHoldForUpdate(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.WalletHold{}, ErrHoldNotFound
+		}
+		s.logger.Error("failed to get wallet hold", zap.String("holdId", holdID.String()), zap.Error(err))
+		return repository.WalletHold{}, err
+	}
+	if hold.WalletID != walletID {
+		return repository.WalletHold{}, ErrHoldNotFound
+	}
+	if hold.Status != HoldStatusActive || !hold.ExpiresAt.After(time.Now()) {
+		return repository.WalletHold{}, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+// ExpireHolds снимает резервы, у которых истек TTL, и возвращает их количество
+func (s *walletService) ExpireHolds(ctx context.Context) (int, error) {
+	holds, err := s.repo.ListExpiredWalletHolds(ctx, expireHoldsBatch)
+	if err != nil {
+		s.logger.Error("failed to list expired wallet holds", zap.Error(err))
+		return 0, err
+	}
+
+	expired := 0
+	for _, h := range holds {
+		ok, err := s.expireHold(ctx, h.WalletID, h.ID)
+		if err != nil {
+			s.logger.Error("failed to expire wallet hold", zap.String("holdId", h.ID.String()), zap.Error(err))
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (s *walletService) expireHold(ctx context.Context, walletID, holdID uuid.UUID) (bool, error) {
+	unlock := s.locker.Lock(ctx, walletID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	expired := false
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		w, err := s.getWalletForUpdate(ctx, q, walletID)
+		if err != nil {
+			return err
+		}
+		hold, err := q.GetWalletHoldForUpdate(ctx, holdID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrHoldNotFound
+			}
+			return err
+		}
+		// Холд могли списать или отменить после выборки
+		if hold.Status != HoldStatusActive || hold.ExpiresAt.After(time.Now()) {
+			return nil
+		}
+		if _, err = s.releaseHold(ctx, q, w, hold, OperationHoldExpire, HoldStatusExpired); err != nil {
+			return err
+		}
+		expired = true
+		return nil
+	})
+	return expired, err
+}
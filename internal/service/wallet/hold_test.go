@@ -0,0 +1,295 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func makeHold(walletID uuid.UUID, amount int64) repository.WalletHold {
+	return repository.WalletHold{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Amount:    dec(amount),
+		Status:    wallet.HoldStatusActive,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func heldUpdate(id uuid.UUID, balance, heldDelta int64) interface{} {
+	return mock.MatchedBy(func(arg repository.UpdateWalletBalanceParams) bool {
+		return arg.ID == id && arg.Balance.Equal(dec(balance)) && arg.HeldDelta.Equal(dec(heldDelta))
+	})
+}
+
+func ledgerFor(holdID uuid.UUID, opType string, amount int64) interface{} {
+	return mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.Type == opType && arg.Amount.Equal(dec(amount)) &&
+			arg.HoldID != nil && *arg.HoldID == holdID
+	})
+}
+
+func TestHold_Success(t *testing.T) {
+	w := makeWallet(100)
+	updated := w
+	updated.HeldBalance = dec(30)
+	hold := makeHold(w.ID, 30)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("CreateWalletHold", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletHoldParams) bool {
+		return arg.WalletID == w.ID && arg.Amount.Equal(dec(30)) && arg.ExpiresAt.After(time.Now().Add(59*time.Minute))
+	})).Return(hold, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 100, 30)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.Type == wallet.OperationHold && arg.HoldID != nil && *arg.HoldID == hold.ID &&
+			arg.BalanceBefore.Equal(dec(100)) && arg.BalanceAfter.Equal(dec(100))
+	})).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithHoldTTL(time.Hour))
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationHold, Amount: dec(30)})
+
+	require.NoError(t, err)
+	assert.Equal(t, hold.ID, result.Hold.ID)
+	assert.Equal(t, "70", wallet.AvailableBalance(result.Wallet).String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHold_InsufficientAvailableFunds(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(80)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationHold, Amount: dec(30)})
+
+	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	mockRepo.AssertNotCalled(t, "CreateWalletHold")
+}
+
+func TestProcessOperation_WithdrawRespectsHeldFunds(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(80)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationWithdraw, Amount: dec(30)})
+
+	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
+
+func TestCapture_Partial(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(30)
+	hold := makeHold(w.ID, 30)
+	captured := hold
+	captured.Status = wallet.HoldStatusCaptured
+	captured.CapturedAmount = &[]decimal.Decimal{dec(20)}[0]
+	updated := w
+	updated.Balance, updated.HeldBalance = dec(80), dec(0)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+	mockRepo.On("UpdateWalletHoldStatus", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletHoldStatusParams) bool {
+		return arg.ID == hold.ID && arg.Status == wallet.HoldStatusCaptured &&
+			arg.CapturedAmount != nil && arg.CapturedAmount.Equal(dec(20))
+	})).Return(captured, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 80, -30)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, ledgerFor(hold.ID, wallet.OperationCapture, 20)).
+		Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationCapture, Amount: dec(20), HoldID: &hold.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, wallet.HoldStatusCaptured, result.Hold.Status)
+	assert.Equal(t, "80", result.Wallet.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCapture_FullAmountByDefault(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(30)
+	hold := makeHold(w.ID, 30)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+	mockRepo.On("UpdateWalletHoldStatus", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletHoldStatusParams) bool {
+		return arg.CapturedAmount != nil && arg.CapturedAmount.Equal(dec(30))
+	})).Return(hold, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 70, -30)).Return(w, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, ledgerFor(hold.ID, wallet.OperationCapture, 30)).
+		Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationCapture, HoldID: &hold.ID})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCapture_ExceedsHold(t *testing.T) {
+	w := makeWallet(100)
+	hold := makeHold(w.ID, 30)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInvalidAmount)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationCapture, Amount: dec(31), HoldID: &hold.ID})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "UpdateWalletHoldStatus")
+}
+
+func TestCapture_HoldIDRequired(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: uuid.New(), Type: wallet.OperationCapture, Amount: dec(10)})
+
+	require.ErrorIs(t, err, wallet.ErrHoldIDRequired)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
+
+func TestCapture_HoldOfAnotherWallet(t *testing.T) {
+	w := makeWallet(100)
+	hold := makeHold(uuid.New(), 30)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrHoldNotFound)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationCapture, Amount: dec(10), HoldID: &hold.ID})
+
+	require.ErrorIs(t, err, wallet.ErrHoldNotFound)
+}
+
+func TestVoid_AlreadyCaptured(t *testing.T) {
+	w := makeWallet(100)
+	hold := makeHold(w.ID, 30)
+	hold.Status = wallet.HoldStatusCaptured
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrHoldNotActive)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationVoid, HoldID: &hold.ID})
+
+	require.ErrorIs(t, err, wallet.ErrHoldNotActive)
+}
+
+func TestVoid_ReleasesHold(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(30)
+	hold := makeHold(w.ID, 30)
+	voided := hold
+	voided.Status = wallet.HoldStatusVoided
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+	mockRepo.On("UpdateWalletHoldStatus", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletHoldStatusParams) bool {
+		return arg.ID == hold.ID && arg.Status == wallet.HoldStatusVoided && arg.CapturedAmount == nil
+	})).Return(voided, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 100, -30)).Return(w, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, ledgerFor(hold.ID, wallet.OperationVoid, 30)).
+		Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationVoid, HoldID: &hold.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, wallet.HoldStatusVoided, result.Hold.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExpireHolds(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(30)
+	expired := makeHold(w.ID, 30)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	// Холд успели списать между выборкой и блокировкой
+	captured := makeHold(w.ID, 10)
+	captured.ExpiresAt = time.Now().Add(-time.Minute)
+	capturedNow := captured
+	capturedNow.Status = wallet.HoldStatusCaptured
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("ListExpiredWalletHolds", mock.Anything, mock.Anything).
+		Return([]repository.WalletHold{expired, captured}, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, expired.ID).Return(expired, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, captured.ID).Return(capturedNow, nil)
+	mockRepo.On("UpdateWalletHoldStatus", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletHoldStatusParams) bool {
+		return arg.ID == expired.ID && arg.Status == wallet.HoldStatusExpired
+	})).Return(expired, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 100, -30)).Return(w, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, ledgerFor(expired.ID, wallet.OperationHoldExpire, 30)).
+		Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	n, err := svc.ExpireHolds(internalCtx())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExpireHolds_HoldNotFound(t *testing.T) {
+	w := makeWallet(100)
+	hold := makeHold(w.ID, 30)
+	hold.ExpiresAt = time.Now().Add(-time.Minute)
+
+	mockRepo := new(MockRepository)
+	var txErr error
+	mockRepo.On("WithTx", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { txErr = args.Get(1).(func(repository.Querier) error)(mockRepo) }).
+		Return(wallet.ErrHoldNotFound)
+	mockRepo.On("ListExpiredWalletHolds", mock.Anything, mock.Anything).
+		Return([]repository.WalletHold{hold}, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(repository.WalletHold{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	n, err := svc.ExpireHolds(internalCtx())
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.ErrorIs(t, txErr, wallet.ErrHoldNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateWalletHoldStatus")
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// ProcessOperationIdempotent выполняет операцию не более одного раза на ключ.
+// Ключ и ответ сохраняются в той же транзакции, что и изменение баланса,
+// повторный запрос с тем же ключом и телом получает сохраненный ответ.
+// Ключи разных клиентов не пересекаются, см. idempotencyCaller.
+func (s *walletService) ProcessOperationIdempotent(ctx context.Context, key string, op Operation) (OperationResult, bool, error) {
+	if err := op.validate(); err != nil {
+		return OperationResult{}, false, err
+	}
+
+	hash := operationRequestHash(op)
+	caller := idempotencyCaller(ctx)
+
+	var (
+		result   OperationResult
+		replayed bool
+	)
+
+	err := s.inWalletTx(ctx, op.WalletID, func(ctx context.Context, q repository.Querier) error {
+		stored, err := q.GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{Caller: caller, Key: key})
+		switch {
+		case err == nil:
+			if stored.RequestHash != hash {
+				s.logger.Warn("idempotency key reused with different request", zap.String("walletId", op.WalletID.String()))
+				return ErrIdempotencyKeyReused
+			}
+			if err = json.Unmarshal(stored.Response, &result); err != nil {
+				s.logger.Error("failed to decode stored idempotent response", zap.Error(err))
+				return err
+			}
+			if err = s.authorize(ctx, result.Wallet); err != nil {
+				return err
+			}
+			replayed = true
+			return nil
+		case !errors.Is(err, pgx.ErrNoRows):
+			s.logger.Error("failed to get idempotency key", zap.Error(err))
+			return err
+		}
+
+		result, err = s.processOperation(ctx, q, op)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateIdempotencyKey(ctx, repository.CreateIdempotencyKeyParams{
+			Caller:      caller,
+			Key:         key,
+			RequestHash: hash,
+			Response:    response,
+			ExpiresAt:   time.Now().Add(s.idempotencyKeyTTL),
+		})
+		if err != nil {
+			// Живой ключ успел сохранить параллельный запрос
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Warn("idempotency key conflict", zap.String("walletId", op.WalletID.String()))
+				return ErrIdempotencyKeyConflict
+			}
+			s.logger.Error("failed to store idempotency key", zap.Error(err))
+		}
+		return err
+	})
+
+	if errors.Is(err, ErrInsufficientFunds) {
+		s.recordInsufficientFunds(ctx, op.WalletID, op.Type, op.Amount)
+	}
+	if err == nil && !replayed {
+		s.logOperation(op, result)
+	}
+
+	return result, replayed, err
+}
+
+func (s *walletService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	n, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		s.logger.Error("failed to purge expired idempotency keys", zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
+
+// idempotencyCaller - пространство ключей вызывающего. У клиента с владельцем оно общее
+// для всех его API-ключей и переживает ротацию, у остальных привязано к личности (Subject).
+// Внутренние вызовы без клиента пользуются общим пустым пространством.
+func idempotencyCaller(ctx context.Context) string {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if p.OwnerID != "" {
+		return "owner:" + p.OwnerID
+	}
+	return p.Subject
+}
+
+// operationRequestHash - отпечаток всех полей запроса
+func operationRequestHash(op Operation) string {
+	holdID := ""
+	if op.HoldID != nil {
+		holdID = op.HoldID.String()
+	}
+	data := op.WalletID.String() + "|" + op.Type + "|" + op.Amount.String() + "|" + holdID
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,238 @@
+package wallet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func TestProcessOperationIdempotent_FirstRequest(t *testing.T) {
+	existing := makeWallet(100)
+	updated := existing
+	updated.Balance = dec(150)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "key-1"}).
+		Return(repository.IdempotencyKey{}, pgx.ErrNoRows)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(existing.ID, 150)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).
+		Return(repository.WalletTransaction{}, nil)
+	mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.MatchedBy(func(arg repository.CreateIdempotencyKeyParams) bool {
+		var stored wallet.OperationResult
+		return arg.Key == "key-1" && len(arg.RequestHash) == 64 &&
+			json.Unmarshal(arg.Response, &stored) == nil && stored.Wallet.Balance.Equal(dec(150)) &&
+			time.Until(arg.ExpiresAt) > time.Hour
+	})).Return(repository.IdempotencyKey{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithIdempotencyKeyTTL(2*time.Hour))
+	result, replayed, err := svc.ProcessOperationIdempotent(internalCtx(), "key-1", wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "150", result.Wallet.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperationIdempotent_ReplaysStoredResponse(t *testing.T) {
+	existing := makeWallet(100)
+	stored := existing
+	stored.Balance = dec(150)
+	response, err := json.Marshal(wallet.OperationResult{Wallet: stored})
+	require.NoError(t, err)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "key-1"}).Return(repository.IdempotencyKey{}, pgx.ErrNoRows).Once()
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).Return(existing, nil).Once()
+	mockRepo.On("UpdateWalletBalance", mock.Anything, mock.Anything).Return(stored, nil).Once()
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil).Once()
+
+	var saved repository.CreateIdempotencyKeyParams
+	mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(repository.CreateIdempotencyKeyParams) }).
+		Return(repository.IdempotencyKey{}, nil).Once()
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, _, err = svc.ProcessOperationIdempotent(internalCtx(), "key-1", wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+	require.NoError(t, err)
+
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "key-1"}).Return(repository.IdempotencyKey{
+		Key:         "key-1",
+		RequestHash: saved.RequestHash,
+		Response:    response,
+	}, nil).Once()
+
+	result, replayed, err := svc.ProcessOperationIdempotent(internalCtx(), "key-1", wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, stored.ID, result.Wallet.ID)
+	assert.Equal(t, "150", result.Wallet.Balance.String())
+	mockRepo.AssertNumberOfCalls(t, "UpdateWalletBalance", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperationIdempotent_ReplaysHold(t *testing.T) {
+	w := makeWallet(100)
+	updated := w
+	updated.HeldBalance = dec(30)
+	hold := makeHold(w.ID, 30)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "hold-1"}).Return(repository.IdempotencyKey{}, pgx.ErrNoRows).Once()
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil).Once()
+	mockRepo.On("CreateWalletHold", mock.Anything, mock.Anything).Return(hold, nil).Once()
+	mockRepo.On("UpdateWalletBalance", mock.Anything, heldUpdate(w.ID, 100, 30)).Return(updated, nil).Once()
+	mockRepo.On("CreateWalletTransaction", mock.Anything, ledgerFor(hold.ID, wallet.OperationHold, 30)).
+		Return(repository.WalletTransaction{}, nil).Once()
+
+	var saved repository.CreateIdempotencyKeyParams
+	mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(repository.CreateIdempotencyKeyParams) }).
+		Return(repository.IdempotencyKey{}, nil).Once()
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	op := wallet.Operation{WalletID: w.ID, Type: wallet.OperationHold, Amount: dec(30)}
+	first, replayed, err := svc.ProcessOperationIdempotent(internalCtx(), "hold-1", op)
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	require.NotNil(t, first.Hold)
+
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "hold-1"}).Return(repository.IdempotencyKey{
+		Key:         "hold-1",
+		RequestHash: saved.RequestHash,
+		Response:    saved.Response,
+	}, nil).Once()
+
+	result, replayed, err := svc.ProcessOperationIdempotent(internalCtx(), "hold-1", op)
+
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	require.NotNil(t, result.Hold)
+	assert.Equal(t, hold.ID, result.Hold.ID)
+	assert.Equal(t, "30", result.Wallet.HeldBalance.String())
+	mockRepo.AssertNumberOfCalls(t, "CreateWalletHold", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperationIdempotent_KeysScopedByCaller(t *testing.T) {
+	w := ownedWallet(100, "merchant-2")
+	updated := w
+	updated.Balance = dec(150)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	// Тот же ключ у merchant-1 не виден: поиск идет только в пространстве merchant-2
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Caller: "owner:merchant-2", Key: "key-1"}).
+		Return(repository.IdempotencyKey{}, pgx.ErrNoRows)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 150)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
+	mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.MatchedBy(func(arg repository.CreateIdempotencyKeyParams) bool {
+		return arg.Caller == "owner:merchant-2" && arg.Key == "key-1"
+	})).Return(repository.IdempotencyKey{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, replayed, err := svc.ProcessOperationIdempotent(asOwner("merchant-2", auth.ScopeWalletWrite), "key-1",
+		wallet.Operation{WalletID: w.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperationIdempotent_KeyReusedWithDifferentRequest(t *testing.T) {
+	existing := makeWallet(100)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrIdempotencyKeyReused)
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "key-1"}).Return(repository.IdempotencyKey{
+		Key:         "key-1",
+		RequestHash: "0000000000000000000000000000000000000000000000000000000000000000",
+		Response:    []byte(`{}`),
+	}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, replayed, err := svc.ProcessOperationIdempotent(internalCtx(), "key-1", wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.ErrorIs(t, err, wallet.ErrIdempotencyKeyReused)
+	assert.False(t, replayed)
+	mockRepo.AssertNotCalled(t, "GetWalletForUpdate")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperationIdempotent_HashCoversHoldID(t *testing.T) {
+	w := makeWallet(100)
+	w.HeldBalance = dec(30)
+
+	voidHash := func(hold repository.WalletHold) string {
+		voided := hold
+		voided.Status = wallet.HoldStatusVoided
+
+		mockRepo := new(MockRepository)
+		withTxOK(mockRepo)
+		mockRepo.On("GetIdempotencyKey", mock.Anything, mock.Anything).Return(repository.IdempotencyKey{}, pgx.ErrNoRows)
+		mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+		mockRepo.On("GetWalletHoldForUpdate", mock.Anything, hold.ID).Return(hold, nil)
+		mockRepo.On("UpdateWalletHoldStatus", mock.Anything, mock.Anything).Return(voided, nil)
+		mockRepo.On("UpdateWalletBalance", mock.Anything, mock.Anything).Return(w, nil)
+		mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
+
+		var saved repository.CreateIdempotencyKeyParams
+		mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { saved = args.Get(1).(repository.CreateIdempotencyKeyParams) }).
+			Return(repository.IdempotencyKey{}, nil)
+
+		svc := wallet.New(mockRepo, zap.NewNop())
+		_, _, err := svc.ProcessOperationIdempotent(internalCtx(), "void-1", wallet.Operation{WalletID: w.ID, Type: wallet.OperationVoid, HoldID: &hold.ID})
+		require.NoError(t, err)
+		return saved.RequestHash
+	}
+
+	// Один ключ для отмены другого холда - это другой запрос, а не повтор
+	assert.NotEqual(t, voidHash(makeHold(w.ID, 30)), voidHash(makeHold(w.ID, 30)))
+}
+
+func TestProcessOperationIdempotent_ConcurrentKeyConflict(t *testing.T) {
+	existing := makeWallet(100)
+	updated := existing
+	updated.Balance = dec(150)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrIdempotencyKeyConflict)
+	mockRepo.On("GetIdempotencyKey", mock.Anything, repository.GetIdempotencyKeyParams{Key: "key-1"}).Return(repository.IdempotencyKey{}, pgx.ErrNoRows)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, mock.Anything).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
+	mockRepo.On("CreateIdempotencyKey", mock.Anything, mock.Anything).Return(repository.IdempotencyKey{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, _, err := svc.ProcessOperationIdempotent(internalCtx(), "key-1", wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.ErrorIs(t, err, wallet.ErrIdempotencyKeyConflict)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeExpiredIdempotencyKeys(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("DeleteExpiredIdempotencyKeys", mock.Anything).Return(int64(3), nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	n, err := svc.PurgeExpiredIdempotencyKeys(internalCtx())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	mockRepo.AssertExpectations(t)
+}
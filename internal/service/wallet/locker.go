@@ -1,9 +1,17 @@
 package wallet
 
 import (
+	"bytes"
+	"context"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	"tryingMicro/OrderAccepter/internal/tracing"
 )
 
 type walletLocker struct {
@@ -22,7 +30,11 @@ func newWalletLocker() *walletLocker {
 	}
 }
 
-func (l *walletLocker) Lock(id uuid.UUID) func() {
+// Lock ждет блокировку кошелька; спан покрывает только ожидание, не удержание
+func (l *walletLocker) Lock(ctx context.Context, id uuid.UUID) func() {
+	_, span := tracing.Tracer().Start(ctx, "wallet.lock",
+		trace.WithAttributes(attribute.String("wallet.id", id.String())))
+
 	l.mu.Lock()
 	e, ok := l.wallets[id]
 	if !ok {
@@ -32,7 +44,11 @@ func (l *walletLocker) Lock(id uuid.UUID) func() {
 	e.count++
 	l.mu.Unlock()
 
+	start := time.Now()
 	e.mu.Lock()
+	metrics.LockWait.Observe(time.Since(start).Seconds())
+	metrics.LocksHeld.Inc()
+	span.End()
 
 	return func() {
 		l.mu.Lock()
@@ -41,6 +57,31 @@ func (l *walletLocker) Lock(id uuid.UUID) func() {
 			delete(l.wallets, id)
 		}
 		l.mu.Unlock()
+		metrics.LocksHeld.Dec()
 		e.mu.Unlock()
 	}
 }
+
+// LockMany берет блокировки нескольких кошельков в порядке возрастания UUID,
+// чтобы встречные операции над одной парой кошельков не блокировали друг друга
+func (l *walletLocker) LockMany(ctx context.Context, ids ...uuid.UUID) func() {
+	ordered := sortedWalletIDs(ids)
+	unlocks := make([]func(), 0, len(ordered))
+	for _, id := range ordered {
+		unlocks = append(unlocks, l.Lock(ctx, id))
+	}
+
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+func sortedWalletIDs(ids []uuid.UUID) []uuid.UUID {
+	ordered := slices.Clone(ids)
+	slices.SortFunc(ordered, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return slices.Compact(ordered)
+}
@@ -1,20 +1,24 @@
 package wallet
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"tryingMicro/OrderAccepter/internal/metrics"
 )
 
 func TestWalletLocker_BasicLockUnlock(t *testing.T) {
 	locker := newWalletLocker()
 	id := uuid.New()
 
-	unlock := locker.Lock(id)
+	unlock := locker.Lock(context.Background(), id)
 	unlock()
 
 	locker.mu.Lock()
@@ -24,17 +28,34 @@ func TestWalletLocker_BasicLockUnlock(t *testing.T) {
 	assert.False(t, exists, "запись должна быть удалена после unlock")
 }
 
+func TestWalletLocker_Metrics(t *testing.T) {
+	locker := newWalletLocker()
+	waits := func() uint64 {
+		var m dto.Metric
+		_ = metrics.LockWait.Write(&m)
+		return m.GetHistogram().GetSampleCount()
+	}
+	held, observed := testutil.ToFloat64(metrics.LocksHeld), waits()
+
+	unlock := locker.LockMany(context.Background(), uuid.New(), uuid.New())
+	assert.Equal(t, held+2, testutil.ToFloat64(metrics.LocksHeld))
+	unlock()
+
+	assert.Equal(t, held, testutil.ToFloat64(metrics.LocksHeld))
+	assert.Equal(t, observed+2, waits())
+}
+
 func TestWalletLocker_DifferentWalletsDontBlock(t *testing.T) {
 	locker := newWalletLocker()
 	id1 := uuid.New()
 	id2 := uuid.New()
 
-	unlock1 := locker.Lock(id1)
+	unlock1 := locker.Lock(context.Background(), id1)
 	defer unlock1()
 
 	done := make(chan struct{})
 	go func() {
-		unlock2 := locker.Lock(id2)
+		unlock2 := locker.Lock(context.Background(), id2)
 		unlock2()
 		close(done)
 	}()
@@ -59,7 +80,7 @@ func TestWalletLocker_SameWalletIsSequential(t *testing.T) {
 		go func() {
 			defer wg.Done()
 
-			unlock := locker.Lock(id)
+			unlock := locker.Lock(context.Background(), id)
 			defer unlock()
 
 			current := atomic.AddInt64(&counter, 1)
@@ -91,7 +112,7 @@ func TestWalletLocker_DifferentWalletsAreParallel(t *testing.T) {
 		id := uuid.New()
 		go func(walletID uuid.UUID) {
 			defer wg.Done()
-			unlock := locker.Lock(walletID)
+			unlock := locker.Lock(context.Background(), walletID)
 			defer unlock()
 			time.Sleep(50 * time.Millisecond)
 		}(id)
@@ -115,7 +136,7 @@ func TestWalletLocker_CorrectCounterUnderConcurrency(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			unlock := locker.Lock(id)
+			unlock := locker.Lock(context.Background(), id)
 			defer unlock()
 			balance++
 		}()
@@ -131,7 +152,7 @@ func TestWalletLocker_NoMemoryLeak(t *testing.T) {
 
 	for i := 0; i < 1000; i++ {
 		id := uuid.New()
-		unlock := locker.Lock(id)
+		unlock := locker.Lock(context.Background(), id)
 		unlock()
 	}
 
@@ -141,3 +162,50 @@ func TestWalletLocker_NoMemoryLeak(t *testing.T) {
 
 	assert.Equal(t, 0, size, "map должна быть пустой после всех unlock")
 }
+
+func TestWalletLocker_LockManyOppositeOrderNoDeadlock(t *testing.T) {
+	locker := newWalletLocker()
+	id1 := uuid.New()
+	id2 := uuid.New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			unlock := locker.LockMany(context.Background(), id1, id2)
+			unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			unlock := locker.LockMany(context.Background(), id2, id1)
+			unlock()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("встречные переводы заблокировали друг друга")
+	}
+
+	locker.mu.Lock()
+	size := len(locker.wallets)
+	locker.mu.Unlock()
+
+	assert.Equal(t, 0, size, "map должна быть пустой после всех unlock")
+}
+
+func TestSortedWalletIDs_DeterministicAndUnique(t *testing.T) {
+	id1 := uuid.New()
+	id2 := uuid.New()
+
+	assert.Equal(t, sortedWalletIDs([]uuid.UUID{id1, id2}), sortedWalletIDs([]uuid.UUID{id2, id1}))
+	assert.Len(t, sortedWalletIDs([]uuid.UUID{id1, id1}), 1)
+}
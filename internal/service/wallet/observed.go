@@ -0,0 +1,177 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+// observedService считает каждый изменяющий вызов в метриках и, если задан
+// recorder, отмечает его в журнале аудита. Вызов через API описывается в
+// audit.Scope транспорта, который допишет маршрут, адрес и код ответа; вызов без
+// Scope записывается сразу. Чтение проходит без учета.
+type observedService struct {
+	WalletService
+	recorder audit.Recorder
+}
+
+func (a *observedService) ProcessOperation(ctx context.Context, op Operation) (OperationResult, error) {
+	result, err := a.WalletService.ProcessOperation(ctx, op)
+	a.observe(ctx, operationAction(op.Type), &op.WalletID, operationPayload{OperationType: op.Type, Amount: op.Amount, HoldID: op.HoldID}, err)
+	return result, err
+}
+
+func (a *observedService) ProcessOperationIdempotent(ctx context.Context, key string, op Operation) (OperationResult, bool, error) {
+	result, replayed, err := a.WalletService.ProcessOperationIdempotent(ctx, key, op)
+	a.observe(ctx, operationAction(op.Type), &op.WalletID, operationPayload{OperationType: op.Type, Amount: op.Amount, HoldID: op.HoldID, IdempotencyKey: key}, err)
+	return result, replayed, err
+}
+
+func (a *observedService) CreateWallet(ctx context.Context, params NewWallet) (repository.Wallet, error) {
+	w, err := a.WalletService.CreateWallet(ctx, params)
+	var walletID *uuid.UUID
+	if err == nil {
+		walletID = &w.ID
+	}
+	a.observe(ctx, "wallet.create", walletID, params, err)
+	return w, err
+}
+
+func (a *observedService) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (TransferResult, error) {
+	result, err := a.WalletService.Transfer(ctx, fromWalletID, toWalletID, amount)
+	a.observe(ctx, "wallet.transfer", &fromWalletID, transferPayload{To: toWalletID, Amount: amount}, err)
+	return result, err
+}
+
+func (a *observedService) Convert(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (ConversionResult, error) {
+	result, err := a.WalletService.Convert(ctx, fromWalletID, toWalletID, amount)
+	a.observe(ctx, "wallet.convert", &fromWalletID, transferPayload{To: toWalletID, Amount: amount}, err)
+	return result, err
+}
+
+func (a *observedService) ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) (ReversalResult, error) {
+	result, err := a.WalletService.ReverseTransaction(ctx, transactionID, amount)
+	var walletID *uuid.UUID
+	if err == nil {
+		walletID = &result.Wallet.ID
+	}
+	a.observe(ctx, "transaction.reverse", walletID, reversalPayload{TransactionID: transactionID, Amount: amount}, err)
+	return result, err
+}
+
+func (a *observedService) ChangeStatus(ctx context.Context, walletID uuid.UUID, change StatusChange) (repository.Wallet, error) {
+	w, err := a.WalletService.ChangeStatus(ctx, walletID, change)
+	a.observe(ctx, "wallet.status."+strings.ToLower(change.Status), &walletID, change, err)
+	return w, err
+}
+
+func (a *observedService) RequestAdjustment(ctx context.Context, walletID uuid.UUID, r AdjustmentRequest) (AdjustmentResult, error) {
+	result, err := a.WalletService.RequestAdjustment(ctx, walletID, r)
+	a.observe(ctx, "adjustment.request", &walletID, r, err)
+	return result, err
+}
+
+func (a *observedService) ApproveAdjustment(ctx context.Context, id uuid.UUID, actor string) (AdjustmentResult, error) {
+	result, err := a.WalletService.ApproveAdjustment(ctx, id, actor)
+	var walletID *uuid.UUID
+	if err == nil {
+		walletID = &result.Adjustment.WalletID
+	}
+	a.observe(ctx, "adjustment.approve", walletID, decisionPayload{AdjustmentID: id, Actor: actor}, err)
+	return result, err
+}
+
+func (a *observedService) RejectAdjustment(ctx context.Context, id uuid.UUID, actor, reason string) (repository.ManualAdjustment, error) {
+	result, err := a.WalletService.RejectAdjustment(ctx, id, actor, reason)
+	var walletID *uuid.UUID
+	if err == nil {
+		walletID = &result.WalletID
+	}
+	a.observe(ctx, "adjustment.reject", walletID, decisionPayload{AdjustmentID: id, Actor: actor, Reason: reason}, err)
+	return result, err
+}
+
+func (a *observedService) observe(ctx context.Context, action string, walletID *uuid.UUID, payload any, err error) {
+	metrics.Operations.WithLabelValues(action, outcomeLabel(err)).Inc()
+	if a.recorder == nil {
+		return
+	}
+	if scope, ok := audit.ScopeFrom(ctx); ok {
+		scope.Describe(action, walletID, err)
+		return
+	}
+	data, _ := json.Marshal(payload)
+	entry := audit.Entry{
+		Actor:         audit.Actor(ctx),
+		Action:        action,
+		WalletID:      walletID,
+		PayloadDigest: audit.Digest(data),
+		Outcome:       audit.Outcome(err),
+	}
+	// Ошибку записи логирует сам Recorder: операция уже проведена
+	_ = a.recorder.Record(ctx, entry)
+}
+
+func operationAction(opType string) string {
+	return "wallet.operation." + strings.ToLower(opType)
+}
+
+// outcomeLabels - ожидаемые отказы различаются в метриках, остальное - error
+var outcomeLabels = []struct {
+	err   error
+	label string
+}{
+	{ErrInsufficientFunds, "insufficient_funds"},
+	{ErrWalletNotFound, "not_found"},
+	{ErrWalletFrozen, "frozen"},
+	{ErrWalletClosed, "closed"},
+	{ErrInvalidAmount, "invalid"},
+	{ErrInvalidOperation, "invalid"},
+	{ErrCurrencyMismatch, "invalid"},
+	{ErrIdempotencyKeyConflict, "conflict"},
+	{ErrIdempotencyKeyReused, "conflict"},
+	{context.DeadlineExceeded, "timeout"},
+}
+
+func outcomeLabel(err error) string {
+	if err == nil {
+		return "success"
+	}
+	for _, o := range outcomeLabels {
+		if errors.Is(err, o.err) {
+			return o.label
+		}
+	}
+	return "error"
+}
+
+// Аргументы вызова без Scope: их digest заменяет digest тела запроса
+type operationPayload struct {
+	OperationType  string          `json:"operation_type"`
+	Amount         decimal.Decimal `json:"amount"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	HoldID         *uuid.UUID      `json:"hold_id,omitempty"`
+}
+
+type transferPayload struct {
+	To     uuid.UUID       `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type reversalPayload struct {
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	Amount        decimal.Decimal `json:"amount"`
+}
+
+type decisionPayload struct {
+	AdjustmentID uuid.UUID `json:"adjustment_id"`
+	Actor        string    `json:"actor"`
+	Reason       string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,70 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/metrics"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+type MockRecorder struct {
+	mock.Mock
+}
+
+func (m *MockRecorder) Record(ctx context.Context, e audit.Entry) error {
+	return m.Called(ctx, e).Error(0)
+}
+
+func TestObserved_DescribesTransportScope(t *testing.T) {
+	w := makeWallet(10)
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	// Отказ по недостатку средств фиксируется событием outbox уже после отката
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+	recorder := new(MockRecorder)
+
+	declined := metrics.Operations.WithLabelValues("wallet.operation.withdraw", "insufficient_funds")
+	before := testutil.ToFloat64(declined)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithAuditRecorder(recorder))
+	ctx, scope := audit.WithScope(internalCtx())
+	_, err := svc.ProcessOperation(ctx, wallet.Operation{WalletID: w.ID, Type: wallet.OperationWithdraw, Amount: dec(50)})
+
+	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	assert.Equal(t, before+1, testutil.ToFloat64(declined))
+	entry, described := scope.Entry()
+	require.True(t, described)
+	assert.Equal(t, "wallet.operation.withdraw", entry.Action)
+	assert.Equal(t, w.ID, *entry.WalletID)
+	assert.Equal(t, wallet.ErrInsufficientFunds.Error(), entry.Outcome)
+	// Запись сделает транспорт, когда узнает код ответа
+	recorder.AssertNotCalled(t, "Record")
+}
+
+func TestObserved_RecordsCallWithoutScope(t *testing.T) {
+	w := makeWallet(0)
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("CreateWallet", mock.Anything, mock.Anything).Return(w, nil)
+	recorder := new(MockRecorder)
+	recorder.On("Record", mock.Anything, mock.MatchedBy(func(e audit.Entry) bool {
+		return e.Action == "wallet.create" && e.Actor == "ops" && *e.WalletID == w.ID &&
+			e.Outcome == audit.OutcomeOK && e.PayloadDigest != ""
+	})).Return(nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop(), wallet.WithAuditRecorder(recorder))
+	ctx := auth.WithPrincipal(internalCtx(), auth.Principal{ID: "ops", Scopes: []string{auth.ScopeAdmin}})
+	_, err := svc.CreateWallet(ctx, wallet.NewWallet{Currency: "USD"})
+
+	require.NoError(t, err)
+	recorder.AssertExpectations(t)
+}
@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+)
+
+type Option func(*walletService)
+
+// WithIdempotencyKeyTTL задает, сколько хранится ответ по ключу идемпотентности
+func WithIdempotencyKeyTTL(ttl time.Duration) Option {
+	return func(s *walletService) {
+		if ttl > 0 {
+			s.idempotencyKeyTTL = ttl
+		}
+	}
+}
+
+// WithHoldTTL задает, через сколько неиспользованный холд снимается автоматически
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(s *walletService) {
+		if ttl > 0 {
+			s.holdTTL = ttl
+		}
+	}
+}
+
+// WithFrozenCredits разрешает или запрещает зачисления на замороженные кошельки
+func WithFrozenCredits(allowed bool) Option {
+	return func(s *walletService) {
+		s.frozenCreditsAllowed = allowed
+	}
+}
+
+// WithAdjustmentApprovalThreshold задает сумму, выше которой ручная корректировка
+// проводится только после подтверждения вторым сотрудником
+func WithAdjustmentApprovalThreshold(threshold decimal.Decimal) Option {
+	return func(s *walletService) {
+		if !threshold.IsNegative() {
+			s.adjustmentApprovalThreshold = threshold
+		}
+	}
+}
+
+// WithAuditRecorder включает запись изменяющих вызовов в журнал аудита
+func WithAuditRecorder(r audit.Recorder) Option {
+	return func(s *walletService) {
+		s.auditRecorder = r
+	}
+}
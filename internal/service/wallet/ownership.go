@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+const maxOwnerFieldLen = 128
+
+// NewWallet - параметры создания кошелька. Пустой OwnerID - владелец берется
+// из клиента в контексте; задать чужого владельца может только admin.
+type NewWallet struct {
+	Currency    string
+	OwnerID     string
+	ExternalRef string
+}
+
+// WalletLookup - поиск кошельков поддержкой: по владельцу и, если задана, по внешней ссылке
+type WalletLookup struct {
+	OwnerID     string
+	ExternalRef string
+}
+
+const maxLookupResults = 100
+
+// FindWallets ищет кошельки владельца. Поиск доступен только персоналу, поэтому
+// результаты не фильтруются через authorize.
+func (s *walletService) FindWallets(ctx context.Context, lookup WalletLookup) ([]repository.Wallet, error) {
+	owner := strings.TrimSpace(lookup.OwnerID)
+	ref := strings.TrimSpace(lookup.ExternalRef)
+	if owner == "" {
+		return nil, ErrInvalidOwner
+	}
+	if ref != "" {
+		w, err := s.repo.GetWalletByExternalRef(ctx, repository.GetWalletByExternalRefParams{OwnerID: &owner, ExternalRef: &ref})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return []repository.Wallet{}, nil
+			}
+			s.logger.Error("failed to find wallet by external ref", zap.String("ownerId", owner), zap.Error(err))
+			return nil, err
+		}
+		return []repository.Wallet{w}, nil
+	}
+	wallets, err := s.repo.ListWalletsByOwner(ctx, repository.ListWalletsByOwnerParams{OwnerID: &owner, Limit: maxLookupResults})
+	if err != nil {
+		s.logger.Error("failed to list owner wallets", zap.String("ownerId", owner), zap.Error(err))
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// authorize проверяет, что клиент из ctx может менять кошелек: владелец или admin.
+// Чужой кошелек неотличим от несуществующего, чтобы по ответам нельзя было перебирать ID.
+// Вызов без клиента запрещен, если он не помечен как внутренний через auth.WithInternal.
+func (s *walletService) authorize(ctx context.Context, w repository.Wallet) error {
+	return s.checkAccess(ctx, w, auth.CanWrite(ctx, w.OwnerID))
+}
+
+// authorizeRead - как authorize, но для чтения: роли поддержки видят любой кошелек
+func (s *walletService) authorizeRead(ctx context.Context, w repository.Wallet) error {
+	return s.checkAccess(ctx, w, auth.CanRead(ctx, w.OwnerID))
+}
+
+func (s *walletService) checkAccess(ctx context.Context, w repository.Wallet, allowed bool) error {
+	if allowed {
+		return nil
+	}
+	p, _ := auth.FromContext(ctx)
+	s.logger.Warn("wallet access denied", zap.String("walletId", w.ID.String()), zap.String("principal", p.ID))
+	return ErrWalletNotFound
+}
+
+// resolveOwner выбирает владельца нового кошелька
+func resolveOwner(ctx context.Context, requested string) (*string, error) {
+	requested = strings.TrimSpace(requested)
+	if len(requested) > maxOwnerFieldLen {
+		return nil, ErrInvalidOwner
+	}
+	p, ok := auth.FromContext(ctx)
+	if !ok && !auth.IsInternal(ctx) {
+		return nil, auth.ErrUnauthenticated
+	}
+	if ok && !p.HasScope(auth.ScopeAdmin) {
+		if requested != "" && requested != p.OwnerID {
+			return nil, ErrInvalidOwner
+		}
+		requested = p.OwnerID
+	}
+	if requested == "" {
+		return nil, nil
+	}
+	return &requested, nil
+}
@@ -0,0 +1,151 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func ownedWallet(balance int64, owner string) repository.Wallet {
+	w := makeWallet(balance)
+	w.OwnerID = &owner
+	return w
+}
+
+func asOwner(owner string, scopes ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{ID: "key-" + owner, OwnerID: owner, Scopes: scopes})
+}
+
+func TestGetBalance_Ownership(t *testing.T) {
+	w := ownedWallet(100, "merchant-1")
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+	svc := wallet.New(mockRepo, zap.NewNop())
+
+	_, err := svc.GetBalance(asOwner("merchant-1", auth.ScopeWalletRead), w.ID)
+	require.NoError(t, err)
+
+	// Чужой кошелек выглядит как несуществующий
+	_, err = svc.GetBalance(asOwner("merchant-2", auth.ScopeWalletRead), w.ID)
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+
+	_, err = svc.GetBalance(asOwner("", auth.ScopeAdmin), w.ID)
+	require.NoError(t, err)
+
+	// Без клиента вызов запрещен, если он не помечен внутренним
+	_, err = svc.GetBalance(context.Background(), w.ID)
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	_, err = svc.GetBalance(internalCtx(), w.ID)
+	require.NoError(t, err)
+}
+
+func TestGetBalance_UnownedWalletAdminOnly(t *testing.T) {
+	w := makeWallet(100)
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+	svc := wallet.New(mockRepo, zap.NewNop())
+
+	_, err := svc.GetBalance(asOwner("merchant-1", auth.ScopeWalletRead), w.ID)
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+}
+
+func TestProcessOperation_ForeignWallet(t *testing.T) {
+	w := ownedWallet(100, "merchant-1")
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrWalletNotFound)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(asOwner("merchant-2", auth.ScopeWalletWrite), wallet.Operation{WalletID: w.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertNotCalled(t, "CreateWalletTransaction")
+}
+
+func TestCreateWallet_OwnerFromPrincipal(t *testing.T) {
+	expected := ownedWallet(0, "merchant-1")
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("CreateWallet", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletParams) bool {
+		return arg.OwnerID != nil && *arg.OwnerID == "merchant-1" &&
+			arg.ExternalRef != nil && *arg.ExternalRef == "order-42"
+	})).Return(expected, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.CreateWallet(asOwner("merchant-1", auth.ScopeWalletWrite), wallet.NewWallet{ExternalRef: " order-42 "})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateWallet_ForeignOwner(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := wallet.New(mockRepo, zap.NewNop())
+
+	_, err := svc.CreateWallet(asOwner("merchant-1", auth.ScopeWalletWrite), wallet.NewWallet{OwnerID: "merchant-2"})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidOwner)
+	mockRepo.AssertNotCalled(t, "CreateWallet")
+}
+
+func TestCreateWallet_RequiresPrincipal(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := wallet.New(mockRepo, zap.NewNop())
+
+	_, err := svc.CreateWallet(context.Background(), wallet.NewWallet{OwnerID: "merchant-1"})
+
+	require.ErrorIs(t, err, auth.ErrUnauthenticated)
+	mockRepo.AssertNotCalled(t, "CreateWallet")
+}
+
+func TestCreateWallet_AdminAssignsOwner(t *testing.T) {
+	expected := ownedWallet(0, "merchant-2")
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("CreateWallet", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletParams) bool {
+		return arg.OwnerID != nil && *arg.OwnerID == "merchant-2" && arg.ExternalRef == nil
+	})).Return(expected, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.CreateWallet(asOwner("", auth.ScopeAdmin), wallet.NewWallet{OwnerID: "merchant-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "merchant-2", *result.OwnerID)
+}
+
+func TestCreateWallet_ExternalRefExists(t *testing.T) {
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrExternalRefExists)
+	mockRepo.On("CreateWallet", mock.Anything, mock.Anything).Return(repository.Wallet{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.CreateWallet(asOwner("merchant-1", auth.ScopeWalletWrite), wallet.NewWallet{ExternalRef: "order-42"})
+
+	require.ErrorIs(t, err, wallet.ErrExternalRefExists)
+}
+
+func TestOwnership_SupportReadsButDoesNotWrite(t *testing.T) {
+	w := ownedWallet(100, "merchant-1")
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrWalletNotFound)
+	mockRepo.On("GetWallet", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	svc := wallet.New(mockRepo, zap.NewNop())
+	ctx := asOwner("", auth.RoleViewer, auth.ScopeWalletWrite)
+
+	_, err := svc.GetBalance(ctx, w.ID)
+	require.NoError(t, err)
+
+	_, err = svc.ProcessOperation(ctx, wallet.Operation{WalletID: w.ID, Type: wallet.OperationWithdraw, Amount: dec(50)})
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+const OperationReversal = "REVERSAL"
+
+// ReversalResult - состояние кошелька, компенсирующая запись и исходная операция после сторно
+type ReversalResult struct {
+	Wallet   repository.Wallet
+	Reversal Transaction
+	Original Transaction
+}
+
+// ReverseTransaction создает компенсирующую запись для DEPOSIT или WITHDRAW.
+// Нулевой amount сторнирует весь еще не сторнированный остаток. Сумма всех
+// сторно по операции не может превышать ее сумму.
+func (s *walletService) ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) (ReversalResult, error) {
+	if !amount.IsZero() {
+		if err := money.Validate(amount); err != nil {
+			return ReversalResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+		}
+	}
+
+	// Кошелек нужен до блокировки: порядок блокировок кошелек -> запись журнала
+	original, err := s.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return ReversalResult{}, err
+	}
+
+	unlock := s.locker.Lock(ctx, original.WalletID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result ReversalResult
+
+	err = s.repo.WithTx(ctx, func(q repository.Querier) error {
+		w, err := s.getWalletForUpdate(ctx, q, original.WalletID)
+		if err != nil {
+			return err
+		}
+		// Сторно - действие поддержки, маршрут закрыт ролью operator. Проверка владельца
+		// остается второй линией защиты, роли поддержки проходят ее как при чтении.
+		// Операция по чужому кошельку выглядит как несуществующая
+		if s.authorizeRead(ctx, w) != nil {
+			return ErrTransactionNotFound
+		}
+		t, err := q.GetWalletTransactionForUpdate(ctx, transactionID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTransactionNotFound
+			}
+			s.logger.Error("failed to get transaction for update", zap.String("transactionId", transactionID.String()), zap.Error(err))
+			return err
+		}
+
+		// Исходная запись заблокирована, поэтому сумма сторно не изменится до конца транзакции
+		reversed, err := q.GetReversedAmount(ctx, &t.ID)
+		if err != nil {
+			s.logger.Error("failed to get reversed amount", zap.String("transactionId", transactionID.String()), zap.Error(err))
+			return err
+		}
+
+		var newBalance decimal.Decimal
+		remaining := t.Amount.Sub(reversed)
+		if !remaining.IsPositive() {
+			return ErrTransactionAlreadyReversed
+		}
+		reverseAmount := amount
+		if reverseAmount.IsZero() {
+			reverseAmount = remaining
+		}
+		if err = money.ValidateForCurrency(reverseAmount, w.Currency); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+		}
+		if reverseAmount.GreaterThan(remaining) {
+			return fmt.Errorf("%w: reversal exceeds remaining amount %s", ErrInvalidAmount, remaining)
+		}
+
+		switch t.Type {
+		case OperationDeposit:
+			if err = s.checkDebit(w); err != nil {
+				return err
+			}
+			if AvailableBalance(w).LessThan(reverseAmount) {
+				s.logger.Warn("reversal exceeds balance", zap.String("transactionId", transactionID.String()), zap.Stringer("available", AvailableBalance(w)), zap.Stringer("amount", reverseAmount))
+				return ErrReversalExceedsBalance
+			}
+			newBalance = w.Balance.Sub(reverseAmount)
+		case OperationWithdraw:
+			if err = s.checkCredit(w); err != nil {
+				return err
+			}
+			newBalance = w.Balance.Add(reverseAmount)
+		default:
+			return ErrTransactionNotReversible
+		}
+
+		updated, entry, err := s.applyLedgerEntry(ctx, q, w, ledgerEntry{
+			opType:     OperationReversal,
+			amount:     reverseAmount,
+			newBalance: newBalance,
+			reversalOf: &t.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result = ReversalResult{
+			Wallet:   updated,
+			Reversal: NewTransaction(entry),
+			Original: NewTransaction(t),
+		}
+		result.Original.ReversedAmount = reversed.Add(reverseAmount)
+		return nil
+	})
+
+	if err == nil {
+		s.logger.Info("wallet transaction reversed",
+			zap.String("transactionId", transactionID.String()),
+			zap.String("walletId", original.WalletID.String()),
+			zap.Stringer("amount", result.Reversal.Amount),
+		)
+	}
+
+	return result, err
+}
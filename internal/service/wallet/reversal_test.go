@@ -0,0 +1,142 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func makeLedgerEntry(walletID uuid.UUID, opType string, amount int64) repository.WalletTransaction {
+	return repository.WalletTransaction{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Type:      opType,
+		Amount:    dec(amount),
+		CreatedAt: time.Now(),
+	}
+}
+
+func reversalSetup(w repository.Wallet, original repository.WalletTransaction, reversed int64, withTxResult error) *MockRepository {
+	mockRepo := new(MockRepository)
+	if withTxResult == nil {
+		withTxOK(mockRepo)
+	} else {
+		withTxErr(mockRepo, withTxResult)
+	}
+	mockRepo.On("GetWalletTransaction", mock.Anything, original.ID).Return(original, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("GetWalletTransactionForUpdate", mock.Anything, original.ID).Return(original, nil)
+	mockRepo.On("GetReversedAmount", mock.Anything, &original.ID).Return(dec(reversed), nil)
+	return mockRepo
+}
+
+func TestReverseTransaction_FullDeposit(t *testing.T) {
+	w := makeWallet(100)
+	original := makeLedgerEntry(w.ID, wallet.OperationDeposit, 40)
+	updated := w
+	updated.Balance = dec(60)
+
+	mockRepo := reversalSetup(w, original, 0, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 60)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.Type == wallet.OperationReversal && arg.Amount.Equal(dec(40)) &&
+			arg.ReversalOf != nil && *arg.ReversalOf == original.ID
+	})).Return(repository.WalletTransaction{Type: wallet.OperationReversal, Amount: dec(40), ReversalOf: &original.ID}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ReverseTransaction(internalCtx(), original.ID, decimal.Zero)
+
+	require.NoError(t, err)
+	assert.Equal(t, "60", result.Wallet.Balance.String())
+	assert.Equal(t, original.ID, *result.Reversal.ReversalOf)
+	assert.Equal(t, "40", result.Original.ReversedAmount.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseTransaction_PartialWithdraw(t *testing.T) {
+	w := makeWallet(100)
+	original := makeLedgerEntry(w.ID, wallet.OperationWithdraw, 50)
+
+	mockRepo := reversalSetup(w, original, 20, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 110)).Return(w, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ReverseTransaction(internalCtx(), original.ID, dec(10))
+
+	require.NoError(t, err)
+	assert.Equal(t, "30", result.Original.ReversedAmount.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseTransaction_AlreadyReversed(t *testing.T) {
+	w := makeWallet(100)
+	original := makeLedgerEntry(w.ID, wallet.OperationDeposit, 40)
+
+	mockRepo := reversalSetup(w, original, 40, wallet.ErrTransactionAlreadyReversed)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ReverseTransaction(internalCtx(), original.ID, decimal.Zero)
+
+	require.ErrorIs(t, err, wallet.ErrTransactionAlreadyReversed)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
+
+func TestReverseTransaction_ExceedsRemaining(t *testing.T) {
+	w := makeWallet(100)
+	original := makeLedgerEntry(w.ID, wallet.OperationWithdraw, 50)
+
+	mockRepo := reversalSetup(w, original, 45, wallet.ErrInvalidAmount)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ReverseTransaction(internalCtx(), original.ID, dec(10))
+
+	require.ErrorIs(t, err, wallet.ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "CreateWalletTransaction")
+}
+
+func TestReverseTransaction_DepositExceedsBalance(t *testing.T) {
+	w := makeWallet(30)
+	original := makeLedgerEntry(w.ID, wallet.OperationDeposit, 40)
+
+	mockRepo := reversalSetup(w, original, 0, wallet.ErrReversalExceedsBalance)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ReverseTransaction(internalCtx(), original.ID, decimal.Zero)
+
+	require.ErrorIs(t, err, wallet.ErrReversalExceedsBalance)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
+
+func TestReverseTransaction_NotReversible(t *testing.T) {
+	w := makeWallet(100)
+	original := makeLedgerEntry(w.ID, wallet.OperationTransferIn, 40)
+
+	mockRepo := reversalSetup(w, original, 0, wallet.ErrTransactionNotReversible)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ReverseTransaction(internalCtx(), original.ID, decimal.Zero)
+
+	require.ErrorIs(t, err, wallet.ErrTransactionNotReversible)
+}
+
+func TestReverseTransaction_NotFound(t *testing.T) {
+	id := uuid.New()
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWalletTransaction", mock.Anything, id).Return(repository.WalletTransaction{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ReverseTransaction(internalCtx(), id, decimal.Zero)
+
+	require.ErrorIs(t, err, wallet.ErrTransactionNotFound)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
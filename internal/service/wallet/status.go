@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+)
+
+const (
+	WalletStatusActive = "ACTIVE"
+	WalletStatusFrozen = "FROZEN"
+	WalletStatusClosed = "CLOSED"
+)
+
+// StatusChange - запрос на смену статуса кошелька с причиной и инициатором
+type StatusChange struct {
+	Status string
+	Reason string
+	Actor  string
+}
+
+// Допустимые переходы: заморозка и разморозка активного кошелька, закрытие из любого незакрытого
+var statusTransitions = map[string][]string{
+	WalletStatusActive: {WalletStatusFrozen, WalletStatusClosed},
+	WalletStatusFrozen: {WalletStatusActive, WalletStatusClosed},
+}
+
+// ChangeStatus переводит кошелек в новый статус и записывает смену в wallet_status_changes
+// и событие wallet.status_changed в outbox. Закрыть можно только кошелек с нулевым балансом.
+func (s *walletService) ChangeStatus(ctx context.Context, walletID uuid.UUID, change StatusChange) (repository.Wallet, error) {
+	change.Reason = strings.TrimSpace(change.Reason)
+	change.Actor = strings.TrimSpace(change.Actor)
+	if change.Reason == "" || change.Actor == "" {
+		return repository.Wallet{}, fmt.Errorf("%w: reason and actor are required", ErrInvalidStatusTransition)
+	}
+
+	unlock := s.locker.Lock(ctx, walletID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var result repository.Wallet
+
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		w, err := s.getWalletForUpdate(ctx, q, walletID)
+		if err != nil {
+			return err
+		}
+		if !canTransition(w.Status, change.Status) {
+			if w.Status == WalletStatusClosed {
+				return ErrWalletClosed
+			}
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, w.Status, change.Status)
+		}
+		if change.Status == WalletStatusClosed && !w.Balance.IsZero() {
+			return ErrWalletNotEmpty
+		}
+
+		result, err = q.UpdateWalletStatus(ctx, repository.UpdateWalletStatusParams{
+			ID:     walletID,
+			Status: change.Status,
+		})
+		if err != nil {
+			s.logger.Error("failed to update wallet status", zap.String("walletId", walletID.String()), zap.Error(err))
+			return err
+		}
+
+		_, err = q.CreateWalletStatusChange(ctx, repository.CreateWalletStatusChangeParams{
+			ID:         uuid.New(),
+			WalletID:   walletID,
+			FromStatus: w.Status,
+			ToStatus:   change.Status,
+			Reason:     change.Reason,
+			Actor:      change.Actor,
+		})
+		if err != nil {
+			s.logger.Error("failed to record wallet status change", zap.String("walletId", walletID.String()), zap.Error(err))
+			return err
+		}
+		return s.recordEvent(ctx, q, outbox.StatusChanged(result, w.Status))
+	})
+
+	if err == nil {
+		s.logger.Info("wallet status changed",
+			zap.String("walletId", walletID.String()),
+			zap.String("status", change.Status),
+			zap.String("actor", change.Actor),
+		)
+	}
+
+	return result, err
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDebit проверяет, что с кошелька можно списывать средства
+func (s *walletService) checkDebit(w repository.Wallet) error {
+	switch w.Status {
+	case WalletStatusFrozen:
+		s.logger.Warn("debit on frozen wallet refused", zap.String("walletId", w.ID.String()))
+		return ErrWalletFrozen
+	case WalletStatusClosed:
+		return ErrWalletClosed
+	}
+	return nil
+}
+
+// checkCredit проверяет, что на кошелек можно зачислять средства.
+// Зачисления на замороженный кошелек разрешены, если не отключены WithFrozenCredits.
+func (s *walletService) checkCredit(w repository.Wallet) error {
+	switch w.Status {
+	case WalletStatusFrozen:
+		if !s.frozenCreditsAllowed {
+			s.logger.Warn("credit on frozen wallet refused", zap.String("walletId", w.ID.String()))
+			return ErrWalletFrozen
+		}
+	case WalletStatusClosed:
+		return ErrWalletClosed
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package wallet_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/internal/service/wallet"
+)
+
+func TestChangeStatus_Freeze(t *testing.T) {
+	w := makeWallet(100)
+	frozen := w
+	frozen.Status = wallet.WalletStatusFrozen
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("UpdateWalletStatus", mock.Anything, repository.UpdateWalletStatusParams{ID: w.ID, Status: wallet.WalletStatusFrozen}).
+		Return(frozen, nil)
+	mockRepo.On("CreateWalletStatusChange", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletStatusChangeParams) bool {
+		return arg.WalletID == w.ID && arg.FromStatus == wallet.WalletStatusActive && arg.ToStatus == wallet.WalletStatusFrozen &&
+			arg.Reason == "chargeback investigation" && arg.Actor == "ops@example.com"
+	})).Return(repository.WalletStatusChange{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ChangeStatus(internalCtx(), w.ID, wallet.StatusChange{
+		Status: wallet.WalletStatusFrozen,
+		Reason: " chargeback investigation ",
+		Actor:  "ops@example.com",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, wallet.WalletStatusFrozen, result.Status)
+	mockRepo.AssertCalled(t, "CreateWalletEvent", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletEventParams) bool {
+		var event outbox.Event
+		return arg.WalletID == w.ID && arg.EventType == "wallet.status_changed" &&
+			json.Unmarshal(arg.Payload, &event) == nil &&
+			event.Status == wallet.WalletStatusFrozen && event.PreviousStatus == wallet.WalletStatusActive
+	}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangeStatus_CloseRequiresZeroBalance(t *testing.T) {
+	w := makeWallet(10)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrWalletNotEmpty)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ChangeStatus(internalCtx(), w.ID, wallet.StatusChange{Status: wallet.WalletStatusClosed, Reason: "user request", Actor: "ops"})
+
+	require.ErrorIs(t, err, wallet.ErrWalletNotEmpty)
+	mockRepo.AssertNotCalled(t, "UpdateWalletStatus")
+}
+
+func TestChangeStatus_InvalidTransition(t *testing.T) {
+	w := makeWallet(0)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInvalidStatusTransition)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ChangeStatus(internalCtx(), w.ID, wallet.StatusChange{Status: wallet.WalletStatusActive, Reason: "unfreeze", Actor: "ops"})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidStatusTransition)
+}
+
+func TestChangeStatus_ReasonRequired(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ChangeStatus(internalCtx(), makeWallet(0).ID, wallet.StatusChange{Status: wallet.WalletStatusFrozen, Actor: "ops"})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidStatusTransition)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
+
+func TestProcessOperation_FrozenWallet(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  string
+		opType  string
+		opts    []wallet.Option
+		wantErr error
+	}{
+		{"withdraw from frozen", wallet.WalletStatusFrozen, wallet.OperationWithdraw, nil, wallet.ErrWalletFrozen},
+		{"deposit to frozen when credits disabled", wallet.WalletStatusFrozen, wallet.OperationDeposit, []wallet.Option{wallet.WithFrozenCredits(false)}, wallet.ErrWalletFrozen},
+		{"deposit to closed", wallet.WalletStatusClosed, wallet.OperationDeposit, nil, wallet.ErrWalletClosed},
+		{"withdraw from closed", wallet.WalletStatusClosed, wallet.OperationWithdraw, nil, wallet.ErrWalletClosed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := makeWallet(100)
+			w.Status = tc.status
+
+			mockRepo := new(MockRepository)
+			withTxErr(mockRepo, tc.wantErr)
+			mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+
+			svc := wallet.New(mockRepo, zap.NewNop(), tc.opts...)
+			_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: tc.opType, Amount: dec(10)})
+
+			require.ErrorIs(t, err, tc.wantErr)
+			mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+		})
+	}
+}
+
+func TestProcessOperation_DepositToFrozenAllowedByDefault(t *testing.T) {
+	w := makeWallet(100)
+	w.Status = wallet.WalletStatusFrozen
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, w.ID).Return(w, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(w.ID, 110)).Return(w, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: w.ID, Type: wallet.OperationDeposit, Amount: dec(10)})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_ToClosedWallet(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+	to.Status = wallet.WalletStatusClosed
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrWalletClosed)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Transfer(internalCtx(), from.ID, to.ID, dec(10))
+
+	require.ErrorIs(t, err, wallet.ErrWalletClosed)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+}
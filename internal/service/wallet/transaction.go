@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"tryingMicro/OrderAccepter/internal/repository"
+)
+
+// Transaction - запись журнала операций по кошельку
+type Transaction struct {
+	ID            uuid.UUID       `json:"id"`
+	WalletID      uuid.UUID       `json:"wallet_id"`
+	Type          string          `json:"type"`
+	Amount        decimal.Decimal `json:"amount"`
+	BalanceBefore decimal.Decimal `json:"balance_before"`
+	BalanceAfter  decimal.Decimal `json:"balance_after"`
+	CreatedAt     time.Time       `json:"created_at"`
+
+	CounterpartyWalletID *uuid.UUID       `json:"counterparty_wallet_id,omitempty"`
+	FxRate               *decimal.Decimal `json:"fx_rate,omitempty"`
+	FxRemainder          *decimal.Decimal `json:"fx_remainder,omitempty"`
+	HoldID               *uuid.UUID       `json:"hold_id,omitempty"`
+	ReversalOf           *uuid.UUID       `json:"reversal_of,omitempty"`
+	// ReversedAmount - сумма компенсирующих записей; в журнале не хранится, считается при чтении
+	ReversedAmount decimal.Decimal `json:"reversed_amount"`
+}
+
+// NewTransaction строит запись журнала в том виде, в каком ее отдает API.
+// ReversedAmount остается нулевым: его заполняет тот, кто читает сторно.
+func NewTransaction(t repository.WalletTransaction) Transaction {
+	return Transaction{
+		ID:            t.ID,
+		WalletID:      t.WalletID,
+		Type:          t.Type,
+		Amount:        t.Amount,
+		BalanceBefore: t.BalanceBefore,
+		BalanceAfter:  t.BalanceAfter,
+		CreatedAt:     t.CreatedAt,
+
+		CounterpartyWalletID: t.CounterpartyWalletID,
+		FxRate:               t.FxRate,
+		FxRemainder:          t.FxRemainder,
+		HoldID:               t.HoldID,
+		ReversalOf:           t.ReversalOf,
+	}
+}
+
+const (
+	DefaultTransactionsLimit = 50
+	MaxTransactionsLimit     = 100
+)
+
+// TransactionFilter - параметры выборки истории операций, нулевые значения не фильтруют
+type TransactionFilter struct {
+	Type      string
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+// Курсор - позиция последней записи страницы в порядке (created_at, id) DESC
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	createdAtRaw, idRaw, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
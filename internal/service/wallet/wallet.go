@@ -3,91 +3,440 @@ package wallet
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/audit"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/internal/tracing"
 	"tryingMicro/OrderAccepter/package/logger"
+	"tryingMicro/OrderAccepter/package/money"
 )
 
 const (
-	OperationDeposit  = "DEPOSIT"
-	OperationWithdraw = "WITHDRAW"
+	OperationDeposit     = "DEPOSIT"
+	OperationWithdraw    = "WITHDRAW"
+	OperationTransferIn  = "TRANSFER_IN"
+	OperationTransferOut = "TRANSFER_OUT"
+	OperationConvertIn   = "CONVERT_IN"
+	OperationConvertOut  = "CONVERT_OUT"
 )
 
 type WalletService interface {
-	ProcessOperation(ctx context.Context, walletID uuid.UUID, opType string, amount float64) (repository.Wallet, error)
+	// ProcessOperation выполняет DEPOSIT, WITHDRAW, HOLD, CAPTURE или VOID
+	ProcessOperation(ctx context.Context, op Operation) (OperationResult, error)
 	GetBalance(ctx context.Context, walletID uuid.UUID) (repository.Wallet, error)
-	CreateWallet(ctx context.Context) (repository.Wallet, error)
+	CreateWallet(ctx context.Context, params NewWallet) (repository.Wallet, error)
+	GetTransaction(ctx context.Context, transactionID uuid.UUID) (Transaction, error)
+	ListTransactions(ctx context.Context, walletID uuid.UUID, filter TransactionFilter) (TransactionPage, error)
+	Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (TransferResult, error)
+	Convert(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (ConversionResult, error)
+	ProcessOperationIdempotent(ctx context.Context, key string, op Operation) (result OperationResult, replayed bool, err error)
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	ExpireHolds(ctx context.Context) (int, error)
+	ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) (ReversalResult, error)
+	ChangeStatus(ctx context.Context, walletID uuid.UUID, change StatusChange) (repository.Wallet, error)
+	FindWallets(ctx context.Context, lookup WalletLookup) ([]repository.Wallet, error)
+	RequestAdjustment(ctx context.Context, walletID uuid.UUID, r AdjustmentRequest) (AdjustmentResult, error)
+	ApproveAdjustment(ctx context.Context, id uuid.UUID, actor string) (AdjustmentResult, error)
+	RejectAdjustment(ctx context.Context, id uuid.UUID, actor, reason string) (repository.ManualAdjustment, error)
+	ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]repository.ManualAdjustment, error)
+}
+
+// Operation - изменение баланса одного кошелька.
+// HOLD резервирует Amount из доступного баланса.
+// CAPTURE списывает Amount (или всю сумму холда, если Amount нулевой) и освобождает остаток.
+// VOID освобождает резерв целиком, Amount игнорируется.
+type Operation struct {
+	WalletID uuid.UUID
+	Type     string
+	Amount   decimal.Decimal
+	// HoldID обязателен для CAPTURE и VOID
+	HoldID *uuid.UUID
+}
+
+// ValidateAmount проверяет сумму, если операции она нужна
+func (op Operation) ValidateAmount() error {
+	switch {
+	case op.Type == OperationVoid:
+		return nil
+	case op.Type == OperationCapture && op.Amount.IsZero():
+		return nil
+	}
+	return money.Validate(op.Amount)
+}
+
+// validate проверяет операцию до блокировки кошелька
+func (op Operation) validate() error {
+	switch op.Type {
+	case OperationDeposit, OperationWithdraw, OperationHold:
+	case OperationCapture, OperationVoid:
+		if op.HoldID == nil {
+			return ErrHoldIDRequired
+		}
+	default:
+		return ErrInvalidOperation
+	}
+	if err := op.ValidateAmount(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	return nil
+}
+
+// OperationResult - кошелек после операции. Hold заполнен для HOLD, CAPTURE и VOID
+type OperationResult struct {
+	Wallet repository.Wallet `json:"wallet"`
+	Hold   *Hold             `json:"hold,omitempty"`
+}
+
+// TransferResult - состояние обоих кошельков после перевода
+type TransferResult struct {
+	From repository.Wallet
+	To   repository.Wallet
 }
 
 type walletService struct {
 	repo   repository.Repository
 	logger logger.Logger
 	locker *walletLocker
+
+	idempotencyKeyTTL time.Duration
+	holdTTL           time.Duration
+
+	frozenCreditsAllowed bool
+
+	adjustmentApprovalThreshold decimal.Decimal
+
+	auditRecorder audit.Recorder
 }
 
-func New(repo repository.Repository, log logger.Logger) WalletService {
-	return &walletService{
+func New(repo repository.Repository, log logger.Logger, opts ...Option) WalletService {
+	s := &walletService{
 		repo:   repo,
 		logger: log,
 		locker: newWalletLocker(),
+
+		idempotencyKeyTTL: DefaultIdempotencyKeyTTL,
+		holdTTL:           DefaultHoldTTL,
+
+		frozenCreditsAllowed: true,
+
+		adjustmentApprovalThreshold: DefaultAdjustmentApprovalThreshold,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return &observedService{WalletService: s, recorder: s.auditRecorder}
+}
+
+func (s *walletService) ProcessOperation(ctx context.Context, op Operation) (_ OperationResult, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "wallet.ProcessOperation", trace.WithAttributes(
+		attribute.String("wallet.id", op.WalletID.String()),
+		attribute.String("wallet.operation", op.Type),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := op.validate(); err != nil {
+		return OperationResult{}, err
+	}
+
+	var result OperationResult
+	err = s.inWalletTx(ctx, op.WalletID, func(ctx context.Context, q repository.Querier) error {
+		var err error
+		result, err = s.processOperation(ctx, q, op)
+		return err
+	})
+
+	if errors.Is(err, ErrInsufficientFunds) {
+		s.recordInsufficientFunds(ctx, op.WalletID, op.Type, op.Amount)
+	}
+	if err == nil {
+		s.logOperation(op, result)
+	}
+
+	return result, err
+}
+
+// processOperation применяет операцию к кошельку в рамках транзакции q
+func (s *walletService) processOperation(ctx context.Context, q repository.Querier, op Operation) (OperationResult, error) {
+	w, err := s.getWalletForUpdate(ctx, q, op.WalletID)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	if err = s.authorize(ctx, w); err != nil {
+		return OperationResult{}, err
+	}
+
+	switch op.Type {
+	case OperationDeposit, OperationWithdraw:
+	case OperationHold:
+		return s.createHold(ctx, q, w, op.Amount)
+	default:
+		hold, err := s.getActiveHoldForUpdate(ctx, q, op.WalletID, *op.HoldID)
+		if err != nil {
+			return OperationResult{}, err
+		}
+		if op.Type == OperationCapture {
+			return s.captureHold(ctx, q, w, hold, op.Amount)
+		}
+		return s.releaseHold(ctx, q, w, hold, OperationVoid, HoldStatusVoided)
+	}
+
+	if err = money.ValidateForCurrency(op.Amount, w.Currency); err != nil {
+		return OperationResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	if op.Type == OperationDeposit {
+		if err = s.checkCredit(w); err != nil {
+			return OperationResult{}, err
+		}
+		w, _, err = s.credit(ctx, q, w, op.Type, op.Amount)
+	} else {
+		if err = s.checkDebit(w); err != nil {
+			return OperationResult{}, err
+		}
+		w, _, err = s.debit(ctx, q, w, op.Type, op.Amount)
+	}
+	return OperationResult{Wallet: w}, err
+}
+
+func (s *walletService) logOperation(op Operation, result OperationResult) {
+	fields := []zap.Field{
+		zap.String("walletId", op.WalletID.String()),
+		zap.String("operation", op.Type),
+		zap.Stringer("amount", op.Amount),
+	}
+	if result.Hold != nil {
+		fields = append(fields, zap.String("holdId", result.Hold.ID.String()), zap.Stringer("holdAmount", result.Hold.Amount))
 	}
+	s.logger.Info("wallet operation completed", fields...)
+}
+
+// inWalletTx выполняет fn под блокировкой кошелька в транзакции, ограниченной
+// по времени. Так проходят все операции над одним кошельком.
+func (s *walletService) inWalletTx(ctx context.Context, walletID uuid.UUID, fn func(ctx context.Context, q repository.Querier) error) error {
+	unlock := s.locker.Lock(ctx, walletID)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.WithTx(ctx, func(q repository.Querier) error {
+		return fn(ctx, q)
+	})
+}
+
+// credit зачисляет amount на заблокированный кошелек w
+func (s *walletService) credit(ctx context.Context, q repository.Querier, w repository.Wallet, opType string, amount decimal.Decimal) (repository.Wallet, repository.WalletTransaction, error) {
+	return s.applyLedgerEntry(ctx, q, w, ledgerEntry{
+		opType:     opType,
+		amount:     amount,
+		newBalance: w.Balance.Add(amount),
+	})
+}
+
+// debit списывает amount с заблокированного кошелька w в пределах доступного баланса
+func (s *walletService) debit(ctx context.Context, q repository.Querier, w repository.Wallet, opType string, amount decimal.Decimal) (repository.Wallet, repository.WalletTransaction, error) {
+	if AvailableBalance(w).LessThan(amount) {
+		s.logger.Warn("insufficient funds", zap.String("walletId", w.ID.String()), zap.Stringer("available", AvailableBalance(w)), zap.Stringer("amount", amount))
+		return repository.Wallet{}, repository.WalletTransaction{}, ErrInsufficientFunds
+	}
+	return s.applyLedgerEntry(ctx, q, w, ledgerEntry{
+		opType:     opType,
+		amount:     amount,
+		newBalance: w.Balance.Sub(amount),
+	})
 }
 
-func (s *walletService) ProcessOperation(ctx context.Context, walletID uuid.UUID, opType string, amount float64) (repository.Wallet, error) {
-	unlock := s.locker.Lock(walletID)
+func (s *walletService) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount decimal.Decimal) (TransferResult, error) {
+	if fromWalletID == toWalletID {
+		return TransferResult{}, ErrSameWallet
+	}
+	if err := money.Validate(amount); err != nil {
+		return TransferResult{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+
+	unlock := s.locker.LockMany(ctx, fromWalletID, toWalletID)
 	defer unlock()
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var result repository.Wallet
+	var result TransferResult
 
 	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
-		w, err := q.GetWalletForUpdate(ctx, walletID)
+		from, to, err := s.getWalletPairForUpdate(ctx, q, fromWalletID, toWalletID)
 		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				s.logger.Warn("wallet not found", zap.String("walletId", walletID.String()))
-				return ErrWalletNotFound
-			}
-			s.logger.Error("failed to get wallet for update", zap.String("walletId", walletID.String()), zap.Error(err))
 			return err
 		}
-
-		var newBalance float64
-		switch opType {
-		case OperationDeposit:
-			newBalance = w.Balance + amount
-		case OperationWithdraw:
-			if w.Balance < amount {
-				s.logger.Warn("insufficient funds", zap.String("walletId", walletID.String()), zap.Float64("balance", w.Balance), zap.Float64("amount", amount))
-				return ErrInsufficientFunds
-			}
-			newBalance = w.Balance - amount
-		default:
-			return ErrInvalidOperation
+		// Зачислять можно на любой кошелек, списывать - только со своего
+		if err = s.authorize(ctx, from); err != nil {
+			return err
+		}
+		if err = s.checkDebit(from); err != nil {
+			return err
+		}
+		if err = s.checkCredit(to); err != nil {
+			return err
+		}
+		if from.Currency != to.Currency {
+			s.logger.Warn("transfer currency mismatch", zap.String("fromCurrency", from.Currency), zap.String("toCurrency", to.Currency))
+			return ErrCurrencyMismatch
+		}
+		if err := money.ValidateForCurrency(amount, from.Currency); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+		}
+		if AvailableBalance(from).LessThan(amount) {
+			s.logger.Warn("insufficient funds", zap.String("walletId", fromWalletID.String()), zap.Stringer("available", AvailableBalance(from)), zap.Stringer("amount", amount))
+			return ErrInsufficientFunds
 		}
 
-		result, err = q.UpdateWalletBalance(ctx, repository.UpdateWalletBalanceParams{
-			ID:      walletID,
-			Balance: newBalance,
+		result.From, err = s.applyBalanceChange(ctx, q, from, ledgerEntry{
+			opType:       OperationTransferOut,
+			amount:       amount,
+			newBalance:   from.Balance.Sub(amount),
+			counterparty: &toWalletID,
 		})
 		if err != nil {
-			s.logger.Error("failed to update wallet balance", zap.String("walletId", walletID.String()), zap.Error(err))
+			return err
 		}
+		result.To, err = s.applyBalanceChange(ctx, q, to, ledgerEntry{
+			opType:       OperationTransferIn,
+			amount:       amount,
+			newBalance:   to.Balance.Add(amount),
+			counterparty: &fromWalletID,
+		})
 		return err
 	})
 
+	if errors.Is(err, ErrInsufficientFunds) {
+		s.recordInsufficientFunds(ctx, fromWalletID, OperationTransferOut, amount)
+	}
 	if err == nil {
-		s.logger.Info("wallet operation completed", zap.String("walletId", walletID.String()), zap.String("operation", opType), zap.Float64("amount", amount))
+		s.logger.Info("wallet transfer completed", zap.String("fromWalletId", fromWalletID.String()), zap.String("toWalletId", toWalletID.String()), zap.Stringer("amount", amount))
 	}
 
 	return result, err
 }
 
+// AvailableBalance - баланс за вычетом зарезервированных холдами средств
+func AvailableBalance(w repository.Wallet) decimal.Decimal {
+	return w.Balance.Sub(w.HeldBalance)
+}
+
+// ledgerEntry - изменение баланса одного кошелька и данные для записи в журнал
+type ledgerEntry struct {
+	opType       string
+	amount       decimal.Decimal
+	newBalance   decimal.Decimal
+	counterparty *uuid.UUID
+	fxRate       *decimal.Decimal
+	fxRemainder  *decimal.Decimal
+	// heldDelta - изменение зарезервированной суммы кошелька
+	heldDelta  decimal.Decimal
+	holdID     *uuid.UUID
+	reversalOf *uuid.UUID
+}
+
+// applyBalanceChange обновляет баланс и пишет запись в журнал в рамках транзакции q
+func (s *walletService) applyBalanceChange(ctx context.Context, q repository.Querier, w repository.Wallet, e ledgerEntry) (repository.Wallet, error) {
+	updated, _, err := s.applyLedgerEntry(ctx, q, w, e)
+	return updated, err
+}
+
+// applyLedgerEntry - то же, что applyBalanceChange, но возвращает и созданную запись журнала
+func (s *walletService) applyLedgerEntry(ctx context.Context, q repository.Querier, w repository.Wallet, e ledgerEntry) (repository.Wallet, repository.WalletTransaction, error) {
+	updated, err := q.UpdateWalletBalance(ctx, repository.UpdateWalletBalanceParams{
+		ID:        w.ID,
+		Balance:   e.newBalance,
+		HeldDelta: e.heldDelta,
+	})
+	if err != nil {
+		s.logger.Error("failed to update wallet balance", zap.String("walletId", w.ID.String()), zap.Error(err))
+		return repository.Wallet{}, repository.WalletTransaction{}, err
+	}
+
+	entry, err := q.CreateWalletTransaction(ctx, repository.CreateWalletTransactionParams{
+		ID:                   uuid.New(),
+		WalletID:             w.ID,
+		Type:                 e.opType,
+		Amount:               e.amount,
+		BalanceBefore:        w.Balance,
+		BalanceAfter:         updated.Balance,
+		CounterpartyWalletID: e.counterparty,
+		FxRate:               e.fxRate,
+		FxRemainder:          e.fxRemainder,
+		HoldID:               e.holdID,
+		ReversalOf:           e.reversalOf,
+	})
+	if err != nil {
+		s.logger.Error("failed to record wallet transaction", zap.String("walletId", w.ID.String()), zap.Error(err))
+		return repository.Wallet{}, repository.WalletTransaction{}, err
+	}
+
+	if err = s.recordEvent(ctx, q, outbox.BalanceChanged(updated, entry)); err != nil {
+		return repository.Wallet{}, repository.WalletTransaction{}, err
+	}
+	return updated, entry, nil
+}
+
+// recordEvent пишет событие в outbox в рамках транзакции q, отправит его релей
+func (s *walletService) recordEvent(ctx context.Context, q repository.Querier, event outbox.Event) error {
+	params, err := event.Params()
+	if err != nil {
+		return err
+	}
+	if _, err = q.CreateWalletEvent(ctx, params); err != nil {
+		s.logger.Error("failed to record wallet event", zap.String("walletId", event.WalletID.String()), zap.String("type", event.Type), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// recordInsufficientFunds пишет событие об отказе уже после отката транзакции операции.
+// Ошибки только логируются: на ответ клиенту они не влияют.
+func (s *walletService) recordInsufficientFunds(ctx context.Context, walletID uuid.UUID, opType string, amount decimal.Decimal) {
+	w, err := s.repo.GetWallet(ctx, walletID)
+	if err != nil {
+		s.logger.Error("failed to get wallet for insufficient funds event", zap.String("walletId", walletID.String()), zap.Error(err))
+		return
+	}
+	_ = s.recordEvent(ctx, s.repo, outbox.InsufficientFunds(w, opType, amount))
+}
+
+func (s *walletService) getWalletForUpdate(ctx context.Context, q repository.Querier, walletID uuid.UUID) (repository.Wallet, error) {
+	w, err := q.GetWalletForUpdate(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("wallet not found", zap.String("walletId", walletID.String()))
+			return repository.Wallet{}, ErrWalletNotFound
+		}
+		s.logger.Error("failed to get wallet for update", zap.String("walletId", walletID.String()), zap.Error(err))
+		return repository.Wallet{}, err
+	}
+	return w, nil
+}
+
+// getWalletPairForUpdate блокирует строки двух кошельков в том же порядке, что и walletLocker
+func (s *walletService) getWalletPairForUpdate(ctx context.Context, q repository.Querier, fromWalletID, toWalletID uuid.UUID) (repository.Wallet, repository.Wallet, error) {
+	locked := make(map[uuid.UUID]repository.Wallet, 2)
+	for _, id := range sortedWalletIDs([]uuid.UUID{fromWalletID, toWalletID}) {
+		w, err := s.getWalletForUpdate(ctx, q, id)
+		if err != nil {
+			return repository.Wallet{}, repository.Wallet{}, err
+		}
+		locked[id] = w
+	}
+	return locked[fromWalletID], locked[toWalletID], nil
+}
+
 func (s *walletService) GetBalance(ctx context.Context, walletID uuid.UUID) (repository.Wallet, error) {
 	w, err := s.repo.GetWallet(ctx, walletID)
 	if err != nil {
@@ -98,15 +447,158 @@ func (s *walletService) GetBalance(ctx context.Context, walletID uuid.UUID) (rep
 		s.logger.Error("failed to get wallet", zap.String("walletId", walletID.String()), zap.Error(err))
 		return repository.Wallet{}, err
 	}
+	if err = s.authorizeRead(ctx, w); err != nil {
+		return repository.Wallet{}, err
+	}
 	return w, nil
 }
-func (s *walletService) CreateWallet(ctx context.Context) (repository.Wallet, error) {
+func (s *walletService) CreateWallet(ctx context.Context, params NewWallet) (repository.Wallet, error) {
+	currency := params.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	currency, err := money.NormalizeCurrency(currency)
+	if err != nil {
+		return repository.Wallet{}, ErrUnsupportedCurrency
+	}
+	owner, err := resolveOwner(ctx, params.OwnerID)
+	if err != nil {
+		return repository.Wallet{}, err
+	}
+	var externalRef *string
+	if ref := strings.TrimSpace(params.ExternalRef); ref != "" {
+		if len(ref) > maxOwnerFieldLen {
+			return repository.Wallet{}, ErrInvalidExternalRef
+		}
+		externalRef = &ref
+	}
+
 	id := uuid.New()
-	w, err := s.repo.CreateWallet(ctx, id)
+	var w repository.Wallet
+	err = s.repo.WithTx(ctx, func(q repository.Querier) error {
+		w, err = q.CreateWallet(ctx, repository.CreateWalletParams{
+			ID:          id,
+			Currency:    currency,
+			OwnerID:     owner,
+			ExternalRef: externalRef,
+		})
+		if err != nil {
+			// Кошелек с той же внешней ссылкой у владельца уже есть
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrExternalRefExists
+			}
+			s.logger.Error("failed to create wallet", zap.String("walletId", id.String()), zap.Error(err))
+			return err
+		}
+		return s.recordEvent(ctx, q, outbox.WalletCreated(w))
+	})
 	if err != nil {
-		s.logger.Error("failed to create wallet", zap.String("walletId", id.String()), zap.Error(err))
 		return repository.Wallet{}, err
 	}
-	s.logger.Info("wallet created", zap.String("walletId", id.String()))
+	s.logger.Info("wallet created", zap.String("walletId", id.String()), zap.String("currency", currency))
 	return w, nil
 }
+
+func (s *walletService) GetTransaction(ctx context.Context, transactionID uuid.UUID) (Transaction, error) {
+	t, err := s.repo.GetWalletTransaction(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("transaction not found", zap.String("transactionId", transactionID.String()))
+			return Transaction{}, ErrTransactionNotFound
+		}
+		s.logger.Error("failed to get transaction", zap.String("transactionId", transactionID.String()), zap.Error(err))
+		return Transaction{}, err
+	}
+	result := NewTransaction(t)
+	if result.ReversedAmount, err = s.repo.GetReversedAmount(ctx, &t.ID); err != nil {
+		s.logger.Error("failed to get reversed amount", zap.String("transactionId", transactionID.String()), zap.Error(err))
+		return Transaction{}, err
+	}
+	return result, nil
+}
+
+func (s *walletService) ListTransactions(ctx context.Context, walletID uuid.UUID, filter TransactionFilter) (TransactionPage, error) {
+	switch filter.Type {
+	case "", OperationDeposit, OperationWithdraw, OperationTransferIn, OperationTransferOut,
+		OperationConvertIn, OperationConvertOut,
+		OperationHold, OperationCapture, OperationVoid, OperationHoldExpire,
+		OperationReversal:
+	default:
+		return TransactionPage{}, ErrInvalidOperation
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.GreaterThan(*filter.MaxAmount) {
+		return TransactionPage{}, ErrInvalidFilter
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return TransactionPage{}, ErrInvalidFilter
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultTransactionsLimit
+	}
+	if limit > MaxTransactionsLimit {
+		limit = MaxTransactionsLimit
+	}
+
+	// Лишняя запись нужна только чтобы понять, есть ли следующая страница
+	params := repository.ListWalletTransactionsParams{
+		WalletID:    walletID,
+		MinAmount:   filter.MinAmount,
+		MaxAmount:   filter.MaxAmount,
+		CreatedFrom: filter.From,
+		CreatedTo:   filter.To,
+		PageLimit:   int32(limit + 1),
+	}
+	if filter.Type != "" {
+		params.Type = &filter.Type
+	}
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return TransactionPage{}, err
+		}
+		params.CursorCreatedAt = &createdAt
+		params.CursorID = &id
+	}
+
+	if _, err := s.GetBalance(ctx, walletID); err != nil {
+		return TransactionPage{}, err
+	}
+
+	rows, err := s.repo.ListWalletTransactions(ctx, params)
+	if err != nil {
+		s.logger.Error("failed to list transactions", zap.String("walletId", walletID.String()), zap.Error(err))
+		return TransactionPage{}, err
+	}
+
+	page := TransactionPage{Transactions: make([]Transaction, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, t := range rows {
+		page.Transactions = append(page.Transactions, NewTransaction(t))
+		ids = append(ids, t.ID)
+	}
+	if len(ids) == 0 {
+		return page, nil
+	}
+
+	// Сторнированные суммы одной выборкой на страницу: в самих записях они не хранятся
+	reversed, err := s.repo.ListReversedAmounts(ctx, ids)
+	if err != nil {
+		s.logger.Error("failed to list reversed amounts", zap.String("walletId", walletID.String()), zap.Error(err))
+		return TransactionPage{}, err
+	}
+	byID := make(map[uuid.UUID]decimal.Decimal, len(reversed))
+	for _, r := range reversed {
+		byID[r.TransactionID] = r.ReversedAmount
+	}
+	for i := range page.Transactions {
+		page.Transactions[i].ReversedAmount = byID[page.Transactions[i].ID]
+	}
+	return page, nil
+}
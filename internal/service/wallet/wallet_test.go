@@ -9,10 +9,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
 	"tryingMicro/OrderAccepter/internal/repository"
 	"tryingMicro/OrderAccepter/internal/service/wallet"
 )
@@ -41,17 +43,269 @@ func (m *MockRepository) UpdateWalletBalance(ctx context.Context, arg repository
 	return args.Get(0).(repository.Wallet), args.Error(1)
 }
 
-func (m *MockRepository) CreateWallet(ctx context.Context, id uuid.UUID) (repository.Wallet, error) {
+func (m *MockRepository) CreateWallet(ctx context.Context, arg repository.CreateWalletParams) (repository.Wallet, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.Wallet), args.Error(1)
+}
+
+func (m *MockRepository) CreateWalletTransaction(ctx context.Context, arg repository.CreateWalletTransactionParams) (repository.WalletTransaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WalletTransaction), args.Error(1)
+}
+
+func (m *MockRepository) GetWalletTransaction(ctx context.Context, id uuid.UUID) (repository.WalletTransaction, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.WalletTransaction), args.Error(1)
+}
+
+func (m *MockRepository) ListWalletTransactions(ctx context.Context, arg repository.ListWalletTransactionsParams) ([]repository.WalletTransaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.WalletTransaction), args.Error(1)
+}
+
+func (m *MockRepository) ListWalletTransactionsAfter(ctx context.Context, arg repository.ListWalletTransactionsAfterParams) ([]repository.WalletTransaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.WalletTransaction), args.Error(1)
+}
+
+func (m *MockRepository) GetIdempotencyKey(ctx context.Context, arg repository.GetIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockRepository) CreateIdempotencyKey(ctx context.Context, arg repository.CreateIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) CreateFxRate(ctx context.Context, arg repository.CreateFxRateParams) (repository.FxRate, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.FxRate), args.Error(1)
+}
+
+func (m *MockRepository) GetFxRateAt(ctx context.Context, arg repository.GetFxRateAtParams) (repository.FxRate, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.FxRate), args.Error(1)
+}
+
+func (m *MockRepository) CreateWalletHold(ctx context.Context, arg repository.CreateWalletHoldParams) (repository.WalletHold, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WalletHold), args.Error(1)
+}
+
+func (m *MockRepository) GetWalletHoldForUpdate(ctx context.Context, id uuid.UUID) (repository.WalletHold, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.WalletHold), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWalletHoldStatus(ctx context.Context, arg repository.UpdateWalletHoldStatusParams) (repository.WalletHold, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WalletHold), args.Error(1)
+}
+
+func (m *MockRepository) ListExpiredWalletHolds(ctx context.Context, limit int32) ([]repository.WalletHold, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]repository.WalletHold), args.Error(1)
+}
+
+func (m *MockRepository) GetWalletTransactionForUpdate(ctx context.Context, id uuid.UUID) (repository.WalletTransaction, error) {
 	args := m.Called(ctx, id)
+	return args.Get(0).(repository.WalletTransaction), args.Error(1)
+}
+
+func (m *MockRepository) GetReversedAmount(ctx context.Context, reversalOf *uuid.UUID) (decimal.Decimal, error) {
+	args := m.Called(ctx, reversalOf)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockRepository) ListReversedAmounts(ctx context.Context, transactionIds []uuid.UUID) ([]repository.ListReversedAmountsRow, error) {
+	args := m.Called(ctx, transactionIds)
+	return args.Get(0).([]repository.ListReversedAmountsRow), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWalletStatus(ctx context.Context, arg repository.UpdateWalletStatusParams) (repository.Wallet, error) {
+	args := m.Called(ctx, arg)
 	return args.Get(0).(repository.Wallet), args.Error(1)
 }
 
+func (m *MockRepository) CreateWalletStatusChange(ctx context.Context, arg repository.CreateWalletStatusChangeParams) (repository.WalletStatusChange, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WalletStatusChange), args.Error(1)
+}
+
+func (m *MockRepository) CreateWalletEvent(ctx context.Context, arg repository.CreateWalletEventParams) (repository.WalletEvent, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WalletEvent), args.Error(1)
+}
+
+func (m *MockRepository) ClaimPendingWalletEvents(ctx context.Context, arg repository.ClaimPendingWalletEventsParams) ([]repository.WalletEvent, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.WalletEvent), args.Error(1)
+}
+
+func (m *MockRepository) MarkWalletEventPublished(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkWalletEventFailed(ctx context.Context, arg repository.MarkWalletEventFailedParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebhookSubscription(ctx context.Context, arg repository.CreateWebhookSubscriptionParams) (repository.WebhookSubscription, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockRepository) ListWebhookSubscriptions(ctx context.Context, ownerID *string) ([]repository.WebhookSubscription, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]repository.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockRepository) DeleteWebhookSubscription(ctx context.Context, arg repository.DeleteWebhookSubscriptionParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) ListMatchingWebhookSubscriptions(ctx context.Context, arg repository.ListMatchingWebhookSubscriptionsParams) ([]repository.WebhookSubscription, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockRepository) CreateApiKey(ctx context.Context, arg repository.CreateApiKeyParams) (repository.ApiKey, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.ApiKey), args.Error(1)
+}
+
+func (m *MockRepository) GetApiKey(ctx context.Context, id uuid.UUID) (repository.ApiKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.ApiKey), args.Error(1)
+}
+
+func (m *MockRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (repository.ApiKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(repository.ApiKey), args.Error(1)
+}
+
+func (m *MockRepository) ListApiKeys(ctx context.Context) ([]repository.ApiKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.ApiKey), args.Error(1)
+}
+
+func (m *MockRepository) RevokeApiKey(ctx context.Context, id uuid.UUID) (repository.ApiKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.ApiKey), args.Error(1)
+}
+
+func (m *MockRepository) TouchApiKey(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebhookDelivery(ctx context.Context, arg repository.CreateWebhookDeliveryParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimDueWebhookDeliveries(ctx context.Context, arg repository.ClaimDueWebhookDeliveriesParams) ([]repository.ClaimDueWebhookDeliveriesRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.ClaimDueWebhookDeliveriesRow), args.Error(1)
+}
+
+func (m *MockRepository) MarkWebhookDeliveryDelivered(ctx context.Context, arg repository.MarkWebhookDeliveryDeliveredParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkWebhookDeliveryFailed(ctx context.Context, arg repository.MarkWebhookDeliveryFailedParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListWebhookDeliveries(ctx context.Context, arg repository.ListWebhookDeliveriesParams) ([]repository.WebhookDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) RedeliverWebhookDelivery(ctx context.Context, arg repository.RedeliverWebhookDeliveryParams) (repository.WebhookDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) ListWalletsByOwner(ctx context.Context, arg repository.ListWalletsByOwnerParams) ([]repository.Wallet, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.Wallet), args.Error(1)
+}
+
+func (m *MockRepository) GetWalletByExternalRef(ctx context.Context, arg repository.GetWalletByExternalRefParams) (repository.Wallet, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.Wallet), args.Error(1)
+}
+
+func (m *MockRepository) CreateManualAdjustment(ctx context.Context, arg repository.CreateManualAdjustmentParams) (repository.ManualAdjustment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.ManualAdjustment), args.Error(1)
+}
+
+func (m *MockRepository) GetManualAdjustment(ctx context.Context, id uuid.UUID) (repository.ManualAdjustment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.ManualAdjustment), args.Error(1)
+}
+
+func (m *MockRepository) GetManualAdjustmentForUpdate(ctx context.Context, id uuid.UUID) (repository.ManualAdjustment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.ManualAdjustment), args.Error(1)
+}
+
+func (m *MockRepository) DecideManualAdjustment(ctx context.Context, arg repository.DecideManualAdjustmentParams) (repository.ManualAdjustment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.ManualAdjustment), args.Error(1)
+}
+
+func (m *MockRepository) ListManualAdjustments(ctx context.Context, arg repository.ListManualAdjustmentsParams) ([]repository.ManualAdjustment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.ManualAdjustment), args.Error(1)
+}
+
+func (m *MockRepository) LockAuditLog(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAuditLogHead(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateAuditEntry(ctx context.Context, arg repository.CreateAuditEntryParams) (repository.AuditLog, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(repository.AuditLog), args.Error(1)
+}
+
+func (m *MockRepository) ListAuditLog(ctx context.Context, arg repository.ListAuditLogParams) ([]repository.AuditLog, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]repository.AuditLog), args.Error(1)
+}
+
+func (m *MockRepository) GetSchemaVersion(ctx context.Context) (int32, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int32), args.Error(1)
+}
+
 func withTxOK(m *MockRepository) {
 	m.On("WithTx", mock.Anything, mock.Anything).
 		Run(func(args mock.Arguments) {
 			fn := args.Get(1).(func(repository.Querier) error)
 			fn(m)
 		}).Return(nil)
+	// Событие outbox пишется в каждой успешной транзакции, отдельные тесты проверяют его через AssertCalled
+	m.On("CreateWalletEvent", mock.Anything, mock.Anything).Return(repository.WalletEvent{}, nil).Maybe()
 }
 
 func withTxErr(m *MockRepository, err error) {
@@ -60,12 +314,30 @@ func withTxErr(m *MockRepository, err error) {
 			fn := args.Get(1).(func(repository.Querier) error)
 			fn(m)
 		}).Return(err)
+	m.On("CreateWalletEvent", mock.Anything, mock.Anything).Return(repository.WalletEvent{}, nil).Maybe()
+}
+
+// internalCtx - контекст внутреннего вызова: проверки владельца его не ограничивают
+func internalCtx() context.Context {
+	return auth.WithInternal(context.Background())
+}
+
+func dec(v int64) decimal.Decimal {
+	return decimal.NewFromInt(v)
+}
+
+func balanceUpdate(id uuid.UUID, balance int64) interface{} {
+	return mock.MatchedBy(func(arg repository.UpdateWalletBalanceParams) bool {
+		return arg.ID == id && arg.Balance.Equal(dec(balance))
+	})
 }
 
-func makeWallet(balance float64) repository.Wallet {
+func makeWallet(balance int64) repository.Wallet {
 	return repository.Wallet{
 		ID:        uuid.New(),
-		Balance:   balance,
+		Balance:   dec(balance),
+		Currency:  "USD",
+		Status:    wallet.WalletStatusActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -74,42 +346,97 @@ func makeWallet(balance float64) repository.Wallet {
 func TestProcessOperation_Deposit_Success(t *testing.T) {
 	existing := makeWallet(100)
 	updated := existing
-	updated.Balance = 150
+	updated.Balance = dec(150)
 
 	mockRepo := new(MockRepository)
 	withTxOK(mockRepo)
 	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
 		Return(existing, nil)
-	mockRepo.On("UpdateWalletBalance", mock.Anything, repository.UpdateWalletBalanceParams{
-		ID: existing.ID, Balance: 150,
-	}).Return(updated, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(existing.ID, 150)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == existing.ID && arg.Type == wallet.OperationDeposit &&
+			arg.Amount.Equal(dec(50)) && arg.BalanceBefore.Equal(dec(100)) && arg.BalanceAfter.Equal(dec(150))
+	})).Return(repository.WalletTransaction{Type: wallet.OperationDeposit}, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	result, err := svc.ProcessOperation(context.Background(), existing.ID, wallet.OperationDeposit, 50)
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+	mockRepo.AssertCalled(t, "CreateWalletEvent", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletEventParams) bool {
+		return arg.WalletID == existing.ID && arg.EventType == "wallet.deposit"
+	}))
 
 	require.NoError(t, err)
-	assert.Equal(t, 150.0, result.Balance)
+	assert.Equal(t, "150", result.Wallet.Balance.String())
 	mockRepo.AssertExpectations(t)
 }
 
 func TestProcessOperation_Withdraw_Success(t *testing.T) {
 	existing := makeWallet(100)
 	updated := existing
-	updated.Balance = 70
+	updated.Balance = dec(70)
 
 	mockRepo := new(MockRepository)
 	withTxOK(mockRepo)
 	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
 		Return(existing, nil)
-	mockRepo.On("UpdateWalletBalance", mock.Anything, repository.UpdateWalletBalanceParams{
-		ID: existing.ID, Balance: 70,
-	}).Return(updated, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(existing.ID, 70)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == existing.ID && arg.Type == wallet.OperationWithdraw &&
+			arg.Amount.Equal(dec(30)) && arg.BalanceBefore.Equal(dec(100)) && arg.BalanceAfter.Equal(dec(70))
+	})).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationWithdraw, Amount: dec(30)})
+
+	require.NoError(t, err)
+	assert.Equal(t, "70", result.Wallet.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperation_Deposit_ExactDecimal(t *testing.T) {
+	existing := makeWallet(0)
+	existing.Balance = decimal.RequireFromString("0.1")
+	updated := existing
+	updated.Balance = decimal.RequireFromString("0.3")
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, mock.MatchedBy(func(arg repository.UpdateWalletBalanceParams) bool {
+		return arg.Balance.String() == "0.3"
+	})).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.Anything).Return(repository.WalletTransaction{}, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	result, err := svc.ProcessOperation(context.Background(), existing.ID, wallet.OperationWithdraw, 30)
+	result, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: decimal.RequireFromString("0.2")})
 
 	require.NoError(t, err)
-	assert.Equal(t, 70.0, result.Balance)
+	assert.Equal(t, "0.3", result.Wallet.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessOperation_InvalidAmount(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: uuid.New(), Type: wallet.OperationDeposit, Amount: decimal.RequireFromString("0.001")})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
+
+func TestProcessOperation_CurrencyPrecision(t *testing.T) {
+	existing := makeWallet(1000)
+	existing.Currency = "JPY"
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInvalidAmount)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).Return(existing, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: decimal.RequireFromString("10.5")})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidAmount)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -122,7 +449,7 @@ func TestProcessOperation_WalletNotFound(t *testing.T) {
 		Return(repository.Wallet{}, pgx.ErrNoRows)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.ProcessOperation(context.Background(), walletID, wallet.OperationDeposit, 50)
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: walletID, Type: wallet.OperationDeposit, Amount: dec(50)})
 
 	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
 	mockRepo.AssertExpectations(t)
@@ -138,7 +465,7 @@ func TestProcessOperation_RepoError(t *testing.T) {
 		Return(repository.Wallet{}, repoErr)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.ProcessOperation(context.Background(), walletID, wallet.OperationDeposit, 50)
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: walletID, Type: wallet.OperationDeposit, Amount: dec(50)})
 
 	require.ErrorIs(t, err, repoErr)
 	mockRepo.AssertExpectations(t)
@@ -151,11 +478,15 @@ func TestProcessOperation_InsufficientFunds(t *testing.T) {
 	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
 	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
 		Return(existing, nil)
+	mockRepo.On("GetWallet", mock.Anything, existing.ID).Return(existing, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.ProcessOperation(context.Background(), existing.ID, wallet.OperationWithdraw, 100)
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationWithdraw, Amount: dec(100)})
 
 	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	mockRepo.AssertCalled(t, "CreateWalletEvent", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletEventParams) bool {
+		return arg.WalletID == existing.ID && arg.EventType == "wallet.insufficient_funds"
+	}))
 	mockRepo.AssertExpectations(t)
 }
 
@@ -163,15 +494,12 @@ func TestProcessOperation_InvalidOperation(t *testing.T) {
 	existing := makeWallet(100)
 
 	mockRepo := new(MockRepository)
-	withTxErr(mockRepo, wallet.ErrInvalidOperation)
-	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
-		Return(existing, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.ProcessOperation(context.Background(), existing.ID, "REFUND", 50)
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: "REFUND", Amount: dec(50)})
 
 	require.ErrorIs(t, err, wallet.ErrInvalidOperation)
-	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "WithTx")
 }
 
 func TestProcessOperation_UpdateError(t *testing.T) {
@@ -182,17 +510,133 @@ func TestProcessOperation_UpdateError(t *testing.T) {
 	withTxErr(mockRepo, updateErr)
 	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
 		Return(existing, nil)
-	mockRepo.On("UpdateWalletBalance", mock.Anything, repository.UpdateWalletBalanceParams{
-		ID: existing.ID, Balance: 150,
-	}).Return(repository.Wallet{}, updateErr)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(existing.ID, 150)).Return(repository.Wallet{}, updateErr)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.ProcessOperation(context.Background(), existing.ID, wallet.OperationDeposit, 50)
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
 
 	require.ErrorIs(t, err, updateErr)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProcessOperation_TransactionRecordError(t *testing.T) {
+	existing := makeWallet(100)
+	updated := existing
+	updated.Balance = dec(150)
+	recordErr := errors.New("db insert failed")
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, recordErr)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, existing.ID).
+		Return(existing, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(existing.ID, 150)).Return(updated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.AnythingOfType("repository.CreateWalletTransactionParams")).
+		Return(repository.WalletTransaction{}, recordErr)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ProcessOperation(internalCtx(), wallet.Operation{WalletID: existing.ID, Type: wallet.OperationDeposit, Amount: dec(50)})
+
+	require.ErrorIs(t, err, recordErr)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_Success(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(10)
+	fromUpdated, toUpdated := from, to
+	fromUpdated.Balance = dec(60)
+	toUpdated.Balance = dec(50)
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(from.ID, 60)).Return(fromUpdated, nil)
+	mockRepo.On("UpdateWalletBalance", mock.Anything, balanceUpdate(to.ID, 50)).Return(toUpdated, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == from.ID && arg.Type == wallet.OperationTransferOut &&
+			arg.BalanceBefore.Equal(dec(100)) && arg.BalanceAfter.Equal(dec(60)) &&
+			arg.CounterpartyWalletID != nil && *arg.CounterpartyWalletID == to.ID
+	})).Return(repository.WalletTransaction{}, nil)
+	mockRepo.On("CreateWalletTransaction", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletTransactionParams) bool {
+		return arg.WalletID == to.ID && arg.Type == wallet.OperationTransferIn &&
+			arg.BalanceBefore.Equal(dec(10)) && arg.BalanceAfter.Equal(dec(50)) &&
+			arg.CounterpartyWalletID != nil && *arg.CounterpartyWalletID == from.ID
+	})).Return(repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.Transfer(internalCtx(), from.ID, to.ID, dec(40))
+
+	require.NoError(t, err)
+	assert.Equal(t, "60", result.From.Balance.String())
+	assert.Equal(t, "50", result.To.Balance.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_InsufficientFunds(t *testing.T) {
+	from := makeWallet(10)
+	to := makeWallet(0)
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrInsufficientFunds)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+	mockRepo.On("GetWallet", mock.Anything, from.ID).Return(from, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Transfer(internalCtx(), from.ID, to.ID, dec(40))
+
+	require.ErrorIs(t, err, wallet.ErrInsufficientFunds)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_CurrencyMismatch(t *testing.T) {
+	from := makeWallet(100)
+	to := makeWallet(0)
+	to.Currency = "EUR"
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrCurrencyMismatch)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, to.ID).Return(to, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Transfer(internalCtx(), from.ID, to.ID, dec(40))
+
+	require.ErrorIs(t, err, wallet.ErrCurrencyMismatch)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_WalletNotFound(t *testing.T) {
+	from := makeWallet(100)
+	toID := uuid.New()
+
+	mockRepo := new(MockRepository)
+	withTxErr(mockRepo, wallet.ErrWalletNotFound)
+	mockRepo.On("GetWalletForUpdate", mock.Anything, from.ID).Return(from, nil).Maybe()
+	mockRepo.On("GetWalletForUpdate", mock.Anything, toID).Return(repository.Wallet{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Transfer(internalCtx(), from.ID, toID, dec(40))
+
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransfer_SameWallet(t *testing.T) {
+	walletID := uuid.New()
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.Transfer(internalCtx(), walletID, walletID, dec(40))
+
+	require.ErrorIs(t, err, wallet.ErrSameWallet)
+	mockRepo.AssertNotCalled(t, "WithTx")
+}
+
 func TestGetBalance_Success(t *testing.T) {
 	expected := makeWallet(200)
 
@@ -200,7 +644,7 @@ func TestGetBalance_Success(t *testing.T) {
 	mockRepo.On("GetWallet", mock.Anything, expected.ID).Return(expected, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	result, err := svc.GetBalance(context.Background(), expected.ID)
+	result, err := svc.GetBalance(internalCtx(), expected.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, expected.ID, result.ID)
@@ -216,7 +660,7 @@ func TestGetBalance_NotFound(t *testing.T) {
 		Return(repository.Wallet{}, pgx.ErrNoRows)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.GetBalance(context.Background(), walletID)
+	_, err := svc.GetBalance(internalCtx(), walletID)
 
 	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
 	mockRepo.AssertExpectations(t)
@@ -231,7 +675,7 @@ func TestGetBalance_RepoError(t *testing.T) {
 		Return(repository.Wallet{}, repoErr)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.GetBalance(context.Background(), walletID)
+	_, err := svc.GetBalance(internalCtx(), walletID)
 
 	require.ErrorIs(t, err, repoErr)
 	mockRepo.AssertExpectations(t)
@@ -240,28 +684,232 @@ func TestCreateWallet_Success(t *testing.T) {
 	expected := makeWallet(0)
 
 	mockRepo := new(MockRepository)
-	mockRepo.On("CreateWallet", mock.Anything, mock.AnythingOfType("uuid.UUID")).
-		Return(expected, nil)
+	withTxOK(mockRepo)
+	mockRepo.On("CreateWallet", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletParams) bool {
+		return arg.ID != uuid.Nil && arg.Currency == "USD"
+	})).Return(expected, nil)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	result, err := svc.CreateWallet(context.Background())
+	result, err := svc.CreateWallet(internalCtx(), wallet.NewWallet{})
 
 	require.NoError(t, err)
 	assert.Equal(t, expected.ID, result.ID)
-	assert.Equal(t, 0.0, result.Balance)
+	assert.Equal(t, "0", result.Balance.String())
+	mockRepo.AssertCalled(t, "CreateWalletEvent", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletEventParams) bool {
+		return arg.WalletID == expected.ID && arg.EventType == "wallet.created"
+	}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateWallet_WithCurrency(t *testing.T) {
+	expected := makeWallet(0)
+	expected.Currency = "JPY"
+
+	mockRepo := new(MockRepository)
+	withTxOK(mockRepo)
+	mockRepo.On("CreateWallet", mock.Anything, mock.MatchedBy(func(arg repository.CreateWalletParams) bool {
+		return arg.Currency == "JPY"
+	})).Return(expected, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.CreateWallet(internalCtx(), wallet.NewWallet{Currency: "jpy"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", result.Currency)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateWallet_UnsupportedCurrency(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.CreateWallet(internalCtx(), wallet.NewWallet{Currency: "XXX"})
+
+	require.ErrorIs(t, err, wallet.ErrUnsupportedCurrency)
+	mockRepo.AssertNotCalled(t, "CreateWallet")
+}
+
 func TestCreateWallet_RepoError(t *testing.T) {
 	repoErr := errors.New("db error")
 
 	mockRepo := new(MockRepository)
-	mockRepo.On("CreateWallet", mock.Anything, mock.AnythingOfType("uuid.UUID")).
+	withTxErr(mockRepo, repoErr)
+	mockRepo.On("CreateWallet", mock.Anything, mock.AnythingOfType("repository.CreateWalletParams")).
 		Return(repository.Wallet{}, repoErr)
 
 	svc := wallet.New(mockRepo, zap.NewNop())
-	_, err := svc.CreateWallet(context.Background())
+	_, err := svc.CreateWallet(internalCtx(), wallet.NewWallet{})
 
 	require.ErrorIs(t, err, repoErr)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetTransaction_Success(t *testing.T) {
+	expected := repository.WalletTransaction{
+		ID:            uuid.New(),
+		WalletID:      uuid.New(),
+		Type:          wallet.OperationDeposit,
+		Amount:        dec(50),
+		BalanceBefore: dec(100),
+		BalanceAfter:  dec(150),
+		CreatedAt:     time.Now(),
+	}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWalletTransaction", mock.Anything, expected.ID).Return(expected, nil)
+	mockRepo.On("GetReversedAmount", mock.Anything, &expected.ID).Return(dec(20), nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	result, err := svc.GetTransaction(internalCtx(), expected.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID, result.ID)
+	assert.Equal(t, expected.WalletID, result.WalletID)
+	assert.Equal(t, "100", result.BalanceBefore.String())
+	assert.Equal(t, "150", result.BalanceAfter.String())
+	assert.Equal(t, "20", result.ReversedAmount.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTransaction_NotFound(t *testing.T) {
+	transactionID := uuid.New()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWalletTransaction", mock.Anything, transactionID).
+		Return(repository.WalletTransaction{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.GetTransaction(internalCtx(), transactionID)
+
+	require.ErrorIs(t, err, wallet.ErrTransactionNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func makeTransactions(walletID uuid.UUID, n int) []repository.WalletTransaction {
+	now := time.Now()
+	txs := make([]repository.WalletTransaction, 0, n)
+	for i := 0; i < n; i++ {
+		txs = append(txs, repository.WalletTransaction{
+			ID:        uuid.New(),
+			WalletID:  walletID,
+			Type:      wallet.OperationDeposit,
+			Amount:    dec(10),
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return txs
+}
+
+func TestListTransactions_FirstPage(t *testing.T) {
+	existing := makeWallet(100)
+	rows := makeTransactions(existing.ID, 3)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("ListWalletTransactions", mock.Anything, mock.MatchedBy(func(arg repository.ListWalletTransactionsParams) bool {
+		return arg.WalletID == existing.ID && arg.PageLimit == 3 && arg.CursorID == nil && arg.Type == nil
+	})).Return(rows, nil)
+	mockRepo.On("ListReversedAmounts", mock.Anything, []uuid.UUID{rows[0].ID, rows[1].ID}).
+		Return([]repository.ListReversedAmountsRow{{TransactionID: rows[1].ID, ReversedAmount: dec(4)}}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	page, err := svc.ListTransactions(internalCtx(), existing.ID, wallet.TransactionFilter{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	assert.Equal(t, rows[0].ID, page.Transactions[0].ID)
+	assert.True(t, page.Transactions[0].ReversedAmount.IsZero())
+	assert.Equal(t, "4", page.Transactions[1].ReversedAmount.String())
+	assert.NotEmpty(t, page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListTransactions_NextPageUsesCursor(t *testing.T) {
+	existing := makeWallet(100)
+	rows := makeTransactions(existing.ID, 3)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("ListWalletTransactions", mock.Anything, mock.MatchedBy(func(arg repository.ListWalletTransactionsParams) bool {
+		return arg.CursorID == nil
+	})).Return(rows, nil).Once()
+	mockRepo.On("ListWalletTransactions", mock.Anything, mock.MatchedBy(func(arg repository.ListWalletTransactionsParams) bool {
+		return arg.CursorID != nil && *arg.CursorID == rows[1].ID &&
+			arg.CursorCreatedAt != nil && arg.CursorCreatedAt.Equal(rows[1].CreatedAt)
+	})).Return(rows[2:], nil).Once()
+	mockRepo.On("ListReversedAmounts", mock.Anything, mock.Anything).Return([]repository.ListReversedAmountsRow{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	first, err := svc.ListTransactions(internalCtx(), existing.ID, wallet.TransactionFilter{Limit: 2})
+	require.NoError(t, err)
+
+	second, err := svc.ListTransactions(internalCtx(), existing.ID, wallet.TransactionFilter{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Transactions, 1)
+	assert.Equal(t, rows[2].ID, second.Transactions[0].ID)
+	assert.Empty(t, second.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListTransactions_Filters(t *testing.T) {
+	existing := makeWallet(100)
+	minAmount, maxAmount := dec(10), dec(100)
+	from := time.Now().Add(-time.Hour)
+	to := time.Now()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, existing.ID).Return(existing, nil)
+	mockRepo.On("ListWalletTransactions", mock.Anything, mock.MatchedBy(func(arg repository.ListWalletTransactionsParams) bool {
+		return arg.Type != nil && *arg.Type == wallet.OperationWithdraw &&
+			arg.MinAmount == &minAmount && arg.MaxAmount == &maxAmount &&
+			arg.CreatedFrom == &from && arg.CreatedTo == &to &&
+			arg.PageLimit == wallet.DefaultTransactionsLimit+1
+	})).Return([]repository.WalletTransaction{}, nil)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	page, err := svc.ListTransactions(internalCtx(), existing.ID, wallet.TransactionFilter{
+		Type:      wallet.OperationWithdraw,
+		MinAmount: &minAmount,
+		MaxAmount: &maxAmount,
+		From:      &from,
+		To:        &to,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListTransactions_InvalidCursor(t *testing.T) {
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ListTransactions(internalCtx(), uuid.New(), wallet.TransactionFilter{Cursor: "not-a-cursor"})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidCursor)
+	mockRepo.AssertNotCalled(t, "ListWalletTransactions")
+}
+
+func TestListTransactions_InvalidAmountRange(t *testing.T) {
+	minAmount, maxAmount := dec(100), dec(10)
+	mockRepo := new(MockRepository)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ListTransactions(internalCtx(), uuid.New(), wallet.TransactionFilter{MinAmount: &minAmount, MaxAmount: &maxAmount})
+
+	require.ErrorIs(t, err, wallet.ErrInvalidFilter)
+	mockRepo.AssertNotCalled(t, "ListWalletTransactions")
+}
+
+func TestListTransactions_WalletNotFound(t *testing.T) {
+	walletID := uuid.New()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetWallet", mock.Anything, walletID).Return(repository.Wallet{}, pgx.ErrNoRows)
+
+	svc := wallet.New(mockRepo, zap.NewNop())
+	_, err := svc.ListTransactions(internalCtx(), walletID, wallet.TransactionFilter{})
+
+	require.ErrorIs(t, err, wallet.ErrWalletNotFound)
+	mockRepo.AssertNotCalled(t, "ListWalletTransactions")
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// Подписку создает клиент, поэтому без проверки адреса получателя сервер можно
+// направить на свои же внутренние сервисы (SSRF).
+
+// Диапазоны, которые не покрываются методами netip.Addr
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// allowedAddr сообщает, можно ли отправлять запросы на addr: loopback, частные,
+// link-local (в том числе адреса метаданных облака) и служебные адреса запрещены
+func allowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range forbiddenPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkHost разрешает имя при регистрации подписки и отклоняет его, если хотя бы
+// один адрес запрещен. Окончательную проверку делает dialer при каждой отправке.
+func (s *webhookService) checkHost(ctx context.Context, host string) error {
+	if s.allowPrivate {
+		return nil
+	}
+	addrs, err := s.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: host cannot be resolved", ErrInvalidSubscription)
+	}
+	for _, addr := range addrs {
+		if !allowedAddr(addr) {
+			return fmt.Errorf("%w: %w", ErrInvalidSubscription, ErrForbiddenDestination)
+		}
+	}
+	return nil
+}
+
+// newClient собирает HTTP-клиент доставки. Адрес проверяется в Control уже после
+// разрешения имени, поэтому ни DNS rebinding, ни редирект не уводят запрос во внутреннюю сеть.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !allowedAddr(addrPort.Addr()) {
+				return ErrForbiddenDestination
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
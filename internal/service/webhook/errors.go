@@ -0,0 +1,12 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	// ErrForbiddenDestination - адрес получателя в loopback, частной или link-local сети
+	ErrForbiddenDestination = errors.New("webhook destination address is not allowed")
+)
@@ -0,0 +1,40 @@
+package webhook
+
+import "time"
+
+type Option func(*webhookService)
+
+// WithMaxAttempts задает число попыток, после которого доставка уходит в DEAD
+func WithMaxAttempts(n int) Option {
+	return func(s *webhookService) {
+		if n > 0 {
+			s.maxAttempts = int32(n)
+		}
+	}
+}
+
+// WithRetryBaseDelay задает паузу после первой неудачи, дальше она удваивается
+func WithRetryBaseDelay(d time.Duration) Option {
+	return func(s *webhookService) {
+		if d > 0 {
+			s.retryBaseDelay = d
+		}
+	}
+}
+
+// WithTimeout ограничивает время одного запроса к получателю
+func WithTimeout(d time.Duration) Option {
+	return func(s *webhookService) {
+		if d > 0 {
+			s.timeout = d
+		}
+	}
+}
+
+// WithAllowPrivateNetworks снимает запрет на получателей в loopback, частных и
+// link-local сетях. Только для локальной разработки и тестов.
+func WithAllowPrivateNetworks(allow bool) Option {
+	return func(s *webhookService) {
+		s.allowPrivate = allow
+	}
+}
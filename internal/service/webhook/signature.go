@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader содержит "t=<unix>,v1=<hex(hmac_sha256(secret, t + "." + body))>".
+// Метка времени входит в подпись, чтобы получатель мог отбрасывать старые повторы.
+const SignatureHeader = "X-Webhook-Signature"
+
+// Sign подписывает тело запроса секретом подписки
+func Sign(secret string, payload []byte, ts time.Time) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + computeMAC(secret, t, payload)
+}
+
+// Verify проверяет заголовок подписи. tolerance ограничивает возраст метки времени,
+// нулевое значение отключает проверку.
+func Verify(secret string, payload []byte, header string, tolerance time.Duration, now time.Time) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+	if t == "" || sig == "" {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)).Abs() > tolerance {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(computeMAC(secret, t, payload))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func computeMAC(secret, t string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
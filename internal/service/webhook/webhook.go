@@ -0,0 +1,561 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/package/logger"
+)
+
+const (
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusDelivered = "DELIVERED"
+	// В DEAD доставка попадает после maxAttempts неудач и ждет ручного Redeliver
+	DeliveryStatusDead = "DEAD"
+
+	EventDeposit           = "deposit"
+	EventWithdraw          = "withdraw"
+	EventInsufficientFunds = "insufficient_funds"
+	EventStatusChanged     = "status_changed"
+
+	DefaultMaxAttempts    = 8
+	DefaultRetryBaseDelay = 30 * time.Second
+	DefaultTimeout        = 5 * time.Second
+	DefaultBatchSize      = 50
+	defaultDeliveriesPage = 50
+	maxDeliveriesPage     = 200
+	maxRetryDelay         = 6 * time.Hour
+	secretBytes           = 32
+)
+
+// Типы событий, на которые можно подписаться, и соответствующие им типы событий outbox
+var subscribableEvents = map[string]string{
+	EventDeposit:           outbox.OperationEventType("DEPOSIT"),
+	EventWithdraw:          outbox.OperationEventType("WITHDRAW"),
+	EventInsufficientFunds: outbox.EventInsufficientFunds,
+	EventStatusChanged:     outbox.EventStatusChanged,
+}
+
+type WebhookService interface {
+	outbox.Publisher
+	// Register создает подписку. Секрет для проверки подписи возвращается только здесь.
+	Register(ctx context.Context, r Registration) (Subscription, error)
+	List(ctx context.Context) ([]Subscription, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]Delivery, error)
+	// Redeliver возвращает доставку (в том числе DEAD) в очередь с обнуленным счетчиком попыток
+	Redeliver(ctx context.Context, deliveryID uuid.UUID) (Delivery, error)
+	// DeliverPending отправляет доставки, время которых пришло, и возвращает число успешных
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+// Registration - параметры новой подписки. WalletID == nil - подписка на все кошельки
+// владельца, от имени которого она создана (у администратора - на все кошельки).
+type Registration struct {
+	URL        string
+	WalletID   *uuid.UUID
+	EventTypes []string
+}
+
+type Subscription struct {
+	ID         uuid.UUID  `json:"id"`
+	URL        string     `json:"url"`
+	Secret     string     `json:"secret,omitempty"`
+	WalletID   *uuid.UUID `json:"wallet_id"`
+	OwnerID    *string    `json:"owner_id"`
+	EventTypes []string   `json:"event_types"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type Delivery struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      *string    `json:"last_error,omitempty"`
+	LastStatusCode *int32     `json:"last_status_code,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+type webhookService struct {
+	repo           repository.Repository
+	logger         logger.Logger
+	client         *http.Client
+	resolver       *net.Resolver
+	timeout        time.Duration
+	allowPrivate   bool
+	maxAttempts    int32
+	retryBaseDelay time.Duration
+	batchSize      int32
+}
+
+func New(repo repository.Repository, log logger.Logger, opts ...Option) WebhookService {
+	s := &webhookService{
+		repo:           repo,
+		logger:         log,
+		resolver:       net.DefaultResolver,
+		timeout:        DefaultTimeout,
+		maxAttempts:    DefaultMaxAttempts,
+		retryBaseDelay: DefaultRetryBaseDelay,
+		batchSize:      DefaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.client = newClient(s.timeout, s.allowPrivate)
+	return s
+}
+
+func (s *webhookService) Register(ctx context.Context, r Registration) (Subscription, error) {
+	host, err := validateURL(r.URL)
+	if err != nil {
+		return Subscription{}, err
+	}
+	eventTypes, err := normalizeEventTypes(r.EventTypes)
+	if err != nil {
+		return Subscription{}, err
+	}
+	ownerID, err := subscriptionOwner(ctx)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if r.WalletID != nil {
+		if err = s.checkWallet(ctx, *r.WalletID); err != nil {
+			return Subscription{}, err
+		}
+	}
+	if err = s.checkHost(ctx, host); err != nil {
+		return Subscription{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		s.logger.Error("failed to generate webhook secret", zap.Error(err))
+		return Subscription{}, err
+	}
+
+	row, err := s.repo.CreateWebhookSubscription(ctx, repository.CreateWebhookSubscriptionParams{
+		ID:         uuid.New(),
+		Url:        r.URL,
+		Secret:     secret,
+		WalletID:   r.WalletID,
+		EventTypes: eventTypes,
+		OwnerID:    ownerID,
+	})
+	if err != nil {
+		s.logger.Error("failed to create webhook subscription", zap.Error(err))
+		return Subscription{}, err
+	}
+
+	sub := toSubscription(row)
+	sub.Secret = row.Secret
+	return sub, nil
+}
+
+func (s *webhookService) List(ctx context.Context) ([]Subscription, error) {
+	rows, err := s.repo.ListWebhookSubscriptions(ctx, ownerFilter(ctx))
+	if err != nil {
+		s.logger.Error("failed to list webhook subscriptions", zap.Error(err))
+		return nil, err
+	}
+	subs := make([]Subscription, 0, len(rows))
+	for _, row := range rows {
+		subs = append(subs, toSubscription(row))
+	}
+	return subs, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	n, err := s.repo.DeleteWebhookSubscription(ctx, repository.DeleteWebhookSubscriptionParams{ID: id, OwnerID: ownerFilter(ctx)})
+	if err != nil {
+		s.logger.Error("failed to delete webhook subscription", zap.String("subscriptionId", id.String()), zap.Error(err))
+		return err
+	}
+	if n == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]Delivery, error) {
+	if limit <= 0 {
+		limit = defaultDeliveriesPage
+	}
+	if limit > maxDeliveriesPage {
+		limit = maxDeliveriesPage
+	}
+
+	var statusFilter *string
+	if status != "" {
+		status = strings.ToUpper(status)
+		switch status {
+		case DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusDead:
+		default:
+			return nil, fmt.Errorf("%w: unknown delivery status %q", ErrInvalidSubscription, status)
+		}
+		statusFilter = &status
+	}
+
+	rows, err := s.repo.ListWebhookDeliveries(ctx, repository.ListWebhookDeliveriesParams{
+		SubscriptionID: subscriptionID,
+		Status:         statusFilter,
+		PageLimit:      int32(limit),
+		OwnerID:        ownerFilter(ctx),
+	})
+	if err != nil {
+		s.logger.Error("failed to list webhook deliveries", zap.String("subscriptionId", subscriptionID.String()), zap.Error(err))
+		return nil, err
+	}
+	deliveries := make([]Delivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, toDelivery(row))
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (Delivery, error) {
+	row, err := s.repo.RedeliverWebhookDelivery(ctx, repository.RedeliverWebhookDeliveryParams{ID: deliveryID, OwnerID: ownerFilter(ctx)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+		s.logger.Error("failed to redeliver webhook", zap.String("deliveryId", deliveryID.String()), zap.Error(err))
+		return Delivery{}, err
+	}
+	return toDelivery(row), nil
+}
+
+// Publish ставит событие в очередь доставки каждой подходящей подписке. Вызывается
+// релеем outbox, поэтому повтор того же события не создает дублей (UNIQUE subscription_id, event_id).
+func (s *webhookService) Publish(ctx context.Context, event outbox.Event) error {
+	if !isSubscribable(event.Type) {
+		return nil
+	}
+
+	subs, err := s.repo.ListMatchingWebhookSubscriptions(ctx, repository.ListMatchingWebhookSubscriptionsParams{
+		WalletID:  &event.WalletID,
+		EventType: event.Type,
+	})
+	if err != nil {
+		s.logger.Error("failed to list matching webhook subscriptions", zap.String("eventId", event.ID.String()), zap.Error(err))
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	payload, err := event.Params()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		err := s.repo.CreateWebhookDelivery(ctx, repository.CreateWebhookDeliveryParams{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload.Payload,
+		})
+		if err != nil {
+			s.logger.Error("failed to enqueue webhook delivery",
+				zap.String("subscriptionId", sub.ID.String()),
+				zap.String("eventId", event.ID.String()),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverPending берет пачку доставок в аренду (locked_until) короткой транзакцией
+// с FOR UPDATE SKIP LOCKED, как и релей outbox. HTTP-запросы идут уже без транзакции
+// и блокировок строк, а результаты фиксируются второй короткой транзакцией. Если воркер
+// упал посреди пачки, аренда истечет и доставки уйдут повторно.
+func (s *webhookService) DeliverPending(ctx context.Context) (int, error) {
+	var rows []repository.ClaimDueWebhookDeliveriesRow
+	err := s.repo.WithTx(ctx, func(q repository.Querier) error {
+		var err error
+		rows, err = q.ClaimDueWebhookDeliveries(ctx, repository.ClaimDueWebhookDeliveriesParams{
+			LockedUntil: time.Now().Add(s.leaseDuration()),
+			BatchSize:   s.batchSize,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to claim due webhook deliveries", zap.Error(err))
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	results := make([]sendResult, len(rows))
+	for i, row := range rows {
+		results[i].code, results[i].err = s.send(ctx, row)
+	}
+
+	delivered := 0
+	err = s.repo.WithTx(ctx, func(q repository.Querier) error {
+		delivered = 0
+		for i, row := range rows {
+			if err := s.mark(ctx, q, row, results[i]); err != nil {
+				return err
+			}
+			if results[i].err == nil {
+				delivered++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delivered, nil
+}
+
+// sendResult - итог одного HTTP-запроса к получателю
+type sendResult struct {
+	code int32
+	err  error
+}
+
+// leaseDuration - аренда пачки должна пережить последовательную отправку всех ее доставок
+func (s *webhookService) leaseDuration() time.Duration {
+	return time.Duration(s.batchSize)*s.client.Timeout + time.Minute
+}
+
+func (s *webhookService) mark(ctx context.Context, q repository.Querier, row repository.ClaimDueWebhookDeliveriesRow, result sendResult) error {
+	var statusCode *int32
+	if result.code != 0 {
+		statusCode = &result.code
+	}
+
+	if result.err == nil {
+		err := q.MarkWebhookDeliveryDelivered(ctx, repository.MarkWebhookDeliveryDeliveredParams{
+			ID:         row.ID,
+			StatusCode: statusCode,
+		})
+		if err != nil {
+			s.logger.Error("failed to mark webhook delivered", zap.String("deliveryId", row.ID.String()), zap.Error(err))
+		}
+		return err
+	}
+
+	attempts := row.Attempts + 1
+	status := DeliveryStatusPending
+	if attempts >= s.maxAttempts {
+		status = DeliveryStatusDead
+	}
+	lastError := result.err.Error()
+	s.logger.Warn("failed to deliver webhook",
+		zap.String("deliveryId", row.ID.String()),
+		zap.String("subscriptionId", row.SubscriptionID.String()),
+		zap.Int32("attempts", attempts),
+		zap.String("status", status),
+		zap.Error(result.err),
+	)
+	err := q.MarkWebhookDeliveryFailed(ctx, repository.MarkWebhookDeliveryFailedParams{
+		ID:            row.ID,
+		Status:        status,
+		NextAttemptAt: time.Now().Add(s.retryDelay(attempts)),
+		LastError:     &lastError,
+		StatusCode:    statusCode,
+	})
+	if err != nil {
+		s.logger.Error("failed to mark webhook delivery failed", zap.String("deliveryId", row.ID.String()), zap.Error(err))
+	}
+	return err
+}
+
+func (s *webhookService) send(ctx context.Context, row repository.ClaimDueWebhookDeliveriesRow) (int32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.Url, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", row.ID.String())
+	req.Header.Set("X-Event-Id", row.EventID.String())
+	req.Header.Set("X-Event-Type", row.EventType)
+	req.Header.Set(SignatureHeader, Sign(row.Secret, row.Payload, time.Now()))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	code := int32(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return code, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return code, nil
+}
+
+// retryDelay - пауза после attempts неудачных попыток: base * 2^(attempts-1), не больше maxRetryDelay
+func (s *webhookService) retryDelay(attempts int32) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := s.retryBaseDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// validateURL проверяет форму адреса и возвращает его хост
+func validateURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("%w: url must be an absolute http(s) url", ErrInvalidSubscription)
+	}
+	return u.Hostname(), nil
+}
+
+func normalizeEventTypes(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidSubscription)
+	}
+	seen := make(map[string]bool, len(types))
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		full, ok := subscribableEvents[strings.ToLower(t)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, t)
+		}
+		if !seen[full] {
+			seen[full] = true
+			result = append(result, full)
+		}
+	}
+	return result, nil
+}
+
+func isSubscribable(eventType string) bool {
+	for _, full := range subscribableEvents {
+		if full == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Наружу типы событий отдаются в том же виде, в каком принимаются при подписке
+func shortEventType(full string) string {
+	for short, f := range subscribableEvents {
+		if f == full {
+			return short
+		}
+	}
+	return full
+}
+
+// subscriptionOwner выбирает владельца новой подписки: у администратора и внутренних
+// вызовов его нет, остальные подписываются только от имени владельца своего ключа
+func subscriptionOwner(ctx context.Context) (*string, error) {
+	if auth.IsInternal(ctx) {
+		return nil, nil
+	}
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if p.HasScope(auth.ScopeAdmin) {
+		return nil, nil
+	}
+	if p.OwnerID == "" {
+		return nil, fmt.Errorf("%w: api key has no owner", ErrInvalidSubscription)
+	}
+	return &p.OwnerID, nil
+}
+
+// ownerFilter ограничивает подписки и доставки владельцем вызывающего; nil - без ограничения.
+// Пустой владелец не совпадает ни с одной подпиской.
+func ownerFilter(ctx context.Context) *string {
+	if auth.IsInternal(ctx) {
+		return nil
+	}
+	p, ok := auth.FromContext(ctx)
+	if ok && p.HasScope(auth.ScopeAdmin) {
+		return nil
+	}
+	return &p.OwnerID
+}
+
+// checkWallet проверяет, что подписаться на кошелек может вызывающий.
+// Чужой кошелек неотличим от несуществующего.
+func (s *webhookService) checkWallet(ctx context.Context, walletID uuid.UUID) error {
+	w, err := s.repo.GetWallet(ctx, walletID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("failed to get wallet for webhook subscription", zap.String("walletId", walletID.String()), zap.Error(err))
+		return err
+	}
+	if err != nil || !auth.CanWrite(ctx, w.OwnerID) {
+		return fmt.Errorf("%w: wallet not found", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func toSubscription(row repository.WebhookSubscription) Subscription {
+	types := make([]string, 0, len(row.EventTypes))
+	for _, t := range row.EventTypes {
+		types = append(types, shortEventType(t))
+	}
+	return Subscription{
+		ID:         row.ID,
+		URL:        row.Url,
+		WalletID:   row.WalletID,
+		OwnerID:    row.OwnerID,
+		EventTypes: types,
+		CreatedAt:  row.CreatedAt,
+	}
+}
+
+func toDelivery(row repository.WebhookDelivery) Delivery {
+	return Delivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		EventID:        row.EventID,
+		EventType:      shortEventType(row.EventType),
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		NextAttemptAt:  row.NextAttemptAt,
+		LastError:      row.LastError,
+		LastStatusCode: row.LastStatusCode,
+		CreatedAt:      row.CreatedAt,
+		DeliveredAt:    row.DeliveredAt,
+	}
+}
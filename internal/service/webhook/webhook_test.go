@@ -0,0 +1,318 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"tryingMicro/OrderAccepter/internal/auth"
+	"tryingMicro/OrderAccepter/internal/mocks"
+	"tryingMicro/OrderAccepter/internal/repository"
+	"tryingMicro/OrderAccepter/internal/service/outbox"
+	"tryingMicro/OrderAccepter/internal/service/webhook"
+)
+
+func newRepo(t *testing.T) *mocks.MockRepository {
+	repo, _ := newTxRepo(t)
+	return repo
+}
+
+// newTxRepo возвращает мок репозитория; inTx показывает, идет ли сейчас транзакция
+func newTxRepo(t *testing.T) (*mocks.MockRepository, *bool) {
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	inTx := new(bool)
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(repository.Querier) error) error {
+			*inTx = true
+			defer func() { *inTx = false }()
+			return fn(repo)
+		}).AnyTimes()
+	return repo, inTx
+}
+
+func ownerCtx(owner string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{ID: "key-" + owner, OwnerID: owner, Scopes: []string{auth.ScopeWalletWrite}})
+}
+
+func dueRow(url string, attempts int32) repository.ClaimDueWebhookDeliveriesRow {
+	return repository.ClaimDueWebhookDeliveriesRow{
+		ID:             uuid.New(),
+		SubscriptionID: uuid.New(),
+		EventID:        uuid.New(),
+		EventType:      "wallet.deposit",
+		Payload:        []byte(`{"type":"wallet.deposit","amount":"10"}`),
+		Attempts:       attempts,
+		Url:            url,
+		Secret:         "whsec_test",
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Now()
+	payload := []byte(`{"id":"1"}`)
+	header := webhook.Sign("secret", payload, now)
+
+	require.NoError(t, webhook.Verify("secret", payload, header, time.Minute, now))
+	assert.ErrorIs(t, webhook.Verify("other", payload, header, time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", []byte(`{"id":"2"}`), header, time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", payload, header, time.Minute, now.Add(time.Hour)), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", payload, "garbage", 0, now), webhook.ErrInvalidSignature)
+}
+
+func TestRegister_ValidatesAndReturnsSecret(t *testing.T) {
+	repo := newRepo(t)
+	repo.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateWebhookSubscriptionParams) (repository.WebhookSubscription, error) {
+			assert.Equal(t, []string{"wallet.deposit", "wallet.insufficient_funds", "wallet.status_changed"}, arg.EventTypes)
+			assert.NotEmpty(t, arg.Secret)
+			return repository.WebhookSubscription{ID: arg.ID, Url: arg.Url, Secret: arg.Secret, EventTypes: arg.EventTypes}, nil
+		})
+
+	ctx := auth.WithInternal(context.Background())
+	svc := webhook.New(repo, zap.NewNop())
+	sub, err := svc.Register(ctx, webhook.Registration{
+		URL:        "https://93.184.216.34/hook",
+		EventTypes: []string{"deposit", "insufficient_funds", "deposit", "status_changed"},
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, sub.Secret)
+	assert.Equal(t, []string{"deposit", "insufficient_funds", "status_changed"}, sub.EventTypes)
+	assert.Nil(t, sub.OwnerID)
+
+	_, err = svc.Register(ctx, webhook.Registration{URL: "https://93.184.216.34", EventTypes: []string{"created"}})
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+	_, err = svc.Register(ctx, webhook.Registration{URL: "ftp://example.com", EventTypes: []string{"deposit"}})
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+}
+
+func TestRegister_StoresKeyOwner(t *testing.T) {
+	repo := newRepo(t)
+	repo.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateWebhookSubscriptionParams) (repository.WebhookSubscription, error) {
+			require.NotNil(t, arg.OwnerID)
+			assert.Equal(t, "alice", *arg.OwnerID)
+			return repository.WebhookSubscription{ID: arg.ID, Url: arg.Url, EventTypes: arg.EventTypes, OwnerID: arg.OwnerID}, nil
+		})
+
+	sub, err := webhook.New(repo, zap.NewNop()).Register(ownerCtx("alice"), webhook.Registration{
+		URL:        "https://93.184.216.34/hook",
+		EventTypes: []string{"deposit"},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, sub.OwnerID)
+	assert.Equal(t, "alice", *sub.OwnerID)
+}
+
+func TestRegister_RejectsForeignWallet(t *testing.T) {
+	walletID := uuid.New()
+	bob := "bob"
+	repo := newRepo(t)
+	repo.EXPECT().GetWallet(gomock.Any(), walletID).Return(repository.Wallet{ID: walletID, OwnerID: &bob}, nil)
+
+	_, err := webhook.New(repo, zap.NewNop()).Register(ownerCtx("alice"), webhook.Registration{
+		URL:        "https://93.184.216.34/hook",
+		WalletID:   &walletID,
+		EventTypes: []string{"deposit"},
+	})
+
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+}
+
+func TestRegister_RequiresPrincipal(t *testing.T) {
+	_, err := webhook.New(newRepo(t), zap.NewNop()).Register(context.Background(), webhook.Registration{
+		URL:        "https://93.184.216.34/hook",
+		EventTypes: []string{"deposit"},
+	})
+
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestRegister_RejectsInternalDestinations(t *testing.T) {
+	svc := webhook.New(newRepo(t), zap.NewNop())
+	for _, url := range []string{
+		"http://127.0.0.1/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := svc.Register(ownerCtx("alice"), webhook.Registration{URL: url, EventTypes: []string{"deposit"}})
+		assert.ErrorIs(t, err, webhook.ErrForbiddenDestination, url)
+		assert.ErrorIs(t, err, webhook.ErrInvalidSubscription, url)
+	}
+}
+
+func TestDeliverPending_BlocksInternalDestinations(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	// Подписка могла быть создана до проверки или имя позже разрешилось во внутренний адрес
+	row := dueRow(receiver.URL, 0)
+	repo := newRepo(t)
+	repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Return([]repository.ClaimDueWebhookDeliveriesRow{row}, nil)
+	repo.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.MarkWebhookDeliveryFailedParams) error {
+			require.NotNil(t, arg.LastError)
+			assert.Contains(t, *arg.LastError, webhook.ErrForbiddenDestination.Error())
+			return nil
+		})
+
+	n, err := webhook.New(repo, zap.NewNop()).DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.False(t, called)
+}
+
+func TestList_FiltersByKeyOwner(t *testing.T) {
+	owner := "alice"
+	repo := newRepo(t)
+	repo.EXPECT().ListWebhookSubscriptions(gomock.Any(), &owner).
+		Return([]repository.WebhookSubscription{{ID: uuid.New(), OwnerID: &owner}}, nil)
+
+	subs, err := webhook.New(repo, zap.NewNop()).List(ownerCtx(owner))
+
+	require.NoError(t, err)
+	assert.Len(t, subs, 1)
+}
+
+func TestDelete_ForeignSubscriptionNotFound(t *testing.T) {
+	id := uuid.New()
+	owner := "alice"
+	repo := newRepo(t)
+	repo.EXPECT().DeleteWebhookSubscription(gomock.Any(), repository.DeleteWebhookSubscriptionParams{ID: id, OwnerID: &owner}).
+		Return(int64(0), nil)
+
+	err := webhook.New(repo, zap.NewNop()).Delete(ownerCtx(owner), id)
+
+	assert.ErrorIs(t, err, webhook.ErrSubscriptionNotFound)
+}
+
+func TestPublish_EnqueuesForMatchingSubscriptions(t *testing.T) {
+	w := repository.Wallet{ID: uuid.New(), Balance: decimal.NewFromInt(5), Currency: "USD"}
+	event := outbox.InsufficientFunds(w, "WITHDRAW", decimal.NewFromInt(10))
+	subs := []repository.WebhookSubscription{{ID: uuid.New()}, {ID: uuid.New()}}
+
+	repo := newRepo(t)
+	repo.EXPECT().ListMatchingWebhookSubscriptions(gomock.Any(), repository.ListMatchingWebhookSubscriptionsParams{
+		WalletID:  &event.WalletID,
+		EventType: outbox.EventInsufficientFunds,
+	}).Return(subs, nil)
+	repo.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.CreateWebhookDeliveryParams) error {
+			assert.Equal(t, event.ID, arg.EventID)
+			assert.Contains(t, string(arg.Payload), `"operation":"WITHDRAW"`)
+			return nil
+		}).Times(2)
+
+	err := webhook.New(repo, zap.NewNop()).Publish(context.Background(), event)
+	require.NoError(t, err)
+}
+
+func TestPublish_IgnoresUnsubscribableEvents(t *testing.T) {
+	repo := newRepo(t)
+	event := outbox.WalletCreated(repository.Wallet{ID: uuid.New()})
+
+	err := webhook.New(repo, zap.NewNop()).Publish(context.Background(), event)
+	require.NoError(t, err)
+}
+
+func TestDeliverPending_SignedRequest(t *testing.T) {
+	var (
+		gotBody   []byte
+		gotHeader http.Header
+	)
+	repo, inTx := newTxRepo(t)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Получатель вызывается вне транзакции: медленный подписчик не держит соединение с БД
+		assert.False(t, *inTx)
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeader = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	row := dueRow(receiver.URL, 0)
+	repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.ClaimDueWebhookDeliveriesParams) ([]repository.ClaimDueWebhookDeliveriesRow, error) {
+			assert.Equal(t, int32(webhook.DefaultBatchSize), arg.BatchSize)
+			assert.True(t, arg.LockedUntil.After(time.Now()))
+			return []repository.ClaimDueWebhookDeliveriesRow{row}, nil
+		})
+	repo.EXPECT().MarkWebhookDeliveryDelivered(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.MarkWebhookDeliveryDeliveredParams) error {
+			assert.Equal(t, row.ID, arg.ID)
+			require.NotNil(t, arg.StatusCode)
+			assert.Equal(t, int32(http.StatusNoContent), *arg.StatusCode)
+			return nil
+		})
+
+	n, err := webhook.New(repo, zap.NewNop(), webhook.WithAllowPrivateNetworks(true)).DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, row.Payload, gotBody)
+	assert.Equal(t, row.EventID.String(), gotHeader.Get("X-Event-Id"))
+	assert.Equal(t, "wallet.deposit", gotHeader.Get("X-Event-Type"))
+	require.NoError(t, webhook.Verify(row.Secret, gotBody, gotHeader.Get(webhook.SignatureHeader), time.Minute, time.Now()))
+}
+
+func TestDeliverPending_RetriesWithBackoff(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	row := dueRow(receiver.URL, 2)
+	repo := newRepo(t)
+	repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Return([]repository.ClaimDueWebhookDeliveriesRow{row}, nil)
+	repo.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.MarkWebhookDeliveryFailedParams) error {
+			assert.Equal(t, webhook.DeliveryStatusPending, arg.Status)
+			require.NotNil(t, arg.StatusCode)
+			assert.Equal(t, int32(http.StatusServiceUnavailable), *arg.StatusCode)
+			// третья неудача: 10s * 2^2
+			assert.WithinDuration(t, time.Now().Add(40*time.Second), arg.NextAttemptAt, 5*time.Second)
+			return nil
+		})
+
+	svc := webhook.New(repo, zap.NewNop(), webhook.WithRetryBaseDelay(10*time.Second), webhook.WithAllowPrivateNetworks(true))
+	n, err := svc.DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDeliverPending_DeadAfterMaxAttempts(t *testing.T) {
+	row := dueRow("http://127.0.0.1:1/unreachable", 2)
+	repo := newRepo(t)
+	repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Return([]repository.ClaimDueWebhookDeliveriesRow{row}, nil)
+	repo.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg repository.MarkWebhookDeliveryFailedParams) error {
+			assert.Equal(t, webhook.DeliveryStatusDead, arg.Status)
+			assert.Nil(t, arg.StatusCode)
+			require.NotNil(t, arg.LastError)
+			return nil
+		})
+
+	svc := webhook.New(repo, zap.NewNop(), webhook.WithMaxAttempts(3), webhook.WithAllowPrivateNetworks(true))
+	_, err := svc.DeliverPending(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer - pgx.QueryTracer, открывающий клиентский спан на каждый запрос.
+// Спан называется по имени запроса sqlc, BEGIN/COMMIT транзакций попадают сюда же.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := QueryName(data.SQL)
+	ctx, _ = Tracer().Start(ctx, "db "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(op),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	End(trace.SpanFromContext(ctx), data.Err)
+}
+
+// QueryName достает имя из комментария sqlc "-- name: GetWallet :one",
+// для остальных запросов - первое слово (begin, commit, select...)
+func QueryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	if word, _, _ := strings.Cut(sql, " "); word != "" {
+		return strings.ToLower(word)
+	}
+	return "query"
+}
@@ -0,0 +1,107 @@
+// Package tracing - трассировка OpenTelemetry. Спаны пишутся через глобальный
+// TracerProvider: пока Setup не вызван, он no-op и инструментирование ничего не стоит.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"tryingMicro/OrderAccepter/util/config"
+)
+
+// Имя инструментирования, под которым сервис создает свои спаны
+const instrumentation = "tryingMicro/OrderAccepter"
+
+// Экспортеры TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const defaultServiceName = "wallet"
+
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
+
+// Tracer возвращает трейсер сервиса из текущего глобального провайдера
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Setup настраивает глобальные провайдер и W3C-пропагатор по конфигу.
+// Возвращенный shutdown досылает буферизованные спаны, его нужно вызвать при остановке.
+func Setup(ctx context.Context, cfg config.Config) (func(context.Context) error, error) {
+	// Пропагатор нужен и без экспортера: входящий traceparent передается дальше как есть
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	name := cfg.TracingServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name)))
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := cfg.TracingSampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Решение о сэмплировании берется у вызывающего, если он его прислал
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newExporter выбирает экспортер по TRACING_EXPORTER; nil - трассировка отключена
+func newExporter(ctx context.Context, cfg config.Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracingExporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		if cfg.TracingOTLPEndpoint == "" {
+			return nil, fmt.Errorf("TRACING_OTLP_ENDPOINT is required for otlp exporter")
+		}
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.TracingOTLPEndpoint)}
+		if cfg.TracingOTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, cfg.TracingExporter)
+	}
+}
+
+// End завершает спан, отмечая ошибку, если она есть
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,146 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"tryingMicro/OrderAccepter/internal/tracing"
+	"tryingMicro/OrderAccepter/util/config"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// recordSpans подменяет глобальный провайдер на записывающий до конца теста
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	_, err := tracing.Setup(context.Background(), config.Config{})
+	require.NoError(t, err)
+
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	rec := recordSpans(t)
+
+	r := gin.New()
+	r.Use(tracing.Middleware())
+	var inner trace.SpanContext
+	r.GET("/wallets/:walletId", func(c *gin.Context) {
+		_, span := tracing.Tracer().Start(c.Request.Context(), "inner")
+		inner = span.SpanContext()
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	server := spans[1]
+	assert.Equal(t, "GET /wallets/:walletId", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, "/wallets/:walletId", attr(server, "http.route").AsString())
+	assert.Equal(t, int64(http.StatusOK), attr(server, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, server.Status().Code)
+
+	assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent().SpanID(), "спаны обработчика - потомки серверного")
+	assert.Equal(t, server.SpanContext().TraceID(), inner.TraceID())
+}
+
+func TestMiddleware_ServerErrorStatus(t *testing.T) {
+	rec := recordSpans(t)
+
+	r := gin.New()
+	r.Use(tracing.Middleware())
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	r.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "4xx не ошибка сервера")
+	assert.False(t, spans[0].Parent().IsValid(), "без traceparent начинается новая трасса")
+}
+
+func TestQueryTracer_SpanPerQuery(t *testing.T) {
+	rec := recordSpans(t)
+	tracer := tracing.QueryTracer{}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: GetWallet :one\nSELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "commit"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "db GetWallet", spans[0].Name())
+	assert.Equal(t, "postgresql", attr(spans[0], "db.system.name").AsString())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "db commit", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"-- name: GetWallet :one\nSELECT id FROM wallets WHERE id = $1", "GetWallet"},
+		{"\n-- name: ListAuditLog :many\nSELECT 1", "ListAuditLog"},
+		{"begin", "begin"},
+		{"COMMIT", "commit"},
+		{"SELECT 1", "select"},
+		{"", "query"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tracing.QueryName(tt.sql), tt.sql)
+	}
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), config.Config{TracingExporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = tracing.Setup(context.Background(), config.Config{TracingExporter: "jaeger"})
+	assert.ErrorIs(t, err, tracing.ErrUnknownExporter)
+
+	_, err = tracing.Setup(context.Background(), config.Config{TracingExporter: tracing.ExporterOTLP})
+	assert.Error(t, err, "otlp требует endpoint")
+}
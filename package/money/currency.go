@@ -0,0 +1,60 @@
+package money
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const DefaultCurrency = "USD"
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Число знаков минорной единицы по ISO 4217. Валюты с тремя знаками (BHD, KWD, ...)
+// не поддерживаются: баланс хранится как NUMERIC(20, 2).
+var currencyExponents = map[string]int32{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"RUB": 2,
+	"CNY": 2,
+	"KZT": 2,
+	"TRY": 2,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// NormalizeCurrency приводит код к верхнему регистру и проверяет, что валюта поддерживается
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := currencyExponents[code]; !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	return code, nil
+}
+
+// Exponent возвращает число знаков после запятой для валюты
+func Exponent(code string) (int32, error) {
+	exp, ok := currencyExponents[code]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+	return exp, nil
+}
+
+// ValidateForCurrency проверяет сумму через Validate и точность минорной единицы валюты
+func ValidateForCurrency(amount decimal.Decimal, code string) error {
+	if err := Validate(amount); err != nil {
+		return err
+	}
+	exp, err := Exponent(code)
+	if err != nil {
+		return err
+	}
+	if !amount.Equal(amount.Truncate(exp)) {
+		return ErrScaleExceeded
+	}
+	return nil
+}
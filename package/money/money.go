@@ -0,0 +1,45 @@
+package money
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// Scale - число знаков после запятой, совпадает с NUMERIC(20, 2) в схеме
+const Scale int32 = 2
+
+// maxAmount - граница суммы: у NUMERIC(20, 2) на целую часть остается 18 знаков
+var maxAmount = decimal.New(1, 18)
+
+var (
+	ErrNotPositive   = errors.New("amount must be positive")
+	ErrScaleExceeded = errors.New("amount has too many decimal places")
+	ErrTooLarge      = errors.New("amount must be less than 10^18")
+)
+
+// Validate проверяет, что сумма положительна, меньше 10^18 и укладывается в Scale знаков
+func Validate(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return ErrNotPositive
+	}
+	if amount.GreaterThanOrEqual(maxAmount) {
+		return ErrTooLarge
+	}
+	if !amount.Equal(amount.Truncate(Scale)) {
+		return ErrScaleExceeded
+	}
+	return nil
+}
+
+// Parse разбирает сумму из строки и проверяет ее через Validate
+func Parse(s string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if err = Validate(amount); err != nil {
+		return decimal.Decimal{}, err
+	}
+	return amount, nil
+}
@@ -0,0 +1,67 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tryingMicro/OrderAccepter/package/money"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		amount string
+		err    error
+	}{
+		{"10", nil},
+		{"0.01", nil},
+		{"12.50", nil},
+		{"0", money.ErrNotPositive},
+		{"-5", money.ErrNotPositive},
+		{"0.001", money.ErrScaleExceeded},
+		{"1.234", money.ErrScaleExceeded},
+		{"999999999999999999.99", nil},
+		{"1000000000000000000", money.ErrTooLarge},
+		{"1e30", money.ErrTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.amount, func(t *testing.T) {
+			err := money.Validate(decimal.RequireFromString(tc.amount))
+			if tc.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	amount, err := money.Parse("0.10")
+	require.NoError(t, err)
+	assert.Equal(t, "0.1", amount.String())
+
+	_, err = money.Parse("abc")
+	assert.Error(t, err)
+
+	_, err = money.Parse("0.105")
+	assert.ErrorIs(t, err, money.ErrScaleExceeded)
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	code, err := money.NormalizeCurrency(" eur ")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", code)
+
+	_, err = money.NormalizeCurrency("XXX")
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
+
+func TestValidateForCurrency(t *testing.T) {
+	assert.NoError(t, money.ValidateForCurrency(decimal.RequireFromString("10.25"), "USD"))
+	assert.NoError(t, money.ValidateForCurrency(decimal.RequireFromString("1000"), "JPY"))
+	assert.ErrorIs(t, money.ValidateForCurrency(decimal.RequireFromString("10.5"), "JPY"), money.ErrScaleExceeded)
+	assert.ErrorIs(t, money.ValidateForCurrency(decimal.RequireFromString("10"), "XXX"), money.ErrUnsupportedCurrency)
+	assert.ErrorIs(t, money.ValidateForCurrency(decimal.RequireFromString("-1"), "USD"), money.ErrNotPositive)
+}
@@ -0,0 +1,32 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (id, name, prefix, secret_hash, scopes, rotated_from, owner_id, staff_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id;
+
+-- name: GetApiKey :one
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+WHERE id = $1;
+
+-- name: GetApiKeyByPrefix :one
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+WHERE prefix = $1;
+
+-- name: ListApiKeys :many
+SELECT id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id
+FROM api_keys
+ORDER BY created_at, id;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, NOW())
+WHERE id = $1
+RETURNING id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at, rotated_from, owner_id, staff_id;
+
+-- Отметка использования пишется не чаще раза в минуту, чтобы не нагружать каждый запрос
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
@@ -0,0 +1,22 @@
+-- Записи добавляются по одной под транзакционной блокировкой: иначе две записи
+-- сослались бы на одну и ту же голову цепочки
+-- name: LockAuditLog :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_log'));
+
+-- name: GetAuditLogHead :one
+SELECT hash
+FROM audit_log
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditEntry :one
+INSERT INTO audit_log (occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash;
+
+-- name: ListAuditLog :many
+SELECT id, occurred_at, actor, action, route, wallet_id, payload_digest, outcome, status_code, client_ip, prev_hash, hash
+FROM audit_log
+WHERE id > @after_id
+ORDER BY id
+LIMIT @row_limit;
@@ -0,0 +1,15 @@
+-- name: CreateFxRate :one
+-- Опубликованный курс не меняется: конфликт возвращает пустой результат (pgx.ErrNoRows)
+INSERT INTO fx_rates (id, base_currency, quote_currency, rate, effective_from)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (base_currency, quote_currency, effective_from) DO NOTHING
+RETURNING id, base_currency, quote_currency, rate, effective_from, created_at;
+
+-- name: GetFxRateAt :one
+SELECT id, base_currency, quote_currency, rate, effective_from, created_at
+FROM fx_rates
+WHERE base_currency = $1
+  AND quote_currency = $2
+  AND effective_from <= sqlc.arg(at)
+ORDER BY effective_from DESC
+LIMIT 1;
@@ -0,0 +1,23 @@
+-- name: GetIdempotencyKey :one
+SELECT key, request_hash, response, created_at, expires_at, caller
+FROM idempotency_keys
+WHERE caller = $1
+  AND key = $2
+  AND expires_at > NOW();
+
+-- Просроченный ключ перезаписывается, живой - нет (запрос вернет pgx.ErrNoRows)
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (caller, key, request_hash, response, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (caller, key) DO UPDATE
+    SET request_hash = EXCLUDED.request_hash,
+        response     = EXCLUDED.response,
+        created_at   = NOW(),
+        expires_at   = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING key, request_hash, response, created_at, expires_at, caller;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE
+FROM idempotency_keys
+WHERE expires_at <= NOW();
@@ -0,0 +1,34 @@
+-- name: CreateManualAdjustment :one
+INSERT INTO manual_adjustments (id, wallet_id, direction, amount, reason, status, requested_by, transaction_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at;
+
+-- name: GetManualAdjustment :one
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE id = $1;
+
+-- name: GetManualAdjustmentForUpdate :one
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: DecideManualAdjustment :one
+UPDATE manual_adjustments
+SET status          = sqlc.arg(status),
+    decided_by      = sqlc.arg(decided_by),
+    decision_reason = sqlc.narg(decision_reason),
+    transaction_id  = sqlc.narg(transaction_id),
+    decided_at      = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'PENDING'
+RETURNING id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at;
+
+-- name: ListManualAdjustments :many
+SELECT id, wallet_id, direction, amount, reason, status, requested_by, decided_by, decision_reason, transaction_id, created_at, decided_at
+FROM manual_adjustments
+WHERE (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(wallet_id)::uuid IS NULL OR wallet_id = sqlc.narg(wallet_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,9 @@
+-- Последняя версия, до которой применены все миграции подряд. Пропуск в середине
+-- означает, что схема отстает, даже если более поздние версии записаны.
+-- name: GetSchemaVersion :one
+SELECT COALESCE((
+    SELECT MIN(s.version)
+    FROM schema_migrations s
+    WHERE NOT EXISTS (SELECT 1 FROM schema_migrations n WHERE n.version = s.version + 1)
+      AND EXISTS (SELECT 1 FROM schema_migrations f WHERE f.version = 1)
+), 0)::INT AS version;
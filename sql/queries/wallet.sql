@@ -1,22 +1,44 @@
 -- name: GetWallet :one
-SELECT id, balance, created_at, updated_at
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 FROM wallets
 WHERE id = $1;
 
 -- name: GetWalletForUpdate :one
-SELECT id, balance, created_at, updated_at
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
 FROM wallets
 WHERE id = $1
     FOR UPDATE;
 
 -- name: CreateWallet :one
-INSERT INTO wallets (id, balance)
-VALUES ($1, 0)
-RETURNING id, balance, created_at, updated_at;
+INSERT INTO wallets (id, balance, currency, owner_id, external_ref)
+VALUES ($1, 0, $2, $3, $4)
+ON CONFLICT (owner_id, external_ref) WHERE external_ref IS NOT NULL DO NOTHING
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref;
 
 -- name: UpdateWalletBalance :one
 UPDATE wallets
-SET balance   = $1,
+SET balance      = sqlc.arg(balance),
+    held_balance = held_balance + sqlc.arg(held_delta),
+    updated_at   = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref;
+
+-- name: UpdateWalletStatus :one
+UPDATE wallets
+SET status     = $1,
     updated_at = NOW()
 WHERE id = $2
-RETURNING id, balance, created_at, updated_at;
+RETURNING id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref;
+
+-- name: ListWalletsByOwner :many
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
+FROM wallets
+WHERE owner_id = $1
+ORDER BY created_at, id
+LIMIT $2;
+
+-- name: GetWalletByExternalRef :one
+SELECT id, balance, created_at, updated_at, currency, held_balance, status, owner_id, external_ref
+FROM wallets
+WHERE owner_id = $1
+  AND external_ref = $2;
@@ -0,0 +1,33 @@
+-- name: CreateWalletEvent :one
+INSERT INTO wallet_events (id, wallet_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING id, wallet_id, event_type, payload, created_at, published_at, attempts, next_attempt_at, last_error, locked_until;
+
+-- name: ClaimPendingWalletEvents :many
+UPDATE wallet_events
+SET locked_until = sqlc.arg(locked_until)::timestamptz
+WHERE id IN (SELECT e.id
+             FROM wallet_events e
+             WHERE e.published_at IS NULL
+               AND e.next_attempt_at <= NOW()
+               AND (e.locked_until IS NULL OR e.locked_until <= NOW())
+             ORDER BY e.created_at, e.id
+             LIMIT sqlc.arg(batch_size)
+                 FOR UPDATE SKIP LOCKED)
+RETURNING id, wallet_id, event_type, payload, created_at, published_at, attempts, next_attempt_at, last_error, locked_until;
+
+-- name: MarkWalletEventPublished :exec
+UPDATE wallet_events
+SET published_at = NOW(),
+    attempts     = attempts + 1,
+    last_error   = NULL,
+    locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkWalletEventFailed :exec
+UPDATE wallet_events
+SET attempts        = attempts + 1,
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_error      = sqlc.arg(last_error),
+    locked_until    = NULL
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,26 @@
+-- name: CreateWalletHold :one
+INSERT INTO wallet_holds (id, wallet_id, amount, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at;
+
+-- name: GetWalletHoldForUpdate :one
+SELECT id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+FROM wallet_holds
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: UpdateWalletHoldStatus :one
+UPDATE wallet_holds
+SET status          = sqlc.arg(status),
+    captured_amount = sqlc.narg(captured_amount),
+    updated_at      = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at;
+
+-- name: ListExpiredWalletHolds :many
+SELECT id, wallet_id, amount, captured_amount, status, created_at, updated_at, expires_at
+FROM wallet_holds
+WHERE status = 'ACTIVE'
+  AND expires_at <= NOW()
+ORDER BY expires_at
+LIMIT $1;
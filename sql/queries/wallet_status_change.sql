@@ -0,0 +1,4 @@
+-- name: CreateWalletStatusChange :one
+INSERT INTO wallet_status_changes (id, wallet_id, from_status, to_status, reason, actor)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, wallet_id, from_status, to_status, reason, actor, created_at;
@@ -0,0 +1,49 @@
+-- name: CreateWalletTransaction :one
+INSERT INTO wallet_transactions (id, wallet_id, type, amount, balance_before, balance_after, counterparty_wallet_id,
+                                 fx_rate, fx_remainder, hold_id, reversal_of)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of;
+
+-- name: GetWalletTransaction :one
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE id = $1;
+
+-- name: GetWalletTransactionForUpdate :one
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS reversed_amount
+FROM wallet_transactions
+WHERE reversal_of = $1;
+
+-- name: ListReversedAmounts :many
+SELECT reversal_of::uuid AS transaction_id, SUM(amount)::numeric AS reversed_amount
+FROM wallet_transactions
+WHERE reversal_of = ANY (sqlc.arg(transaction_ids)::uuid[])
+GROUP BY reversal_of;
+
+-- name: ListWalletTransactions :many
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE wallet_id = sqlc.arg(wallet_id)
+  AND (sqlc.narg(type)::varchar IS NULL OR type = sqlc.narg(type))
+  AND (sqlc.narg(min_amount)::numeric IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::numeric IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListWalletTransactionsAfter :many
+SELECT id, wallet_id, type, amount, balance_before, balance_after, created_at, counterparty_wallet_id, fx_rate, fx_remainder, hold_id, reversal_of
+FROM wallet_transactions
+WHERE wallet_id = sqlc.arg(wallet_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_limit);
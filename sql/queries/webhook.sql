@@ -0,0 +1,91 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (id, url, secret, wallet_id, event_types, owner_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, url, secret, wallet_id, event_types, created_at, owner_id;
+
+-- owner_id = NULL в фильтре - подписки всех владельцев
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, secret, wallet_id, event_types, created_at, owner_id
+FROM webhook_subscriptions
+WHERE (sqlc.narg(owner_id)::varchar IS NULL OR owner_id = sqlc.narg(owner_id))
+ORDER BY created_at, id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(owner_id)::varchar IS NULL OR owner_id = sqlc.narg(owner_id));
+
+-- Подписка владельца получает события только его кошельков, подписка без владельца - всех
+-- name: ListMatchingWebhookSubscriptions :many
+SELECT id, url, secret, wallet_id, event_types, created_at, owner_id
+FROM webhook_subscriptions
+WHERE (wallet_id IS NULL OR wallet_id = sqlc.arg(wallet_id))
+  AND sqlc.arg(event_type)::text = ANY (event_types)
+  AND (owner_id IS NULL OR owner_id = (SELECT w.owner_id FROM wallets w WHERE w.id = sqlc.arg(wallet_id)));
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET locked_until = sqlc.arg(locked_until)::timestamptz
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (SELECT w.id
+               FROM webhook_deliveries w
+               WHERE w.status = 'PENDING'
+                 AND w.next_attempt_at <= NOW()
+                 AND (w.locked_until IS NULL OR w.locked_until <= NOW())
+               ORDER BY w.next_attempt_at, w.id
+               LIMIT sqlc.arg(batch_size)
+                   FOR UPDATE SKIP LOCKED)
+RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status           = 'DELIVERED',
+    attempts         = attempts + 1,
+    last_status_code = sqlc.arg(status_code),
+    last_error       = NULL,
+    delivered_at     = NOW(),
+    locked_until     = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status           = sqlc.arg(status),
+    attempts         = attempts + 1,
+    next_attempt_at  = sqlc.arg(next_attempt_at),
+    last_error       = sqlc.arg(last_error),
+    last_status_code = sqlc.narg(status_code),
+    locked_until     = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, last_status_code,
+       created_at, delivered_at, locked_until
+FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+  AND subscription_id IN (SELECT s.id
+                          FROM webhook_subscriptions s
+                          WHERE sqlc.narg(owner_id)::varchar IS NULL
+                             OR s.owner_id = sqlc.narg(owner_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status          = 'PENDING',
+    attempts        = 0,
+    next_attempt_at = NOW(),
+    last_error      = NULL
+WHERE webhook_deliveries.id = sqlc.arg(id)
+  AND subscription_id IN (SELECT s.id
+                          FROM webhook_subscriptions s
+                          WHERE sqlc.narg(owner_id)::varchar IS NULL
+                             OR s.owner_id = sqlc.narg(owner_id))
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, last_status_code,
+          created_at, delivered_at, locked_until;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+                                                   id              UUID           PRIMARY KEY,
+                                                   wallet_id       UUID           NOT NULL REFERENCES wallets (id),
+                                                   type            VARCHAR(32)    NOT NULL,
+                                                   amount          NUMERIC(20, 2) NOT NULL,
+                                                   balance_before  NUMERIC(20, 2) NOT NULL,
+                                                   balance_after   NUMERIC(20, 2) NOT NULL,
+                                                   created_at      TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+                                                   CONSTRAINT amount_positive CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS wallet_transactions_wallet_id_created_at_idx
+    ON wallet_transactions (wallet_id, created_at, id);
@@ -0,0 +1,2 @@
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS counterparty_wallet_id UUID REFERENCES wallets (id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                key           VARCHAR(255) PRIMARY KEY,
+                                                request_hash  CHAR(64)     NOT NULL,
+                                                response      JSONB        NOT NULL,
+                                                created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+                                                expires_at    TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx
+    ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
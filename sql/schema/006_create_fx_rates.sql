@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+                                        id              UUID           PRIMARY KEY,
+                                        base_currency   CHAR(3)        NOT NULL,
+                                        quote_currency  CHAR(3)        NOT NULL,
+                                        rate            NUMERIC(20, 10) NOT NULL,
+                                        effective_from  TIMESTAMPTZ    NOT NULL,
+                                        created_at      TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+                                        CONSTRAINT rate_positive CHECK (rate > 0),
+                                        CONSTRAINT fx_rates_pair_effective_from_key UNIQUE (base_currency, quote_currency, effective_from)
+);
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS fx_rate      NUMERIC,
+    ADD COLUMN IF NOT EXISTS fx_remainder NUMERIC;
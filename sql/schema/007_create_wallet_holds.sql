@@ -0,0 +1,22 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS held_balance NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT held_balance_within_balance CHECK (held_balance >= 0 AND held_balance <= balance);
+
+CREATE TABLE IF NOT EXISTS wallet_holds (
+                                            id               UUID           PRIMARY KEY,
+                                            wallet_id        UUID           NOT NULL REFERENCES wallets(id),
+                                            amount           NUMERIC(20, 2) NOT NULL,
+                                            captured_amount  NUMERIC(20, 2),
+                                            status           VARCHAR(16)    NOT NULL DEFAULT 'ACTIVE',
+                                            created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+                                            updated_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+                                            expires_at       TIMESTAMPTZ    NOT NULL,
+                                            CONSTRAINT hold_amount_positive CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_holds_active_expires_at
+    ON wallet_holds (expires_at)
+    WHERE status = 'ACTIVE';
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS hold_id UUID REFERENCES wallet_holds(id);
@@ -0,0 +1,8 @@
+ALTER TABLE wallet_transactions
+    ADD COLUMN IF NOT EXISTS reversal_of     UUID REFERENCES wallet_transactions(id),
+    ADD COLUMN IF NOT EXISTS reversed_amount NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT reversed_amount_within_amount CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_reversal_of
+    ON wallet_transactions (reversal_of)
+    WHERE reversal_of IS NOT NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS wallet_events (
+                                             id               UUID         PRIMARY KEY,
+                                             wallet_id        UUID         NOT NULL REFERENCES wallets(id),
+                                             event_type       VARCHAR(64)  NOT NULL,
+                                             payload          JSONB        NOT NULL,
+                                             created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+                                             published_at     TIMESTAMPTZ,
+                                             attempts         INTEGER      NOT NULL DEFAULT 0,
+                                             next_attempt_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+                                             last_error       TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_events_pending
+    ON wallet_events (next_attempt_at)
+    WHERE published_at IS NULL;
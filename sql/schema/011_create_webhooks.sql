@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+                                                     id           UUID          PRIMARY KEY,
+                                                     url          TEXT          NOT NULL,
+                                                     secret       VARCHAR(128)  NOT NULL,
+                                                     -- NULL - подписка на события всех кошельков
+                                                     wallet_id    UUID          REFERENCES wallets(id),
+                                                     event_types  TEXT[]        NOT NULL,
+                                                     created_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_wallet_id
+    ON webhook_subscriptions (wallet_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  id                UUID          PRIMARY KEY,
+                                                  subscription_id   UUID          NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+                                                  event_id          UUID          NOT NULL,
+                                                  event_type        VARCHAR(64)   NOT NULL,
+                                                  payload           JSONB         NOT NULL,
+                                                  status            VARCHAR(16)   NOT NULL DEFAULT 'PENDING',
+                                                  attempts          INTEGER       NOT NULL DEFAULT 0,
+                                                  next_attempt_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+                                                  last_error        TEXT,
+                                                  last_status_code  INTEGER,
+                                                  created_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+                                                  delivered_at      TIMESTAMPTZ,
+                                                  CONSTRAINT webhook_deliveries_subscription_event_key UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'PENDING';
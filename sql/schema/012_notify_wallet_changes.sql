@@ -0,0 +1,15 @@
+-- Каждая запись журнала будит подписчиков потока кошелька. pg_notify внутри транзакции
+-- доставляется только после COMMIT, а одинаковые уведомления одной транзакции схлопываются.
+CREATE OR REPLACE FUNCTION notify_wallet_change() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('wallet_changes', NEW.wallet_id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS wallet_transactions_notify ON wallet_transactions;
+CREATE TRIGGER wallet_transactions_notify
+    AFTER INSERT ON wallet_transactions
+    FOR EACH ROW
+EXECUTE FUNCTION notify_wallet_change();
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+                                        id            UUID          PRIMARY KEY,
+                                        name          VARCHAR(128)  NOT NULL,
+                                        -- prefix - открытая часть ключа для поиска; сам секрет хранится только в виде SHA-256
+                                        prefix        VARCHAR(32)   NOT NULL UNIQUE,
+                                        secret_hash   BYTEA         NOT NULL,
+                                        scopes        TEXT[]        NOT NULL,
+                                        created_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+                                        last_used_at  TIMESTAMPTZ,
+                                        revoked_at    TIMESTAMPTZ,
+                                        -- ключ, на смену которому выпущен этот
+                                        rotated_from  UUID          REFERENCES api_keys(id)
+);
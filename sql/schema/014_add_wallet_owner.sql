@@ -0,0 +1,16 @@
+-- NULL - кошелек без владельца: создан до появления владельцев, доступен только admin
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS owner_id     VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS external_ref VARCHAR(128);
+
+CREATE INDEX IF NOT EXISTS idx_wallets_owner_id
+    ON wallets (owner_id);
+
+-- Внешняя ссылка уникальна в пределах владельца
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_owner_external_ref
+    ON wallets (owner_id, external_ref)
+    WHERE external_ref IS NOT NULL;
+
+-- Владелец, от имени которого действует ключ; у ключей admin может отсутствовать
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS owner_id VARCHAR(128);
@@ -0,0 +1,26 @@
+-- Ручные корректировки баланса поддержкой. Крупные ждут подтверждения вторым сотрудником
+CREATE TABLE IF NOT EXISTS manual_adjustments (
+                                                  id               UUID           PRIMARY KEY,
+                                                  wallet_id        UUID           NOT NULL REFERENCES wallets(id),
+                                                  direction        VARCHAR(8)     NOT NULL,
+                                                  amount           NUMERIC(20, 2) NOT NULL,
+                                                  reason           TEXT           NOT NULL,
+                                                  status           VARCHAR(16)    NOT NULL DEFAULT 'PENDING',
+                                                  requested_by     VARCHAR(255)   NOT NULL,
+                                                  decided_by       VARCHAR(255),
+                                                  decision_reason  TEXT,
+                                                  transaction_id   UUID           REFERENCES wallet_transactions(id),
+                                                  created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+                                                  decided_at       TIMESTAMPTZ,
+                                                  CONSTRAINT adjustment_amount_positive CHECK (amount > 0),
+                                                  CONSTRAINT adjustment_direction_valid CHECK (direction IN ('CREDIT', 'DEBIT')),
+                                                  CONSTRAINT adjustment_status_valid CHECK (status IN ('PENDING', 'APPLIED', 'REJECTED')),
+                                                  CONSTRAINT adjustment_four_eyes CHECK (decided_by IS NULL OR decided_by <> requested_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_manual_adjustments_pending
+    ON manual_adjustments (created_at)
+    WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_manual_adjustments_wallet_id
+    ON manual_adjustments (wallet_id, created_at);
@@ -0,0 +1,40 @@
+-- Журнал аудита изменяющих вызовов API. Каждая запись хранит хеш предыдущей,
+-- поэтому правка или удаление записи в середине ломает цепочку при проверке.
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id              BIGSERIAL    PRIMARY KEY,
+                                         occurred_at     TIMESTAMPTZ  NOT NULL,
+                                         actor           VARCHAR(255) NOT NULL,
+                                         action          VARCHAR(128) NOT NULL,
+                                         route           TEXT         NOT NULL DEFAULT '',
+                                         wallet_id       UUID,
+                                         payload_digest  VARCHAR(64)  NOT NULL DEFAULT '',
+                                         outcome         TEXT         NOT NULL,
+                                         status_code     INTEGER,
+                                         client_ip       VARCHAR(64)  NOT NULL DEFAULT '',
+                                         prev_hash       VARCHAR(64)  NOT NULL,
+                                         hash            VARCHAR(64)  NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_wallet_id
+    ON audit_log (wallet_id, id)
+    WHERE wallet_id IS NOT NULL;
+
+-- Журнал только дополняется: изменение и удаление запрещены и для владельца таблицы
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only: % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_no_update ON audit_log;
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
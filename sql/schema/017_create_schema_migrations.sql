@@ -0,0 +1,10 @@
+-- Версия схемы для проверки готовности. Каждая следующая миграция добавляет сюда
+-- свой номер, а сервис сверяет максимум с ожидаемым health.ExpectedSchemaVersion
+CREATE TABLE IF NOT EXISTS schema_migrations (
+                                                 version     INT          PRIMARY KEY,
+                                                 applied_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO schema_migrations (version)
+SELECT generate_series(1, 17)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,26 @@
+-- Журнал операций только дополняется. Сторнированная сумма больше не хранится в исходной
+-- записи, а считается по компенсирующим записям (reversal_of), поэтому правка строк не нужна.
+ALTER TABLE wallet_transactions
+    DROP CONSTRAINT IF EXISTS reversed_amount_within_amount,
+    DROP COLUMN IF EXISTS reversed_amount;
+
+CREATE OR REPLACE FUNCTION wallet_transactions_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'wallet_transactions is append-only: % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS wallet_transactions_no_update ON wallet_transactions;
+CREATE TRIGGER wallet_transactions_no_update
+    BEFORE UPDATE OR DELETE ON wallet_transactions
+    FOR EACH ROW
+EXECUTE FUNCTION wallet_transactions_append_only();
+
+DROP TRIGGER IF EXISTS wallet_transactions_no_truncate ON wallet_transactions;
+CREATE TRIGGER wallet_transactions_no_truncate
+    BEFORE TRUNCATE ON wallet_transactions
+    FOR EACH STATEMENT
+EXECUTE FUNCTION wallet_transactions_append_only();
+
+INSERT INTO schema_migrations (version) VALUES (18) ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Аренда пачки событий релеем: событие с действующим locked_until не выбирается
+-- другими экземплярами, а отправка идет вне транзакции БД.
+ALTER TABLE wallet_events
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+INSERT INTO schema_migrations (version) VALUES (19) ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+-- Ключ идемпотентности уникален в пределах вызывающего: одинаковые ключи разных
+-- клиентов не пересекаются и не отдают чужой сохраненный ответ.
+-- Существующие ключи остаются с пустым caller и доживают свой TTL.
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS caller TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT IF EXISTS idempotency_keys_pkey,
+    ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (caller, key);
+
+INSERT INTO schema_migrations (version) VALUES (20) ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Владелец подписки: клиент получает события только своих кошельков.
+-- NULL - подписка администратора на события всех кошельков.
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN IF NOT EXISTS owner_id VARCHAR(128);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_owner_id
+    ON webhook_subscriptions (owner_id);
+
+INSERT INTO schema_migrations (version) VALUES (21) ON CONFLICT DO NOTHING;
@@ -0,0 +1,50 @@
+-- 017 записала версии 1-17, не глядя на схему. Сверяем каждую версию с объектом,
+-- который создает ее миграция: версии без объекта удаляются, недостающие добавляются.
+-- Готовность берет последнюю версию без пропусков, поэтому отсутствующая миграция
+-- держит /readyz в down, пока ее не применят.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+                                                 version     INT          PRIMARY KEY,
+                                                 applied_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+WITH applied (version, present) AS (
+    VALUES
+        (1, to_regclass('wallets') IS NOT NULL),
+        (2, to_regclass('wallet_transactions') IS NOT NULL),
+        (3, EXISTS (SELECT 1 FROM information_schema.columns
+                    WHERE table_schema = current_schema() AND table_name = 'wallet_transactions' AND column_name = 'counterparty_wallet_id')),
+        (4, to_regclass('idempotency_keys') IS NOT NULL),
+        (5, EXISTS (SELECT 1 FROM information_schema.columns
+                    WHERE table_schema = current_schema() AND table_name = 'wallets' AND column_name = 'currency')),
+        (6, to_regclass('fx_rates') IS NOT NULL),
+        (7, to_regclass('wallet_holds') IS NOT NULL),
+        (8, EXISTS (SELECT 1 FROM information_schema.columns
+                    WHERE table_schema = current_schema() AND table_name = 'wallet_transactions' AND column_name = 'reversal_of')),
+        (9, to_regclass('wallet_status_changes') IS NOT NULL),
+        (10, to_regclass('wallet_events') IS NOT NULL),
+        (11, to_regclass('webhook_deliveries') IS NOT NULL),
+        (12, EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'wallet_transactions_notify')),
+        (13, to_regclass('api_keys') IS NOT NULL),
+        (14, EXISTS (SELECT 1 FROM information_schema.columns
+                     WHERE table_schema = current_schema() AND table_name = 'api_keys' AND column_name = 'owner_id')),
+        (15, to_regclass('manual_adjustments') IS NOT NULL),
+        (16, to_regclass('audit_log') IS NOT NULL),
+        (17, TRUE),
+        (18, EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'wallet_transactions_no_update')),
+        (19, EXISTS (SELECT 1 FROM information_schema.columns
+                     WHERE table_schema = current_schema() AND table_name = 'wallet_events' AND column_name = 'locked_until')),
+        (20, EXISTS (SELECT 1 FROM information_schema.columns
+                     WHERE table_schema = current_schema() AND table_name = 'idempotency_keys' AND column_name = 'caller')),
+        (21, EXISTS (SELECT 1 FROM information_schema.columns
+                     WHERE table_schema = current_schema() AND table_name = 'webhook_subscriptions' AND column_name = 'owner_id'))
+),
+     missing AS (
+         DELETE FROM schema_migrations s
+             USING applied a
+             WHERE s.version = a.version AND NOT a.present
+     )
+INSERT INTO schema_migrations (version)
+SELECT version FROM applied WHERE present
+ON CONFLICT DO NOTHING;
+
+INSERT INTO schema_migrations (version) VALUES (22) ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Сотрудник, которому выдан ключ поддержки или admin. По нему аудит называет
+-- исполнителя: имя ключа - свободный текст и личностью не считается
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS staff_id VARCHAR(128);
+
+INSERT INTO schema_migrations (version) VALUES (23) ON CONFLICT DO NOTHING;
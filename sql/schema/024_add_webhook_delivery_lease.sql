@@ -0,0 +1,6 @@
+-- Аренда пачки доставок воркером, как у wallet_events: доставка с действующим
+-- locked_until не выбирается другими экземплярами, а HTTP-запросы идут вне транзакции БД.
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+INSERT INTO schema_migrations (version) VALUES (24) ON CONFLICT DO NOTHING;
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 type Config struct {
@@ -15,6 +16,58 @@ type Config struct {
 	DBName      string `mapstructure:"DB_NAME"`
 	DBSSLMode   string `mapstructure:"DB_SSL_MODE"`
 	DBMaxConns  int32  `mapstructure:"DB_MAX_CONNS"`
+
+	// Пустой GRPC_ADDR отключает gRPC API
+	GRPCAddr string `mapstructure:"GRPC_ADDR"`
+
+	// Проверка запросов и ответов по вшитой спецификации OpenAPI
+	OpenAPIValidateRequests  bool `mapstructure:"OPENAPI_VALIDATE_REQUESTS"`
+	OpenAPIValidateResponses bool `mapstructure:"OPENAPI_VALIDATE_RESPONSES"`
+
+	// Статический ключ с правами admin для выпуска первых ключей; пустой - отключен
+	AuthBootstrapKey string `mapstructure:"AUTH_BOOTSTRAP_KEY"`
+
+	// JWT шлюза проверяются по локальному JWKS; пустой AUTH_JWKS_PATH отключает JWT
+	AuthJWKSPath      string        `mapstructure:"AUTH_JWKS_PATH"`
+	AuthJWTIssuer     string        `mapstructure:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience   string        `mapstructure:"AUTH_JWT_AUDIENCE"`
+	AuthJWTOwnerClaim string        `mapstructure:"AUTH_JWT_OWNER_CLAIM"`
+	AuthJWTLeeway     time.Duration `mapstructure:"AUTH_JWT_LEEWAY"`
+
+	// TracingExporter - куда отправлять спаны: none, stdout или otlp (gRPC на TRACING_OTLP_ENDPOINT)
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+
+	// Таймаут каждой проверки /readyz и пауза между отказом готовности и остановкой сервера
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	HoldTTL           time.Duration `mapstructure:"HOLD_TTL"`
+
+	FrozenWalletAllowCredits bool `mapstructure:"FROZEN_WALLET_ALLOW_CREDITS"`
+
+	// Ручные корректировки больше порога ждут подтверждения вторым сотрудником
+	AdminAdjustmentApprovalThreshold string `mapstructure:"ADMIN_ADJUSTMENT_APPROVAL_THRESHOLD"`
+
+	// OutboxPublisher - куда релей отправляет события: stdout, file или http
+	OutboxPublisher      string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxFilePath       string        `mapstructure:"OUTBOX_FILE_PATH"`
+	OutboxWebhookURL     string        `mapstructure:"OUTBOX_WEBHOOK_URL"`
+	OutboxWebhookTimeout time.Duration `mapstructure:"OUTBOX_WEBHOOK_TIMEOUT"`
+	OutboxRelayInterval  time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+
+	// После WebhookMaxAttempts неудачных попыток доставка переходит в DEAD
+	WebhookMaxAttempts      int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetryBaseDelay   time.Duration `mapstructure:"WEBHOOK_RETRY_BASE_DELAY"`
+	WebhookTimeout          time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookDeliveryInterval time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+
+	// Разрешает получателей во внутренних сетях; только для локальной разработки
+	WebhookAllowPrivateNetworks bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
 }
 
 func (c Config) DBURL() string {